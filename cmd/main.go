@@ -42,6 +42,10 @@ func init() {
 	pflag.IntVar(&opts.MetricPort, "metric-port", 9090, "Metric port")
 	pflag.StringVar(&opts.ConfigFilePath, "config-file", "/etc/stargate/config/stargate.yaml", "Path to the file containing the config")
 	pflag.StringVar(&opts.PersistenceFilePath, "persistence-file", "/data/alerts.dump", "Path to the file used to persist the alert store")
+	pflag.StringVar(&opts.PreferencesFilePath, "preferences-file", "/data/preferences.yaml", "Path to the file used to persist the preferences of Slack users")
+	pflag.StringVar(&opts.MaintenanceFilePath, "maintenance-file", "/data/maintenance.yaml", "Path to the file used to persist the maintenance windows added via the API")
+	pflag.StringVar(&opts.StaleSilencesFilePath, "stale-silences-file", "/data/stale-silences.yaml", "Path to the file used to persist when silences last matched an alert")
+	pflag.StringVar(&opts.RemindersFilePath, "reminders-file", "/data/reminders.yaml", "Path to the file used to persist the reminders of expiring silences")
 	pflag.DurationVar(&opts.RecheckInterval, "recheck-interval", 5*time.Minute, "Garbage collections within the alert store happens that often")
 	pflag.BoolVar(&opts.IsDebug, "debug", false, "Enable debug configuration and log level")
 	pflag.BoolVar(&opts.IsDisableSlackRTM, "disable-slack-rtm", false, "Disable Slack RTM (the bot)")
@@ -70,6 +70,35 @@ func AcknowledgeAlert(alert *client.ExtendedAlert, acknowledgedBy string) *clien
 	return clone
 }
 
+// AcknowledgeAlertByUser acknowledges the alert on behalf of the Slack user.
+// Besides the name, the ID of the user is kept in the acknowledgedByID annotation as names are ambiguous.
+func AcknowledgeAlertByUser(alert *client.ExtendedAlert, userName, userID string) *client.ExtendedAlert {
+	clone := AcknowledgeAlert(alert, userName)
+	if userID == "" || IsAcknowledgedByUser(clone, userID) {
+		return clone
+	}
+
+	acknowledgedByID := userID
+	if ids, ok := clone.Annotations[alertmanager.AcknowledgedByIDLabel]; ok && ids != "" {
+		acknowledgedByID = fmt.Sprintf("%s, %s", ids, userID)
+	}
+	clone.Annotations[alertmanager.AcknowledgedByIDLabel] = client.LabelValue(acknowledgedByID)
+	return clone
+}
+
+// IsAcknowledgedByUser checks whether the Slack user acknowledged the alert.
+func IsAcknowledgedByUser(alert *client.ExtendedAlert, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	for _, id := range strings.Split(string(alert.Annotations[alertmanager.AcknowledgedByIDLabel]), ",") {
+		if strings.TrimSpace(id) == userID {
+			return true
+		}
+	}
+	return false
+}
+
 // AcknowledgeAlerts acknowledges multiple alerts
 func AcknowledgeAlerts(alertList []*client.ExtendedAlert, acknowledgedBy string) []*client.ExtendedAlert {
 	ackedAlertList := make([]*client.ExtendedAlert, len(alertList))
@@ -47,6 +47,18 @@ func TestAcknowledgeAlert(t *testing.T) {
 	}
 }
 
+func TestAcknowledgeAlertByUser(t *testing.T) {
+	a := AcknowledgeAlertByUser(newAlerts()[0], "Peter", "U0001")
+	a = AcknowledgeAlertByUser(a, "Peter", "U0002")
+	a = AcknowledgeAlertByUser(a, "Peter", "U0001")
+
+	assert.Equal(t, "Peter", string(a.Annotations[alertmanager.AcknowledgedByLabel]), "the acknowledgedBy annotation should be equal")
+	assert.Equal(t, "U0001, U0002", string(a.Annotations[alertmanager.AcknowledgedByIDLabel]), "the acknowledgedByID annotation should be equal")
+	assert.True(t, IsAcknowledgedByUser(a, "U0002"), "the alert should be acknowledged by the user")
+	assert.False(t, IsAcknowledgedByUser(a, "U0003"), "the alert should not be acknowledged by another user of the same name")
+	assert.False(t, IsAcknowledgedByUser(newAlerts()[0], "U0001"), "alerts acknowledged by name only should not be attributed to a user")
+}
+
 func newAlerts() []*client.ExtendedAlert {
 	return []*client.ExtendedAlert{
 		{
@@ -36,6 +36,9 @@ const (
 	// AcknowledgedByLabel ...
 	AcknowledgedByLabel = "acknowledgedBy"
 
+	// AcknowledgedByIDLabel lists the Slack user IDs of the users who acknowledged an alert.
+	AcknowledgedByIDLabel = "acknowledgedByID"
+
 	// AcknowledgedAtLabel ...
 	AcknowledgedAtLabel = "acknowledgedAt"
 
@@ -72,53 +75,123 @@ func New(config config.Config, logger log.Logger) *Client {
 	}
 }
 
-// CreateSilence creates a silence.
-func (a *Client) CreateSilence(alert *client.ExtendedAlert, silenceAuthor, silenceComment string, silenceDuration time.Duration) (string, error) {
-	if alert == nil {
-		return "", errors.New("alert must not be nil")
+// CreateSilenceWithMatchers creates a silence with the given matchers.
+// An active silence with the same matchers is extended instead if it ends earlier. Otherwise it is returned unchanged.
+// Active silences whose matchers are a subset or superset of the given ones are reported as overlaps.
+func (a *Client) CreateSilenceWithMatchers(silenceMatchers types.Matchers, silenceAuthor, silenceComment string, silenceDuration time.Duration) (*SilenceResult, error) {
+	if err := ValidateMatchers(silenceMatchers); err != nil {
+		return nil, err
 	}
-	if silenceDuration == 0 {
-		return "", errors.New("duration must be greater than 0")
+	if silenceDuration <= 0 {
+		return nil, errors.New("duration must be greater than 0")
 	}
 	if silenceAuthor == "" {
-		return "", errors.New("author must not be empty")
+		return nil, errors.New("author must not be empty")
 	}
 
 	a.logger.LogInfo("creating silence",
-		"alertLabels", alert.Labels,
+		"silenceMatchers", silenceMatchers,
 		"silenceDuration", silenceDuration,
 		"silenceAuthor", silenceAuthor,
 	)
 
 	now := time.Now().UTC()
-	silenceMatchers := matchersFromAlert(alert)
+	endsAt := now.Add(silenceDuration)
 
-	silenceID, isExists, err := a.isSilenceExists(silenceMatchers)
+	activeSilences, err := a.listActiveSilences(now)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if isExists {
-		a.logger.LogInfo("silence already exists", "silenceMatchers", silenceMatchers)
-		return silenceID, err
+	existing, overlaps := findSilenceOverlaps(activeSilences, silenceMatchers)
+
+	if existing != nil {
+		if !endsAt.After(existing.EndsAt) {
+			a.logger.LogInfo("silence already exists", "silenceID", existing.ID, "silenceMatchers", silenceMatchers)
+			return &SilenceResult{ID: existing.ID, Status: SilenceStatus.Unchanged, EndsAt: existing.EndsAt, Overlaps: overlaps}, nil
+		}
+
+		extended := *existing
+		extended.EndsAt = endsAt
+		extended.Comment = fmt.Sprintf("%s\nextended by %s until %s: %s", existing.Comment, silenceAuthor, endsAt.Format(time.RFC3339), silenceComment)
+
+		silenceID, err := a.silenceAPIClient.Set(context.TODO(), extended)
+		if err != nil {
+			return nil, err
+		}
+		a.logger.LogInfo("extended silence", "silenceID", silenceID, "endsAt", endsAt)
+		return &SilenceResult{ID: silenceID, Status: SilenceStatus.Extended, EndsAt: endsAt, Overlaps: overlaps}, nil
 	}
 
 	silence := types.Silence{
 		Matchers:  silenceMatchers,
 		StartsAt:  now,
-		EndsAt:    now.Add(silenceDuration),
+		EndsAt:    endsAt,
 		CreatedBy: silenceAuthor,
 		Comment:   silenceComment,
 	}
 
-	silenceID, err = a.silenceAPIClient.Set(context.TODO(), silence)
+	silenceID, err := a.silenceAPIClient.Set(context.TODO(), silence)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	a.logger.LogInfo("created silence", "silenceID", silenceID)
 
+	return &SilenceResult{ID: silenceID, Status: SilenceStatus.Created, EndsAt: endsAt, Overlaps: overlaps}, nil
+}
+
+// CreateScheduledSilence creates the given silence, which may start in the future.
+// Unlike CreateSilenceWithMatchers, existing silences are neither extended nor reused.
+func (a *Client) CreateScheduledSilence(silence types.Silence) (string, error) {
+	if err := validateSilence(silence); err != nil {
+		return "", err
+	}
+
+	a.logger.LogInfo("creating scheduled silence",
+		"silenceMatchers", silence.Matchers,
+		"startsAt", silence.StartsAt,
+		"endsAt", silence.EndsAt,
+		"silenceAuthor", silence.CreatedBy,
+	)
+
+	silenceID, err := a.silenceAPIClient.Set(context.TODO(), silence)
+	if err != nil {
+		return "", err
+	}
+	a.logger.LogInfo("created scheduled silence", "silenceID", silenceID)
+	return silenceID, nil
+}
+
+// UpdateSilence updates the silence with the ID of the given one.
+// The Alertmanager expires the silence and creates a new one if it already started and its matchers or start changed.
+func (a *Client) UpdateSilence(silence types.Silence) (string, error) {
+	if silence.ID == "" {
+		return "", errors.New("silence ID must not be empty")
+	}
+	if err := validateSilence(silence); err != nil {
+		return "", err
+	}
+
+	a.logger.LogInfo("updating silence",
+		"silenceID", silence.ID,
+		"silenceMatchers", silence.Matchers,
+		"endsAt", silence.EndsAt,
+		"silenceAuthor", silence.CreatedBy,
+	)
+
+	silenceID, err := a.silenceAPIClient.Set(context.TODO(), silence)
+	if err != nil {
+		return "", err
+	}
+	a.logger.LogInfo("updated silence", "silenceID", silenceID, "previousSilenceID", silence.ID)
 	return silenceID, nil
 }
 
+// ExpireSilence expires a silence.
+func (a *Client) ExpireSilence(silenceID string) error {
+	a.logger.LogInfo("expiring silence", "silenceID", silenceID)
+	return a.silenceAPIClient.Expire(context.TODO(), silenceID)
+}
+
 // LinkToSilence creates a link to a silence.
 func (a *Client) LinkToSilence(silenceID string) string {
 	return fmt.Sprintf("%s/#/silences/%s", a.Config.AlertManager.URL, silenceID)
@@ -150,18 +223,27 @@ func (a *Client) GetSilenceByID(silenceID string) (*types.Silence, error) {
 	return a.silenceAPIClient.Get(context.TODO(), silenceID)
 }
 
-func (a *Client) isSilenceExists(matchers types.Matchers) (string, bool, error) {
-	matchersNoAuthor := matchersWithoutAuthor(matchers)
-	silences, err := a.silenceAPIClient.List(context.TODO(), matchersNoAuthor.String())
+// listActiveSilences returns the silences which are active at the given time.
+func (a *Client) listActiveSilences(now time.Time) ([]*types.Silence, error) {
+	silences, err := a.silenceAPIClient.List(context.TODO(), "")
 	if err != nil {
-		return "", false, err
+		return nil, err
 	}
-	for _, s := range silences {
-		if matchersWithoutAuthor(s.Matchers).Equal(matchersNoAuthor) {
-			return s.ID, true, nil
-		}
+	return activeSilences(silences, now), nil
+}
+
+// validateSilence checks the silence before it is created or updated.
+func validateSilence(silence types.Silence) error {
+	if err := ValidateMatchers(silence.Matchers); err != nil {
+		return err
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return errors.New("silence must end after it starts")
+	}
+	if silence.CreatedBy == "" {
+		return errors.New("author must not be empty")
 	}
-	return "", false, nil
+	return nil
 }
 
 func matchersWithoutAuthor(matchers types.Matchers) types.Matchers {
@@ -173,17 +255,3 @@ func matchersWithoutAuthor(matchers types.Matchers) types.Matchers {
 	}
 	return matcherWithoutAuthor
 }
-
-func matchersFromAlert(alert *client.ExtendedAlert) types.Matchers {
-	matchers := make([]*types.Matcher, 0)
-	for labelKey, labelValue := range alert.Labels {
-		m := &types.Matcher{
-			Name:  string(labelKey),
-			Value: string(labelValue),
-		}
-		m.IsRegex = true
-		matchers = append(matchers, m)
-	}
-
-	return matchers
-}
@@ -0,0 +1,107 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package alertmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+)
+
+// BlastRadius describes the firing alerts a silence would match.
+type BlastRadius struct {
+	Alerts     []*client.ExtendedAlert
+	Severities []string
+	Regions    []string
+}
+
+// NewBlastRadius returns the alerts matched by the initialized matchers and their distinct severities and regions.
+func NewBlastRadius(matchers types.Matchers, alerts []*client.ExtendedAlert) *BlastRadius {
+	b := &BlastRadius{Alerts: make([]*client.ExtendedAlert, 0)}
+	severities := make(map[string]bool)
+	regions := make(map[string]bool)
+
+	for _, a := range alerts {
+		labels := make(model.LabelSet, len(a.Labels))
+		for k, v := range a.Labels {
+			labels[model.LabelName(k)] = model.LabelValue(v)
+		}
+		if !matchers.Match(labels) {
+			continue
+		}
+
+		b.Alerts = append(b.Alerts, a)
+		if severity := string(a.Labels[SeverityLabel]); severity != "" {
+			severities[severity] = true
+		}
+		if region := string(a.Labels[RegionLabel]); region != "" {
+			regions[region] = true
+		}
+	}
+
+	b.Severities = sortedKeys(severities)
+	b.Regions = sortedKeys(regions)
+	return b
+}
+
+// IsExceeding checks whether the silence matches more than maxAlerts alerts or alerts of several severities or regions.
+func (b *BlastRadius) IsExceeding(maxAlerts int) bool {
+	return len(b.Alerts) > maxAlerts || len(b.Severities) > 1 || len(b.Regions) > 1
+}
+
+// Summary describes the blast radius, e.g. 'this will silence 12 alerts with severity critical, warning in region eu-de-1'.
+func (b *BlastRadius) Summary() string {
+	summary := fmt.Sprintf("this will silence %d alerts", len(b.Alerts))
+	if len(b.Severities) > 0 {
+		summary += fmt.Sprintf(" with severity %s", strings.Join(b.Severities, ", "))
+	}
+	if len(b.Regions) > 0 {
+		summary += fmt.Sprintf(" in region %s", strings.Join(b.Regions, ", "))
+	}
+	return summary
+}
+
+// GetBlastRadius evaluates the matchers against the firing alerts, which are neither silenced nor inhibited.
+func (a *Client) GetBlastRadius(matchers types.Matchers) (*BlastRadius, error) {
+	if err := ValidateMatchers(matchers); err != nil {
+		return nil, err
+	}
+
+	filter := NewDefaultFilter()
+	filter.IsInhibited = false
+	alerts, err := a.ListAlerts(filter)
+	if err != nil {
+		return nil, err
+	}
+	return NewBlastRadius(matchers, alerts), nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
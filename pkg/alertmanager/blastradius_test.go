@@ -0,0 +1,119 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package alertmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/config"
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAlert(alertname, severity, region string) *client.ExtendedAlert {
+	return &client.ExtendedAlert{
+		Alert: client.Alert{
+			Labels: client.LabelSet{
+				"alertname":   client.LabelValue(alertname),
+				SeverityLabel: client.LabelValue(severity),
+				RegionLabel:   client.LabelValue(region),
+			},
+		},
+	}
+}
+
+func TestBlastRadius(t *testing.T) {
+	alerts := []*client.ExtendedAlert{
+		newTestAlert("NodeDown", "critical", "eu-de-1"),
+		newTestAlert("NodeDown", "warning", "eu-de-1"),
+		newTestAlert("NodeDown", "critical", "eu-nl-1"),
+		newTestAlert("DiskFull", "warning", "eu-de-1"),
+	}
+
+	testCases := []struct {
+		matchers    types.Matchers
+		alertCount  int
+		isExceeding bool
+		summary     string
+	}{
+		{
+			matchers:    types.Matchers{types.NewMatcher("alertname", "NodeDown"), types.NewMatcher(SeverityLabel, "critical"), types.NewMatcher(RegionLabel, "eu-de-1")},
+			alertCount:  1,
+			isExceeding: false,
+			summary:     "this will silence 1 alerts with severity critical in region eu-de-1",
+		},
+		{
+			matchers:    types.Matchers{types.NewMatcher("alertname", "NodeDown"), types.NewMatcher(RegionLabel, "eu-de-1")},
+			alertCount:  2,
+			isExceeding: true,
+			summary:     "this will silence 2 alerts with severity critical, warning in region eu-de-1",
+		},
+		{
+			matchers:    types.Matchers{types.NewMatcher("alertname", "NodeDown"), types.NewMatcher(SeverityLabel, "critical")},
+			alertCount:  2,
+			isExceeding: true,
+			summary:     "this will silence 2 alerts with severity critical in region eu-de-1, eu-nl-1",
+		},
+		{
+			matchers:    types.Matchers{types.NewMatcher("alertname", "Unknown")},
+			alertCount:  0,
+			isExceeding: false,
+			summary:     "this will silence 0 alerts",
+		},
+	}
+
+	for _, tc := range testCases {
+		require.NoError(t, ValidateMatchers(tc.matchers), "the matchers should be valid")
+		b := NewBlastRadius(tc.matchers, alerts)
+		assert.Len(t, b.Alerts, tc.alertCount, "the number of matched alerts should be equal for %s", tc.matchers.String())
+		assert.Equal(t, tc.isExceeding, b.IsExceeding(5), "the blast radius should be exceeded for %s", tc.matchers.String())
+		assert.Equal(t, tc.summary, b.Summary(), "the summary should be equal")
+	}
+
+	b := NewBlastRadius(types.Matchers{types.NewMatcher(RegionLabel, "eu-de-1"), types.NewMatcher(SeverityLabel, "warning")}, alerts)
+	assert.True(t, b.IsExceeding(1), "more alerts than allowed should exceed the blast radius")
+	assert.False(t, b.IsExceeding(2), "as many alerts as allowed should not exceed the blast radius")
+}
+
+func TestGetBlastRadiusIgnoresInhibitedAlerts(t *testing.T) {
+	firing := newTestAlert("NodeDown", "critical", "eu-de-1")
+	inhibited := newTestAlert("NodeDown", "warning", "eu-de-1")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alerts := []*client.ExtendedAlert{firing}
+		if r.URL.Query().Get("inhibited") != "false" {
+			alerts = append(alerts, inhibited)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "data": alerts})
+	}))
+	defer server.Close()
+
+	cfg := config.Config{}
+	cfg.AlertManager.URL = server.URL
+	b, err := New(cfg, log.NewLogger(false)).GetBlastRadius(types.Matchers{types.NewMatcher("alertname", "NodeDown")})
+	require.NoError(t, err, "there should be no error getting the blast radius")
+	assert.Equal(t, []*client.ExtendedAlert{firing}, b.Alerts, "inhibited alerts should not be counted")
+}
@@ -22,6 +22,8 @@ package alertmanager
 import (
 	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/prometheus/alertmanager/client"
@@ -90,12 +92,13 @@ func NewFilterFromRequest(r *http.Request) *Filter {
 func (f *Filter) WithAdditionalFilter(addFilter map[string]string) {
 	filterList := make([]string, 0)
 	for k, v := range addFilter {
-		filterList = append(filterList, fmt.Sprintf("%s=\"%s\"", k, v))
+		filterList = append(filterList, filterMatcher(k, v))
 	}
 	// add trailing "," if not already if necessary.
 	if f.AddFilter != "" && !strings.HasSuffix(f.AddFilter, ",") {
 		f.AddFilter += ","
 	}
+	sort.Strings(filterList)
 	f.AddFilter += strings.Join(filterList, ",")
 }
 
@@ -103,15 +106,28 @@ func (f *Filter) WithAdditionalFilter(addFilter map[string]string) {
 func (f *Filter) WithAlertLabelsFilter(lblset client.LabelSet) {
 	filterList := make([]string, 0)
 	for k, v := range lblset {
-		filterList = append(filterList, fmt.Sprintf("%s=\"%s\"", string(k), string(v)))
+		filterList = append(filterList, filterMatcher(string(k), string(v)))
 	}
 	// add trailing "," if not already if necessary.
 	if f.AddFilter != "" && !strings.HasSuffix(f.AddFilter, ",") {
 		f.AddFilter += ","
 	}
+	sort.Strings(filterList)
 	f.AddFilter += strings.Join(filterList, ",")
 }
 
+// filterMatcherEscaper replaces the characters the filter parser of the Alertmanager does not allow in values.
+// It does not support escaping, so these are expressed as hex escapes of a regex.
+var filterMatcherEscaper = strings.NewReplacer(`"`, `\x22`, `=`, `\x3d`, `~`, `\x7e`, `!`, `\x21`)
+
+// filterMatcher returns a matcher for exactly the value as understood by the filter parser of the Alertmanager.
+func filterMatcher(name, value string) string {
+	if !strings.ContainsAny(value, `"=~!`) {
+		return fmt.Sprintf(`%s="%s"`, name, value)
+	}
+	return fmt.Sprintf(`%s=~"%s"`, name, filterMatcherEscaper.Replace(regexp.QuoteMeta(value)))
+}
+
 func (f *Filter) toString() string {
 	return f.AddFilter
 }
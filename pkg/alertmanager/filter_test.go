@@ -3,6 +3,7 @@ package alertmanager
 import (
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"testing"
 
 	"github.com/prometheus/alertmanager/client"
@@ -51,3 +52,18 @@ func TestWithAlertLabelsFilterAppend(t *testing.T) {
 
 	assert.Equal(t, filter.toString(), "labelName=\"labelValue\",alertname=\"Quark\",region=\"eu-de-1\"", "the filter should be equal")
 }
+
+func TestWithAlertLabelsFilterSpecialCharacters(t *testing.T) {
+	labelSet := client.LabelSet{
+		client.LabelName("alertname"): client.LabelValue("Quark"),
+		client.LabelName("query"):     client.LabelValue(`up{job="node"} != 1`),
+	}
+
+	filter := NewDefaultFilter()
+	filter.WithAlertLabelsFilter(labelSet)
+
+	assert.Equal(t, `alertname="Quark",query=~"up\{job\x3d\x22node\x22\} \x21\x3d 1"`, filter.toString(), "values the alertmanager cannot parse should be expressed as regex")
+
+	re := regexp.MustCompile(`^(?:` + `up\{job\x3d\x22node\x22\} \x21\x3d 1` + `)$`)
+	assert.True(t, re.MatchString(`up{job="node"} != 1`), "the regex should match the value literally")
+}
@@ -0,0 +1,100 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package alertmanager
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/sapcc/stargate/pkg/silence"
+)
+
+// ValidateMatchers checks the matchers of a silence before it is created.
+// Regular expressions must be valid and the silence must not match every alert.
+// The checks are shared with the configured silences in silence.ValidateMatchers.
+func ValidateMatchers(matchers types.Matchers) error {
+	return silence.ValidateMatchers(matchers)
+}
+
+// MatchersFromAlert returns sorted equality matchers for all labels of the alert.
+func MatchersFromAlert(alert *client.ExtendedAlert) types.Matchers {
+	matchers := make([]*types.Matcher, 0, len(alert.Labels))
+	for labelKey, labelValue := range alert.Labels {
+		matchers = append(matchers, NewLiteralMatcher(string(labelKey), string(labelValue), false))
+	}
+	return types.NewMatchers(matchers...)
+}
+
+// NewLiteralMatcher returns a matcher for exactly the given value.
+// The value is escaped if a regex matcher is requested, so special characters like `.` or `|` match literally.
+func NewLiteralMatcher(name, value string, isRegex bool) *types.Matcher {
+	if isRegex {
+		return &types.Matcher{Name: name, Value: regexp.QuoteMeta(value), IsRegex: true}
+	}
+	return types.NewMatcher(model.LabelName(name), value)
+}
+
+// isEquivalentMatcher checks whether both matchers match the same values.
+// Regex matchers only matching a literal value, as created by previous versions of the stargate, are treated as equality matchers.
+func isEquivalentMatcher(a, b *types.Matcher) bool {
+	if a.Name != b.Name {
+		return false
+	}
+
+	aValue, aIsLiteral := literalValue(a)
+	bValue, bIsLiteral := literalValue(b)
+	if aIsLiteral && bIsLiteral {
+		return aValue == bValue
+	}
+	return aIsLiteral == bIsLiteral && a.Value == b.Value
+}
+
+// literalValue returns the value matched by the matcher and whether it only matches this value.
+// Regex matchers are literal if they have no special characters other than escaped ones.
+func literalValue(m *types.Matcher) (string, bool) {
+	if !m.IsRegex {
+		return m.Value, true
+	}
+
+	var value strings.Builder
+	for i := 0; i < len(m.Value); i++ {
+		c := m.Value[i]
+		if c == '\\' {
+			if i+1 == len(m.Value) || !isRegexSpecial(m.Value[i+1]) {
+				return "", false
+			}
+			i++
+			value.WriteByte(m.Value[i])
+			continue
+		}
+		if isRegexSpecial(c) {
+			return "", false
+		}
+		value.WriteByte(c)
+	}
+	return value.String(), true
+}
+
+func isRegexSpecial(c byte) bool {
+	return strings.IndexByte(`\.+*?()|[]{}^$`, c) != -1
+}
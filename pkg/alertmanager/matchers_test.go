@@ -0,0 +1,92 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package alertmanager
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchersFromAlert(t *testing.T) {
+	alert := &client.ExtendedAlert{
+		Alert: client.Alert{
+			Labels: client.LabelSet{
+				"alertname": "OpenstackNeutronDown",
+				"region":    "eu-de-1",
+				"instance":  "10.0.0.1:9100",
+				"query":     "rate(errors[5m]) > 0.1 || up == 0",
+			},
+		},
+	}
+
+	matchers := MatchersFromAlert(alert)
+	assert.Equal(t, `{alertname="OpenstackNeutronDown",instance="10.0.0.1:9100",query="rate(errors[5m]) > 0.1 || up == 0",region="eu-de-1"}`, matchers.String(), "should be sorted equality matchers")
+	assert.NoError(t, ValidateMatchers(matchers), "matchers from the alert should be valid")
+}
+
+func TestValidateMatchers(t *testing.T) {
+	testCases := []struct {
+		matchers types.Matchers
+		isValid  bool
+	}{
+		{types.Matchers{{Name: "region", Value: "eu-de-1"}}, true},
+		{types.Matchers{{Name: "region", Value: "eu-de-(1|2)", IsRegex: true}}, true},
+		{types.Matchers{}, false},
+		{types.Matchers{{Name: "region", Value: "eu-de-(1", IsRegex: true}}, false},
+		{types.Matchers{{Name: "not-a-label", Value: "eu-de-1"}}, false},
+		{types.Matchers{{Name: "region", Value: ".*", IsRegex: true}}, false},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateMatchers(tc.matchers)
+		if tc.isValid {
+			assert.NoError(t, err, "the matchers %s should be valid", tc.matchers.String())
+		} else {
+			assert.Error(t, err, "the matchers %s should be invalid", tc.matchers.String())
+		}
+	}
+}
+
+func TestIsEquivalentMatcher(t *testing.T) {
+	equality := &types.Matcher{Name: "region", Value: "eu-de-1"}
+	assert.True(t, isEquivalentMatcher(equality, &types.Matcher{Name: "region", Value: "eu-de-1", IsRegex: true}), "a regex without special characters should be equivalent")
+	assert.False(t, isEquivalentMatcher(&types.Matcher{Name: "instance", Value: "10.0.0.1"}, &types.Matcher{Name: "instance", Value: "10.0.0.1", IsRegex: true}), "a regex with special characters should not be equivalent")
+	assert.False(t, isEquivalentMatcher(equality, &types.Matcher{Name: "region", Value: "eu-de-2"}), "different values should not be equivalent")
+	assert.True(t, isEquivalentMatcher(&types.Matcher{Name: "instance", Value: "10.0.0.1"}, NewLiteralMatcher("instance", "10.0.0.1", true)), "an escaped regex should be equivalent")
+	assert.True(t, isEquivalentMatcher(&types.Matcher{Name: "region", Value: "eu-de-(1|2)", IsRegex: true}, &types.Matcher{Name: "region", Value: "eu-de-(1|2)", IsRegex: true}), "equal regexes should be equivalent")
+}
+
+func TestNewLiteralMatcher(t *testing.T) {
+	value := "rate(errors[5m]) > 0.1 || up == 0"
+
+	m := NewLiteralMatcher("query", value, true)
+	assert.Equal(t, `rate\(errors\[5m\]\) > 0\.1 \|\| up == 0`, m.Value, "special characters should be escaped")
+	assert.NoError(t, ValidateMatchers(types.Matchers{m}), "the escaped regex should be valid")
+	assert.True(t, m.Match(model.LabelSet{"query": model.LabelValue(value)}), "the value should match literally")
+	assert.False(t, m.Match(model.LabelSet{"query": "rate(errors[5m]) > 0.1"}), "other values should not match")
+
+	m = NewLiteralMatcher("query", value, false)
+	assert.False(t, m.IsRegex, "should be an equality matcher")
+	assert.Equal(t, value, m.Value, "the value should not be escaped")
+}
@@ -0,0 +1,157 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package alertmanager
+
+import (
+	"time"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+)
+
+// SilenceStatus describes what happened when creating a silence.
+var SilenceStatus = struct {
+	Created,
+	Extended,
+	Unchanged string
+}{
+	"created",
+	"extended",
+	"unchanged",
+}
+
+// SilenceResult is the outcome of creating a silence.
+type SilenceResult struct {
+	// ID of the created, extended or unchanged silence.
+	ID string
+
+	// Status is one of SilenceStatus.
+	Status string
+
+	// EndsAt is the end of the silence. Might be later than requested if an existing silence was unchanged.
+	EndsAt time.Time
+
+	// Overlaps are active silences matching some of the same alerts.
+	Overlaps []SilenceOverlap
+}
+
+// SilenceOverlap is an active silence whose matchers are a subset or superset of the matchers of another silence.
+type SilenceOverlap struct {
+	Silence *types.Silence
+
+	// IsBroader is true if the silence has fewer matchers and thus matches at least the same alerts.
+	IsBroader bool
+}
+
+// activeSilences returns the silences which are active at the given time.
+// Pending silences, e.g. of scheduled maintenance windows, are neither reused nor extended as they do not silence anything yet.
+func activeSilences(silences []*types.Silence, now time.Time) []*types.Silence {
+	active := make([]*types.Silence, 0, len(silences))
+	for _, s := range silences {
+		if s.Status.State == types.SilenceStateActive && !s.StartsAt.After(now) {
+			active = append(active, s)
+		}
+	}
+	return active
+}
+
+// findSilenceOverlaps returns the silence with equal matchers and the silences whose matchers are a subset or superset.
+func findSilenceOverlaps(silences []*types.Silence, matchers types.Matchers) (*types.Silence, []SilenceOverlap) {
+	var (
+		equal    *types.Silence
+		overlaps = make([]SilenceOverlap, 0)
+		wanted   = matchersWithoutAuthor(matchers)
+	)
+
+	for _, s := range silences {
+		existing := matchersWithoutAuthor(s.Matchers)
+		isSubset := isMatchersSubset(existing, wanted)
+		isSuperset := isMatchersSubset(wanted, existing)
+
+		switch {
+		case isSubset && isSuperset:
+			// Extend the silence ending last if there are several.
+			if equal == nil || s.EndsAt.After(equal.EndsAt) {
+				equal = s
+			}
+		case isSubset:
+			overlaps = append(overlaps, SilenceOverlap{Silence: s, IsBroader: true})
+		case isSuperset:
+			overlaps = append(overlaps, SilenceOverlap{Silence: s, IsBroader: false})
+		}
+	}
+	return equal, overlaps
+}
+
+// isMatchersSubset checks whether every matcher of a is contained in b.
+func isMatchersSubset(a, b types.Matchers) bool {
+	for _, ma := range a {
+		found := false
+		for _, mb := range b {
+			if isEquivalentMatcher(ma, mb) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// SilencesMatchingAlerts returns the IDs of the silences matching at least one of the alerts.
+// Silences with invalid matchers are considered matching.
+func SilencesMatchingAlerts(silences []*types.Silence, alerts []*client.ExtendedAlert) map[string]bool {
+	labelSets := make([]model.LabelSet, 0, len(alerts))
+	for _, a := range alerts {
+		labels := make(model.LabelSet, len(a.Labels))
+		for k, v := range a.Labels {
+			labels[model.LabelName(k)] = model.LabelValue(v)
+		}
+		labelSets = append(labelSets, labels)
+	}
+
+	matching := make(map[string]bool)
+	for _, s := range silences {
+		if !initMatchers(s.Matchers) {
+			matching[s.ID] = true
+			continue
+		}
+		for _, labels := range labelSets {
+			if s.Matchers.Match(labels) {
+				matching[s.ID] = true
+				break
+			}
+		}
+	}
+	return matching
+}
+
+// initMatchers compiles the regular expressions of matchers received from the Alertmanager API.
+func initMatchers(matchers types.Matchers) bool {
+	for _, m := range matchers {
+		if err := m.Init(); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package alertmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSilence(id string, endsIn time.Duration, matchers ...*types.Matcher) *types.Silence {
+	return &types.Silence{ID: id, Matchers: matchers, EndsAt: time.Now().Add(endsIn)}
+}
+
+func TestFindSilenceOverlaps(t *testing.T) {
+	alertname := &types.Matcher{Name: "alertname", Value: "KubernetesNodeNotReady"}
+	region := &types.Matcher{Name: "region", Value: "staging"}
+	node := &types.Matcher{Name: "node", Value: "node001"}
+	regionRegex := &types.Matcher{Name: "region", Value: "staging", IsRegex: true}
+
+	silences := []*types.Silence{
+		newTestSilence("equal", time.Hour, region, alertname),
+		newTestSilence("equalLonger", 2*time.Hour, alertname, region),
+		newTestSilence("broader", time.Hour, alertname),
+		newTestSilence("narrower", time.Hour, alertname, region, node),
+		newTestSilence("regex", time.Hour, alertname, regionRegex),
+		newTestSilence("unrelated", time.Hour, node),
+	}
+
+	equal, overlaps := findSilenceOverlaps(silences, types.Matchers{alertname, region})
+	require.NotNil(t, equal, "a silence with equal matchers should be found")
+	assert.Equal(t, "equalLonger", equal.ID, "the silence ending last should be found")
+
+	require.Len(t, overlaps, 2, "the broader and narrower silences should overlap")
+	assert.Equal(t, SilenceOverlap{Silence: silences[2], IsBroader: true}, overlaps[0], "the silence without region should be broader")
+	assert.Equal(t, SilenceOverlap{Silence: silences[3], IsBroader: false}, overlaps[1], "the silence with node should be narrower")
+
+	equal, overlaps = findSilenceOverlaps(silences, types.Matchers{node, &types.Matcher{Name: "createdBy", Value: "Jane Doe"}})
+	require.NotNil(t, equal, "the author should be ignored")
+	assert.Equal(t, "unrelated", equal.ID, "the silence matching the node should be found")
+	assert.Len(t, overlaps, 1, "the silence of the node should overlap")
+}
+
+func TestActiveSilences(t *testing.T) {
+	now := time.Now()
+	alertname := &types.Matcher{Name: "alertname", Value: "KubernetesNodeNotReady"}
+
+	active := newTestSilence("active", time.Hour, alertname)
+	active.StartsAt = now.Add(-time.Hour)
+	active.Status.State = types.SilenceStateActive
+
+	pending := newTestSilence("pending", 3*time.Hour, alertname)
+	pending.StartsAt = now.Add(time.Hour)
+	pending.Status.State = types.SilenceStatePending
+
+	expired := newTestSilence("expired", -time.Hour, alertname)
+	expired.StartsAt = now.Add(-2 * time.Hour)
+	expired.Status.State = types.SilenceStateExpired
+
+	silences := activeSilences([]*types.Silence{active, pending, expired}, now)
+	require.Len(t, silences, 1, "only the active silence should be returned")
+	assert.Equal(t, "active", silences[0].ID)
+
+	existing, _ := findSilenceOverlaps(activeSilences([]*types.Silence{pending}, now), types.Matchers{alertname})
+	assert.Nil(t, existing, "a pending silence should not be reused or extended")
+}
+
+func TestSilencesMatchingAlerts(t *testing.T) {
+	alerts := []*client.ExtendedAlert{
+		newTestAlert("NodeDown", "critical", "eu-de-1"),
+		newTestAlert("DiskFull", "warning", "eu-nl-1"),
+	}
+
+	silences := []*types.Silence{
+		newTestSilence("equal", time.Hour, &types.Matcher{Name: "alertname", Value: "NodeDown"}, &types.Matcher{Name: RegionLabel, Value: "eu-de-1"}),
+		newTestSilence("regex", time.Hour, &types.Matcher{Name: RegionLabel, Value: "eu-nl-.*", IsRegex: true}),
+		newTestSilence("stale", time.Hour, &types.Matcher{Name: "alertname", Value: "NodeDown"}, &types.Matcher{Name: RegionLabel, Value: "eu-nl-1"}),
+		newTestSilence("invalid", time.Hour, &types.Matcher{Name: RegionLabel, Value: "eu-(", IsRegex: true}),
+	}
+
+	assert.Equal(t,
+		map[string]bool{"equal": true, "regex": true, "invalid": true},
+		SilencesMatchingAlerts(silences, alerts),
+		"silences matching an alert or with invalid matchers should be found",
+	)
+}
@@ -22,10 +22,15 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/pager"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/sapcc/stargate/pkg/util"
 	"gopkg.in/yaml.v2"
 )
 
@@ -34,6 +39,38 @@ type Config struct {
 	AlertManager alertmanagerConfig `yaml:"alertmanager"`
 	Slack        slackConfig        `yaml:"slack"`
 	Pagerduty    pagerdutyConfig    `yaml:"pagerduty"`
+	Opsgenie     opsgenieConfig     `yaml:"opsgenie"`
+	UserMapping  userMappingConfig  `yaml:"user_mapping"`
+
+	// Authorization restricts the actions members of slack user groups are allowed to perform.
+	Authorization authorizationConfig `yaml:"authorization"`
+
+	// SilencePresets are the silences offered via buttons, reactions and the message shortcut.
+	SilencePresets []silence.Preset `yaml:"silence_presets"`
+
+	// BusinessCalendar is used to compute the end of silences like `until next working day`.
+	BusinessCalendar businessCalendarConfig `yaml:"business_calendar"`
+
+	// SilenceGuardrail asks for confirmation before creating silences matching many alerts.
+	SilenceGuardrail silenceGuardrailConfig `yaml:"silence_guardrail"`
+
+	// SilenceApproval requires another authorized user to approve long or critical silences.
+	SilenceApproval silenceApprovalConfig `yaml:"silence_approval"`
+
+	// SilenceReminders remind the creators of silences before the silences expire.
+	SilenceReminders silenceRemindersConfig `yaml:"silence_reminders"`
+
+	// SilenceJanitor reports silences which have not matched any alert for a while.
+	SilenceJanitor silenceJanitorConfig `yaml:"silence_janitor"`
+
+	// Maintenance creates silences for recurring maintenance windows.
+	Maintenance maintenanceConfig `yaml:"maintenance"`
+
+	// DeclaredSilences are reconciled from YAML files, e.g. kept in a Git repository.
+	DeclaredSilences declaredSilencesConfig `yaml:"declared_silences"`
+
+	// Pager is the backend used to acknowledge incidents. Either `pagerduty` (default), `opsgenie` or `none`.
+	Pager string `yaml:"pager"`
 
 	ListenPort  int
 	ExternalURL string
@@ -73,6 +110,20 @@ type slackConfig struct {
 	// RecheckInterval for user group memberships.
 	RecheckInterval time.Duration `yaml:"recheck_interval"`
 
+	// ShortcutCallbackID is the callback ID of the message shortcut "Silence with Stargate".
+	ShortcutCallbackID string `yaml:"shortcut_callback_id"`
+
+	// ReactionActions maps emoji names to the actions performed if a user reacts with the emoji to an alert message.
+	// Actions are `acknowledge`, `page` or the action of a silence preset, e.g. `silence1Day`.
+	ReactionActions map[string]string `yaml:"reaction_actions"`
+
+	// SocketMode enables receiving interactive payloads, slash commands and events via a websocket.
+	// Useful if Slack cannot reach the stargate.
+	SocketMode bool `yaml:"socket_mode"`
+
+	// AppLevelToken with the `connections:write` scope required for Socket Mode.
+	AppLevelToken string `yaml:"app_level_token"`
+
 	// IsDisableRTM allows disabeling the slack RTM (real time messaging).
 	IsDisableRTM bool `yaml:"-"`
 }
@@ -83,6 +134,152 @@ type pagerdutyConfig struct {
 
 	// DefaultUserEmail is used to acknowledge incidents if no Pagerduty user is found.
 	DefaultUserEmail string `yaml:"default_user_email"`
+
+	// Services maps the name of a service to the routing key of its Events API v2 integration.
+	// Used to page the on-call of a service.
+	Services map[string]string `yaml:"services"`
+
+	// DefaultService is paged if the service of an alert is unknown.
+	DefaultService string `yaml:"default_service"`
+
+	// SyncNotes enables the synchronization of incident notes with the Slack thread of the alert.
+	SyncNotes bool `yaml:"sync_notes"`
+
+	// SyncNotesInterval is the interval in which new incident notes are mirrored to the Slack thread.
+	SyncNotesInterval time.Duration `yaml:"sync_notes_interval"`
+}
+
+type opsgenieConfig struct {
+	// APIKey of an Opsgenie API integration.
+	APIKey string `yaml:"api_key"`
+
+	// APIURL of Opsgenie. Use https://api.eu.opsgenie.com for the EU instance.
+	APIURL string `yaml:"api_url"`
+
+	// DefaultUserEmail is used to acknowledge alerts if the Slack user has no email address.
+	DefaultUserEmail string `yaml:"default_user_email"`
+
+	// Services maps the name of a service to the Opsgenie team that is paged.
+	Services map[string]string `yaml:"services"`
+
+	// DefaultService is paged if the service of an alert is unknown.
+	DefaultService string `yaml:"default_service"`
+}
+
+type authorizationConfig struct {
+	// Policies grant actions to members of slack user groups.
+	// Members of the `slack.authorized_groups` are allowed to perform every action if empty.
+	Policies []policy.Policy `yaml:"policies"`
+}
+
+type businessCalendarConfig struct {
+	// TimeZone used if the Slack time zone of a user is unknown, e.g. `Europe/Berlin`. Default: UTC.
+	TimeZone string `yaml:"time_zone"`
+
+	// BusinessDayStart is the time of day business starts in the format HH:MM. Default: 09:00.
+	BusinessDayStart string `yaml:"business_day_start"`
+
+	// BusinessDayEnd is the time of day business ends in the format HH:MM. Default: 17:00.
+	BusinessDayEnd string `yaml:"business_day_end"`
+
+	// HolidaysFile is an iCal file whose all-day events are holidays.
+	HolidaysFile string `yaml:"holidays_file"`
+}
+
+type silenceGuardrailConfig struct {
+	// MaxAlerts is the number of firing alerts a silence may match without confirmation. Default: 10.
+	// Silences matching alerts of several severities or regions always require confirmation.
+	MaxAlerts int `yaml:"max_alerts"`
+
+	// Disabled creates silences without confirmation.
+	Disabled bool `yaml:"disabled"`
+}
+
+type silenceApprovalConfig struct {
+	// MaxDuration a silence may last without approval. Silences of any duration can be created without approval if 0.
+	MaxDuration time.Duration `yaml:"max_duration"`
+
+	// Severities of alerts whose silences require approval, e.g. `critical`.
+	Severities []string `yaml:"severities"`
+
+	// Channel the approval is requested in if the silence was requested in a direct message.
+	Channel string `yaml:"channel"`
+
+	// Timeout after which a silence can no longer be approved. Default: 1h.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type silenceRemindersConfig struct {
+	// Enabled reminds the creators of silences created via the stargate.
+	Enabled bool `yaml:"enabled"`
+
+	// Before is the time before the end of a silence the reminder is sent. Default: 1h.
+	Before time.Duration `yaml:"before"`
+
+	// ExtendBy is the time a silence is extended by via the reminder. Default: 24h.
+	ExtendBy time.Duration `yaml:"extend_by"`
+
+	// InThread posts the reminder to the thread the silence was requested in instead of a direct message.
+	InThread bool `yaml:"in_thread"`
+
+	// Interval in which expiring silences are checked. Default: 5m.
+	Interval time.Duration `yaml:"interval"`
+}
+
+type silenceJanitorConfig struct {
+	// Enabled periodically checks for stale silences.
+	Enabled bool `yaml:"enabled"`
+
+	// Channel stale silences are reported to. Required if enabled.
+	Channel string `yaml:"channel"`
+
+	// StaleAfter is the time a silence has not matched any alert after which it is reported. Default: 72h.
+	StaleAfter time.Duration `yaml:"stale_after"`
+
+	// ExpireWithConfirmation offers to expire stale silences once confirmed by their owner.
+	ExpireWithConfirmation bool `yaml:"expire_with_confirmation"`
+
+	// Interval in which silences are checked. Default: 1h.
+	Interval time.Duration `yaml:"interval"`
+}
+
+type maintenanceConfig struct {
+	// Enabled creates silences for the maintenance windows configured here or added via the API.
+	Enabled bool `yaml:"enabled"`
+
+	// Channel the silences of maintenance windows are announced in. Required if enabled.
+	Channel string `yaml:"channel"`
+
+	// LeadTime before the start of a window its silence is created. Default: 10m.
+	LeadTime time.Duration `yaml:"lead_time"`
+
+	// Interval in which the windows are checked. Default: 1m.
+	Interval time.Duration `yaml:"interval"`
+
+	// Windows are the recurring maintenance windows.
+	Windows []silence.MaintenanceWindow `yaml:"windows"`
+}
+
+type declaredSilencesConfig struct {
+	// Enabled reconciles the silences declared in the directory.
+	Enabled bool `yaml:"enabled"`
+
+	// Directory containing a YAML file per silence. Required if enabled.
+	Directory string `yaml:"directory"`
+
+	// Interval in which the directory is polled and reconciled. Default: 1m.
+	Interval time.Duration `yaml:"interval"`
+}
+
+type userMappingConfig struct {
+	// File maps Slack user IDs to user IDs of the pager.
+	File string `yaml:"file"`
+
+	// SlackProfileField is the ID of a custom Slack profile field containing the user ID of the pager.
+	SlackProfileField string `yaml:"slack_profile_field"`
+
+	// ReloadInterval in which the mapping file is reloaded if modified.
+	ReloadInterval time.Duration `yaml:"reload_interval"`
 }
 
 // NewConfig reads the configuration from the given filePath.
@@ -116,6 +313,48 @@ func NewConfig(opts Options, logger log.Logger) (cfg Config, err error) {
 		logger.LogFatal("invalid alertmanager configuration", "err", err)
 	}
 
+	if err := cfg.validatePager(logger); err != nil {
+		logger.LogFatal("invalid pager configuration", "err", err)
+	}
+
+	cfg.UserMapping.validate()
+
+	if err := cfg.Authorization.validate(); err != nil {
+		logger.LogFatal("invalid authorization configuration", "err", err)
+	}
+
+	if err := cfg.validateSilencePresets(); err != nil {
+		logger.LogFatal("invalid silence presets", "err", err)
+	}
+
+	if err := cfg.BusinessCalendar.validate(); err != nil {
+		logger.LogFatal("invalid business calendar configuration", "err", err)
+	}
+
+	if err := cfg.SilenceGuardrail.validate(); err != nil {
+		logger.LogFatal("invalid silence guardrail configuration", "err", err)
+	}
+
+	if err := cfg.SilenceApproval.validate(); err != nil {
+		logger.LogFatal("invalid silence approval configuration", "err", err)
+	}
+
+	if err := cfg.SilenceReminders.validate(); err != nil {
+		logger.LogFatal("invalid silence reminders configuration", "err", err)
+	}
+
+	if err := cfg.SilenceJanitor.validate(); err != nil {
+		logger.LogFatal("invalid silence janitor configuration", "err", err)
+	}
+
+	if err := cfg.Maintenance.validate(); err != nil {
+		logger.LogFatal("invalid maintenance configuration", "err", err)
+	}
+
+	if err := cfg.DeclaredSilences.validate(); err != nil {
+		logger.LogFatal("invalid declared silences configuration", "err", err)
+	}
+
 	return cfg, nil
 }
 
@@ -136,10 +375,191 @@ func (s *slackConfig) validate() error {
 		s.Command = "/stargate"
 	}
 
+	if s.ShortcutCallbackID == "" {
+		s.ShortcutCallbackID = "silence_with_stargate"
+	}
+
 	if s.RecheckInterval == 0 {
 		s.RecheckInterval = 1 * time.Hour
 	}
 
+	if s.SocketMode && s.AppLevelToken == "" {
+		return errors.New("incomplete messenger configuration: socket mode requires the `app_level_token`")
+	}
+
+	return nil
+}
+
+// validateSilencePresets validates the presets or uses the defaults if none are configured.
+func (c *Config) validateSilencePresets() error {
+	if len(c.SilencePresets) == 0 {
+		c.SilencePresets = silence.DefaultPresets()
+	}
+
+	actions := make([]string, 0, len(c.SilencePresets))
+	for i := range c.SilencePresets {
+		p := &c.SilencePresets[i]
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		if util.StringSliceContains(actions, p.Action) {
+			return fmt.Errorf("duplicate silence preset '%s'", p.Action)
+		}
+		actions = append(actions, p.Action)
+	}
+	return nil
+}
+
+func (b *businessCalendarConfig) validate() error {
+	if b.TimeZone != "" {
+		if _, err := time.LoadLocation(b.TimeZone); err != nil {
+			return errors.Wrap(err, "invalid `business_calendar.time_zone`")
+		}
+	}
+	for _, timeOfDay := range []string{b.BusinessDayStart, b.BusinessDayEnd} {
+		if timeOfDay == "" {
+			continue
+		}
+		if _, err := silence.ParseTimeOfDay(timeOfDay); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewCalendar returns the business calendar.
+func (b *businessCalendarConfig) NewCalendar() (*silence.Calendar, error) {
+	var (
+		location         *time.Location
+		businessDayStart time.Duration
+		businessDayEnd   time.Duration
+		err              error
+	)
+	if b.TimeZone != "" {
+		if location, err = time.LoadLocation(b.TimeZone); err != nil {
+			return nil, err
+		}
+	}
+	if b.BusinessDayStart != "" {
+		if businessDayStart, err = silence.ParseTimeOfDay(b.BusinessDayStart); err != nil {
+			return nil, err
+		}
+	}
+	if b.BusinessDayEnd != "" {
+		if businessDayEnd, err = silence.ParseTimeOfDay(b.BusinessDayEnd); err != nil {
+			return nil, err
+		}
+	}
+	return silence.NewCalendar(location, businessDayStart, businessDayEnd, b.HolidaysFile)
+}
+
+func (g *silenceGuardrailConfig) validate() error {
+	if g.MaxAlerts < 0 {
+		return errors.New("`silence_guardrail.max_alerts` must not be negative")
+	}
+	if g.MaxAlerts == 0 {
+		g.MaxAlerts = 10
+	}
+	return nil
+}
+
+func (a *silenceApprovalConfig) validate() error {
+	if a.MaxDuration < 0 {
+		return errors.New("`silence_approval.max_duration` must not be negative")
+	}
+	if a.Timeout < 0 {
+		return errors.New("`silence_approval.timeout` must not be negative")
+	}
+	if a.Timeout == 0 {
+		a.Timeout = 1 * time.Hour
+	}
+	return nil
+}
+
+// IsEnabled checks whether any silence requires approval.
+func (a *silenceApprovalConfig) IsEnabled() bool {
+	return a.MaxDuration > 0 || len(a.Severities) > 0
+}
+
+func (r *silenceRemindersConfig) validate() error {
+	if r.Before < 0 || r.ExtendBy < 0 || r.Interval < 0 {
+		return errors.New("`silence_reminders` durations must not be negative")
+	}
+	if r.Before == 0 {
+		r.Before = 1 * time.Hour
+	}
+	if r.ExtendBy == 0 {
+		r.ExtendBy = 24 * time.Hour
+	}
+	if r.Interval == 0 {
+		r.Interval = 5 * time.Minute
+	}
+	return nil
+}
+
+func (j *silenceJanitorConfig) validate() error {
+	if !j.Enabled {
+		return nil
+	}
+	if j.Channel == "" {
+		return errors.New("incomplete silence janitor configuration: missing `silence_janitor.channel`")
+	}
+	if j.StaleAfter < 0 || j.Interval < 0 {
+		return errors.New("`silence_janitor` durations must not be negative")
+	}
+	if j.StaleAfter == 0 {
+		j.StaleAfter = 72 * time.Hour
+	}
+	if j.Interval == 0 {
+		j.Interval = 1 * time.Hour
+	}
+	return nil
+}
+
+func (m *maintenanceConfig) validate() error {
+	if !m.Enabled {
+		return nil
+	}
+	if m.Channel == "" {
+		return errors.New("incomplete maintenance configuration: missing `maintenance.channel`")
+	}
+	if m.LeadTime < 0 || m.Interval < 0 {
+		return errors.New("`maintenance` durations must not be negative")
+	}
+	if m.LeadTime == 0 {
+		m.LeadTime = 10 * time.Minute
+	}
+	if m.Interval == 0 {
+		m.Interval = 1 * time.Minute
+	}
+
+	names := make([]string, 0, len(m.Windows))
+	for i := range m.Windows {
+		w := &m.Windows[i]
+		if err := w.Validate(); err != nil {
+			return err
+		}
+		if util.StringSliceContains(names, w.Name) {
+			return fmt.Errorf("duplicate maintenance window '%s'", w.Name)
+		}
+		names = append(names, w.Name)
+	}
+	return nil
+}
+
+func (d *declaredSilencesConfig) validate() error {
+	if !d.Enabled {
+		return nil
+	}
+	if d.Directory == "" {
+		return errors.New("incomplete declared silences configuration: missing `declared_silences.directory`")
+	}
+	if d.Interval < 0 {
+		return errors.New("`declared_silences.interval` must not be negative")
+	}
+	if d.Interval == 0 {
+		d.Interval = 1 * time.Minute
+	}
 	return nil
 }
 
@@ -151,6 +571,102 @@ func (a *alertmanagerConfig) validate() error {
 	return nil
 }
 
+func (c *Config) validatePager(logger log.Logger) error {
+	switch c.Pager {
+	case "":
+		// Pagerduty is optional if not explicitly configured.
+		if c.Pagerduty.AuthToken == "" {
+			logger.LogWarn("pagerduty integration disabled. missing `pagerduty.auth_token` in config")
+			c.Pager = pager.Backend.None
+			return nil
+		}
+		c.Pager = pager.Backend.Pagerduty
+		return c.Pagerduty.validate(logger)
+	case pager.Backend.Pagerduty:
+		return c.Pagerduty.validate(logger)
+	case pager.Backend.Opsgenie:
+		return c.Opsgenie.validate()
+	case pager.Backend.None:
+		logger.LogInfo("pager integration disabled")
+	default:
+		return fmt.Errorf("unknown pager '%s'. must be one of: %s, %s, %s", c.Pager, pager.Backend.Pagerduty, pager.Backend.Opsgenie, pager.Backend.None)
+	}
+	return nil
+}
+
+// IsPagerEnabled checks whether a pager backend is configured.
+func (c *Config) IsPagerEnabled() bool {
+	return c.Pager != "" && c.Pager != pager.Backend.None
+}
+
+func (p *pagerdutyConfig) validate(logger log.Logger) error {
+	if p.AuthToken == "" {
+		return errors.New("missing `pagerduty.auth_token` in config")
+	}
+
+	if err := validateDefaultService(p.Services, p.DefaultService); err != nil {
+		return errors.Wrap(err, "invalid `pagerduty.default_service`")
+	}
+
+	if p.DefaultUserEmail == "" {
+		logger.LogWarn("missing `pagerduty.default_user_email` in config. acknowledging incidents on behalf of users unknown to pagerduty will fail")
+	} else if !strings.Contains(p.DefaultUserEmail, "@") {
+		return fmt.Errorf("invalid `pagerduty.default_user_email` '%s'", p.DefaultUserEmail)
+	}
+
+	if p.SyncNotesInterval == 0 {
+		p.SyncNotesInterval = 1 * time.Minute
+	}
+
+	return nil
+}
+
+func (o *opsgenieConfig) validate() error {
+	if o.APIKey == "" {
+		return errors.New("missing `opsgenie.api_key` in config")
+	}
+
+	if o.APIURL == "" {
+		o.APIURL = "https://api.opsgenie.com"
+	}
+
+	if err := validateDefaultService(o.Services, o.DefaultService); err != nil {
+		return errors.Wrap(err, "invalid `opsgenie.default_service`")
+	}
+
+	return nil
+}
+
+func (a *authorizationConfig) validate() error {
+	for _, p := range a.Policies {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *userMappingConfig) validate() {
+	if u.ReloadInterval == 0 {
+		u.ReloadInterval = 5 * time.Minute
+	}
+}
+
+// IsEnabled checks whether a user mapping source is configured.
+func (u *userMappingConfig) IsEnabled() bool {
+	return u.File != "" || u.SlackProfileField != ""
+}
+
+func validateDefaultService(services map[string]string, defaultService string) error {
+	if defaultService == "" {
+		return nil
+	}
+	if _, ok := services[defaultService]; !ok {
+		return fmt.Errorf("service '%s' not found in services", defaultService)
+	}
+	return nil
+}
+
 // GetValidationToken returns either the signingSecret or verificationToken in order to validate slack messenger.
 func (s *slackConfig) GetValidationToken() string {
 	if s.SigningSecret != "" {
@@ -23,12 +23,16 @@ import "time"
 
 // Options passed via cmd line
 type Options struct {
-	ListenPort          int
-	MetricPort          int
-	IsDebug             bool
-	IsDisableSlackRTM   bool
-	ExternalURL         string
-	ConfigFilePath      string
-	PersistenceFilePath string
-	RecheckInterval     time.Duration
+	ListenPort            int
+	MetricPort            int
+	IsDebug               bool
+	IsDisableSlackRTM     bool
+	ExternalURL           string
+	ConfigFilePath        string
+	PersistenceFilePath   string
+	PreferencesFilePath   string
+	RemindersFilePath     string
+	MaintenanceFilePath   string
+	StaleSilencesFilePath string
+	RecheckInterval       time.Duration
 }
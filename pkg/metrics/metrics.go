@@ -37,12 +37,21 @@ func init() {
 		FailedOperationsTotal,
 		SnapshotSize,
 		SnapshotDuration,
+		AuthorizedUsers,
+		StaleSilences,
+		ExpiredStaleSilencesTotal,
+		DeclaredSilences,
+		DeclaredSilencesDrift,
+		DeclaredSilencesReconcileErrorsTotal,
 	)
 }
 
 // MetricNamespace ...
 const MetricNamespace = "stargate"
 
+// BackendAlertmanager is the backend label value used for operations in the Alertmanager.
+const BackendAlertmanager = "alertmanager"
+
 var (
 	// HTTPRequestsTotal ...
 	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -54,16 +63,16 @@ var (
 	// SuccessfulOperationsTotal ...
 	SuccessfulOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name:      "successful_operations_total",
-		Help:      "Count of all successful operations",
+		Help:      "Count of all successful operations by backend",
 		Namespace: MetricNamespace,
-	}, []string{"operation"})
+	}, []string{"operation", "backend"})
 
 	// FailedOperationsTotal ...
 	FailedOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name:      "failed_operations_total",
-		Help:      "Count of all failed operations",
+		Help:      "Count of all failed operations by backend",
 		Namespace: MetricNamespace,
-	}, []string{"operation"})
+	}, []string{"operation", "backend"})
 
 	// SnapshotSize ...
 	SnapshotSize = prometheus.NewGauge(prometheus.GaugeOpts{
@@ -78,6 +87,48 @@ var (
 		Help:      "Duration of the snapshot",
 		Namespace: MetricNamespace,
 	})
+
+	// AuthorizedUsers is the number of slack users authorized to interact with the stargate.
+	AuthorizedUsers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "authorized_users",
+		Help:      "Number of slack users authorized to interact with the stargate",
+		Namespace: MetricNamespace,
+	})
+
+	// StaleSilences is the number of active silences which have not matched any alert for the configured time.
+	StaleSilences = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "stale_silences",
+		Help:      "Number of active silences which have not matched any alert for the configured time",
+		Namespace: MetricNamespace,
+	})
+
+	// ExpiredStaleSilencesTotal is the number of stale silences expired after confirmation by their owner.
+	ExpiredStaleSilencesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "expired_stale_silences_total",
+		Help:      "Count of stale silences expired after confirmation by their owner",
+		Namespace: MetricNamespace,
+	})
+
+	// DeclaredSilences is the number of silences declared in the directory.
+	DeclaredSilences = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "declared_silences",
+		Help:      "Number of silences declared in the directory",
+		Namespace: MetricNamespace,
+	})
+
+	// DeclaredSilencesDrift is the number of declared silences which were missing, changed, duplicate, ended or removed during the last reconciliation.
+	DeclaredSilencesDrift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "declared_silences_drift",
+		Help:      "Number of declared silences which were missing, changed, duplicate, ended or removed during the last reconciliation",
+		Namespace: MetricNamespace,
+	}, []string{"kind"})
+
+	// DeclaredSilencesReconcileErrorsTotal is the number of reconciliations which failed to load the declared silences or to fix their drift.
+	DeclaredSilencesReconcileErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "declared_silences_reconcile_errors_total",
+		Help:      "Count of reconciliations which failed to load the declared silences or to fix their drift",
+		Namespace: MetricNamespace,
+	})
 )
 
 // Serve ...
@@ -0,0 +1,315 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package opsgenie
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/alertmanager/client"
+	"github.com/sapcc/stargate/pkg/alert"
+	"github.com/sapcc/stargate/pkg/config"
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/pager"
+)
+
+const (
+	// StatusOpen ...
+	StatusOpen = "open"
+	// StatusAcknowledged ...
+	StatusAcknowledged = "acknowledged"
+
+	// Source is used to identify requests by the stargate in Opsgenie.
+	Source = "stargate"
+
+	// listAlertsLimit is the maximum number of alerts the Opsgenie API returns per page.
+	listAlertsLimit = 100
+)
+
+// Client ...
+type Client struct {
+	logger     log.Logger
+	config     config.Config
+	httpClient *http.Client
+}
+
+type opsgenieAlert struct {
+	ID           string   `json:"id"`
+	TinyID       string   `json:"tinyId"`
+	Alias        string   `json:"alias"`
+	Message      string   `json:"message"`
+	Status       string   `json:"status"`
+	Acknowledged bool     `json:"acknowledged"`
+	Tags         []string `json:"tags"`
+}
+
+type createAlertRequest struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Responders  []responder       `json:"responders,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	Priority    string            `json:"priority,omitempty"`
+}
+
+type responder struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type actionRequest struct {
+	User   string `json:"user,omitempty"`
+	Source string `json:"source,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// NewClient creates a new opsgenie client.
+func NewClient(config config.Config, logger log.Logger) *Client {
+	return &Client{
+		logger:     log.NewLoggerWith(logger, "component", "opsgenie"),
+		config:     config,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the name of the pager backend.
+func (o *Client) Name() string {
+	return pager.Backend.Opsgenie
+}
+
+// Check verifies the connectivity to Opsgenie and the permissions of the `api_key`.
+func (o *Client) Check() error {
+	if err := o.do(http.MethodGet, "/v2/alerts?limit=1", nil, nil); err != nil {
+		return errors.Wrap(err, "failed to list opsgenie alerts. check connectivity and permissions of the `opsgenie.api_key`")
+	}
+	return nil
+}
+
+// AcknowledgeIncident acknowledges the open Opsgenie alert corresponding to the alert.
+func (o *Client) AcknowledgeIncident(extendedAlert *client.ExtendedAlert, user pager.User) error {
+	return o.closeOrAcknowledge(extendedAlert, user, "acknowledge")
+}
+
+// ResolveIncident closes the open Opsgenie alert corresponding to the alert.
+func (o *Client) ResolveIncident(extendedAlert *client.ExtendedAlert, user pager.User) error {
+	return o.closeOrAcknowledge(extendedAlert, user, "close")
+}
+
+// FindIncidentByAlert returns the open Opsgenie alert corresponding to the alert.
+func (o *Client) FindIncidentByAlert(extendedAlert *client.ExtendedAlert) (*pager.Incident, error) {
+	a, err := o.findOpsgenieAlert(extendedAlert)
+	if err != nil {
+		return nil, err
+	}
+	return toPagerIncident(a)
+}
+
+// ListIncidents returns the list of open Opsgenie alerts.
+func (o *Client) ListIncidents() ([]*pager.Incident, error) {
+	alertList, err := o.listOpenAlerts()
+	if err != nil {
+		return nil, err
+	}
+
+	incidentList := make([]*pager.Incident, 0)
+	for _, a := range alertList {
+		incident, err := toPagerIncident(a)
+		if err != nil {
+			o.logger.LogDebug("ignoring opsgenie alert", "alertID", a.ID, "err", err)
+			continue
+		}
+		incidentList = append(incidentList, incident)
+	}
+	return incidentList, nil
+}
+
+// TriggerIncident creates an Opsgenie alert for the team of the service.
+func (o *Client) TriggerIncident(event *pager.Event) error {
+	service, err := pager.ServiceOrDefault(o.config.Opsgenie.Services, event.Service, o.config.Opsgenie.DefaultService)
+	if err != nil {
+		return err
+	}
+
+	o.logger.LogDebug("creating alert", "service", service, "alias", event.DedupKey, "summary", event.Summary)
+	return o.do(http.MethodPost, "/v2/alerts", createAlertRequest{
+		Message:    event.Summary,
+		Alias:      event.DedupKey,
+		Responders: []responder{{Name: o.config.Opsgenie.Services[service], Type: "team"}},
+		Details:    event.Details,
+		Source:     Source,
+		Priority:   priorityFromSeverity(event.Severity),
+	}, nil)
+}
+
+// Services returns the names of the services whose on-call can be paged.
+func (o *Client) Services() []string {
+	return pager.SortedServiceNames(o.config.Opsgenie.Services)
+}
+
+func (o *Client) closeOrAcknowledge(extendedAlert *client.ExtendedAlert, user pager.User, action string) error {
+	// Opsgenie identifies users by their username, which is an email address.
+	userEmail := user.String()
+	if userEmail == "" {
+		userEmail = o.config.Opsgenie.DefaultUserEmail
+	}
+	if userEmail == "" {
+		return fmt.Errorf("cannot %s alert '%s' without a mail address", action, extendedAlert.Alert)
+	}
+
+	a, err := o.findOpsgenieAlert(extendedAlert)
+	if err != nil {
+		return err
+	}
+
+	o.logger.LogDebug(fmt.Sprintf("%s alert", action), "alertID", a.ID, "user", userEmail)
+	return o.do(
+		http.MethodPost,
+		fmt.Sprintf("/v2/alerts/%s/%s?identifierType=id", url.PathEscape(a.ID), action),
+		actionRequest{User: userEmail, Source: Source, Note: fmt.Sprintf("%s via Slack by %s", action, userEmail)},
+		nil,
+	)
+}
+
+// findOpsgenieAlert finds open alerts in opsgenie by alertname, region.
+func (o *Client) findOpsgenieAlert(extendedAlert *client.ExtendedAlert) (*opsgenieAlert, error) {
+	regionName, err := alert.GetRegionFromExtendedAlert(extendedAlert)
+	if err != nil {
+		return nil, err
+	}
+
+	alertName, err := alert.GetAlertnameFromExtendedAlert(extendedAlert)
+	if err != nil {
+		return nil, err
+	}
+
+	alertList, err := o.listOpenAlerts()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range alertList {
+		matchMap, err := parseRegionAndAlertnameFromOpsgenieAlert(a.Message, a.Tags)
+		if err != nil {
+			continue
+		}
+		if matchMap["alertname"] == alertName && matchMap["region"] == regionName {
+			return a, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no opsgenie alert found for alert name: '%s', region: '%s'", alertName, regionName)
+}
+
+// listOpenAlerts returns all open alerts by paging through the results.
+func (o *Client) listOpenAlerts() ([]*opsgenieAlert, error) {
+	alertList := make([]*opsgenieAlert, 0)
+	for offset := 0; ; offset += listAlertsLimit {
+		var result struct {
+			Data []*opsgenieAlert `json:"data"`
+		}
+		query := url.Values{}
+		query.Set("query", fmt.Sprintf("status:%s", StatusOpen))
+		query.Set("limit", strconv.Itoa(listAlertsLimit))
+		query.Set("offset", strconv.Itoa(offset))
+
+		if err := o.do(http.MethodGet, "/v2/alerts?"+query.Encode(), nil, &result); err != nil {
+			return nil, errors.Wrap(err, "failed to list opsgenie alerts")
+		}
+		alertList = append(alertList, result.Data...)
+
+		if len(result.Data) < listAlertsLimit {
+			return alertList, nil
+		}
+	}
+}
+
+// do sends a request to the Opsgenie API and decodes the response into result if given.
+func (o *Client) do(method, path string, body, result interface{}) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(o.config.Opsgenie.APIURL, "/")+path, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "GenieKey "+o.config.Opsgenie.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := o.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		resBody, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("opsgenie API returned %d: %s", res.StatusCode, string(resBody))
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
+
+func toPagerIncident(a *opsgenieAlert) (*pager.Incident, error) {
+	matchMap, err := parseRegionAndAlertnameFromOpsgenieAlert(a.Message, a.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	status := a.Status
+	if a.Acknowledged {
+		status = StatusAcknowledged
+	}
+
+	return &pager.Incident{
+		ID:     a.ID,
+		Name:   matchMap["alertname"],
+		Region: matchMap["region"],
+		Status: status,
+	}, nil
+}
+
+func priorityFromSeverity(severity string) string {
+	switch severity {
+	case "critical":
+		return "P1"
+	case "error":
+		return "P2"
+	case "warning":
+		return "P3"
+	default:
+		return "P4"
+	}
+}
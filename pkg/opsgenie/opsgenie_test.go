@@ -0,0 +1,191 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package opsgenie
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/sapcc/stargate/pkg/config"
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/pager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAPIKey = "key"
+
+// testServer is a fake Opsgenie API returning the given open alerts page by page.
+type testServer struct {
+	t        *testing.T
+	alerts   []*opsgenieAlert
+	pages    int
+	created  []createAlertRequest
+	actions  map[string]actionRequest
+	failWith int
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	assert.Equal(s.t, "GenieKey "+testAPIKey, r.Header.Get("Authorization"), "the request should be authenticated with the api key")
+	if s.failWith != 0 {
+		w.WriteHeader(s.failWith)
+		w.Write([]byte(`{"message":"failed"}`))
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/alerts":
+		assert.Equal(s.t, "status:open", r.URL.Query().Get("query"), "only open alerts should be listed")
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		end := offset + limit
+		if end > len(s.alerts) {
+			end = len(s.alerts)
+		}
+		page := make([]*opsgenieAlert, 0)
+		if offset < end {
+			page = s.alerts[offset:end]
+		}
+		s.pages++
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": page})
+
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/alerts":
+		var req createAlertRequest
+		assert.NoError(s.t, json.NewDecoder(r.Body).Decode(&req), "decoding the request must not raise an error")
+		s.created = append(s.created, req)
+		w.WriteHeader(http.StatusAccepted)
+
+	case r.Method == http.MethodPost:
+		var req actionRequest
+		assert.NoError(s.t, json.NewDecoder(r.Body).Decode(&req), "decoding the request must not raise an error")
+		s.actions[r.URL.Path] = req
+		w.WriteHeader(http.StatusAccepted)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestClient(t *testing.T, server *testServer) (*Client, func()) {
+	server.t = t
+	server.actions = make(map[string]actionRequest)
+	httpServer := httptest.NewServer(server)
+
+	cfg := config.Config{}
+	cfg.Opsgenie.APIURL = httpServer.URL
+	cfg.Opsgenie.APIKey = testAPIKey
+	cfg.Opsgenie.DefaultUserEmail = "default@example.com"
+	cfg.Opsgenie.Services = map[string]string{"compute": "compute-team"}
+	cfg.Opsgenie.DefaultService = "compute"
+	return NewClient(cfg, log.NewLogger(false)), httpServer.Close
+}
+
+// newTestAlerts returns count unrelated open alerts followed by the alert of NodeDown in eu-de-1.
+func newTestAlerts(count int) []*opsgenieAlert {
+	alerts := make([]*opsgenieAlert, 0, count+1)
+	for i := 0; i < count; i++ {
+		alerts = append(alerts, &opsgenieAlert{ID: fmt.Sprintf("other-%d", i), Message: fmt.Sprintf("[EU-NL-1] Alert%d - unrelated", i), Status: StatusOpen})
+	}
+	return append(alerts, &opsgenieAlert{ID: "node-down", Message: "something", Tags: []string{"alertname:NodeDown", "region:eu-de-1"}, Status: StatusOpen})
+}
+
+func newTestExtendedAlert() *client.ExtendedAlert {
+	return &client.ExtendedAlert{
+		Alert: client.Alert{
+			Labels: client.LabelSet{"alertname": "NodeDown", "region": "eu-de-1"},
+		},
+	}
+}
+
+func TestListIncidentsPaging(t *testing.T) {
+	server := &testServer{alerts: newTestAlerts(2 * listAlertsLimit)}
+	o, closeServer := newTestClient(t, server)
+	defer closeServer()
+
+	incidents, err := o.ListIncidents()
+	require.NoError(t, err, "listing incidents must not raise an error")
+	assert.Len(t, incidents, 2*listAlertsLimit+1, "the alerts of all pages should be listed")
+	assert.Equal(t, 3, server.pages, "all pages should be requested")
+}
+
+func TestFindIncidentByAlert(t *testing.T) {
+	o, closeServer := newTestClient(t, &testServer{alerts: newTestAlerts(listAlertsLimit + 10)})
+	defer closeServer()
+
+	incident, err := o.FindIncidentByAlert(newTestExtendedAlert())
+	require.NoError(t, err, "the alert on the second page should be found")
+	assert.Equal(t, "node-down", incident.ID, "the id of the incident should be equal")
+	assert.Equal(t, "NodeDown", incident.Name, "the name of the incident should be equal")
+	assert.Equal(t, "eu-de-1", incident.Region, "the region of the incident should be equal")
+
+	o, closeServer = newTestClient(t, &testServer{alerts: newTestAlerts(1)[:1]})
+	defer closeServer()
+	_, err = o.FindIncidentByAlert(newTestExtendedAlert())
+	assert.Error(t, err, "there should be no alert found")
+}
+
+func TestAcknowledgeAndResolveIncident(t *testing.T) {
+	server := &testServer{alerts: newTestAlerts(1)}
+	o, closeServer := newTestClient(t, server)
+	defer closeServer()
+
+	require.NoError(t, o.AcknowledgeIncident(newTestExtendedAlert(), pager.User{Email: "user@example.com"}), "acknowledging must not raise an error")
+	ack, ok := server.actions["/v2/alerts/node-down/acknowledge"]
+	require.True(t, ok, "the alert should be acknowledged")
+	assert.Equal(t, "user@example.com", ack.User, "the alert should be acknowledged by the user")
+	assert.Equal(t, Source, ack.Source, "the source should be the stargate")
+
+	require.NoError(t, o.ResolveIncident(newTestExtendedAlert(), pager.User{}), "closing must not raise an error")
+	closed, ok := server.actions["/v2/alerts/node-down/close"]
+	require.True(t, ok, "the alert should be closed")
+	assert.Equal(t, "default@example.com", closed.User, "the default user should be used without mail address")
+}
+
+func TestTriggerIncident(t *testing.T) {
+	server := &testServer{}
+	o, closeServer := newTestClient(t, server)
+	defer closeServer()
+
+	err := o.TriggerIncident(&pager.Event{Summary: "compute is down", Severity: "critical", DedupKey: "compute-down"})
+	require.NoError(t, err, "triggering an incident must not raise an error")
+	require.Len(t, server.created, 1, "an alert should be created")
+
+	created := server.created[0]
+	assert.Equal(t, "compute is down", created.Message, "the message should be equal")
+	assert.Equal(t, "compute-down", created.Alias, "the alias should be the dedup key")
+	assert.Equal(t, "P1", created.Priority, "critical events should have the highest priority")
+	assert.Equal(t, []responder{{Name: "compute-team", Type: "team"}}, created.Responders, "the team of the default service should be paged")
+
+	assert.Error(t, o.TriggerIncident(&pager.Event{Service: "unknown", Summary: "unknown"}), "paging an unknown service should fail")
+}
+
+func TestAPIError(t *testing.T) {
+	o, closeServer := newTestClient(t, &testServer{failWith: http.StatusForbidden})
+	defer closeServer()
+
+	err := o.Check()
+	require.Error(t, err, "the check should fail")
+	assert.Contains(t, err.Error(), "403", "the error should contain the status code")
+}
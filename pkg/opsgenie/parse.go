@@ -0,0 +1,69 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package opsgenie
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RegionAlertnameRegex is used to find the region and alertname from the message of an Opsgenie alert
+const RegionAlertnameRegex = `\[(?P<region>[^\]]+?)\]\s(?P<alertname>\S+?)\s\-`
+
+// parseRegionAndAlertnameFromOpsgenieAlert finds the alertname and region either in the tags
+// of an Opsgenie alert (alertname:<name>, region:<region>) or in its message.
+func parseRegionAndAlertnameFromOpsgenieAlert(message string, tags []string) (map[string]string, error) {
+	matchMap := make(map[string]string)
+
+	for _, tag := range tags {
+		kv := strings.SplitN(tag, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "alertname":
+			matchMap["alertname"] = kv[1]
+		case "region":
+			matchMap["region"] = strings.ToLower(kv[1])
+		}
+	}
+	if len(matchMap) == 2 {
+		return matchMap, nil
+	}
+
+	regionAlertnameRegex := regexp.MustCompile(RegionAlertnameRegex)
+	match := regionAlertnameRegex.FindStringSubmatch(message)
+	for i, name := range regionAlertnameRegex.SubexpNames() {
+		if i > 0 && i < len(match) {
+			m := match[i]
+			if name == "region" {
+				m = strings.ToLower(m)
+			}
+			matchMap[name] = m
+		}
+	}
+
+	if matchMap["alertname"] == "" || matchMap["region"] == "" {
+		return nil, fmt.Errorf("opsgenie alert does not contain alertname and/or region: '%s'", message)
+	}
+
+	return matchMap, nil
+}
@@ -0,0 +1,60 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package opsgenie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRegionAndAlertnameFromOpsgenieAlert(t *testing.T) {
+	tests := []struct {
+		message  string
+		tags     []string
+		expected map[string]string
+	}{
+		{
+			message:  "[EU-DE-1] OpenstackLbaasApiFlapping - lbaas API flapping",
+			expected: map[string]string{"alertname": "OpenstackLbaasApiFlapping", "region": "eu-de-1"},
+		},
+		{
+			message:  "[7 Alerts] [EU-DE-2] VVOLDatastoreNotAccessibleFromHost - vVOL Datastore accessibility check from host",
+			expected: map[string]string{"alertname": "VVOLDatastoreNotAccessibleFromHost", "region": "eu-de-2"},
+		},
+		{
+			message:  "something unrelated",
+			tags:     []string{"alertname:OpenstackNeutronDatapathDown", "region:EU-NL-1", "critical"},
+			expected: map[string]string{"alertname": "OpenstackNeutronDatapathDown", "region": "eu-nl-1"},
+		},
+	}
+
+	for _, test := range tests {
+		actualMatchMap, err := parseRegionAndAlertnameFromOpsgenieAlert(test.message, test.tags)
+		assert.NoError(t, err, "there should be no error parsing the opsgenie alert: %s", test.message)
+		assert.Equal(t, test.expected["alertname"], actualMatchMap["alertname"], "the alertname should be equal")
+		assert.Equal(t, test.expected["region"], actualMatchMap["region"], "the region should be equal")
+	}
+}
+
+func TestParseOpsgenieAlertWithoutRegion(t *testing.T) {
+	_, err := parseRegionAndAlertnameFromOpsgenieAlert("OpenstackLbaasApiFlapping - lbaas API flapping", nil)
+	assert.Error(t, err, "should throw an error as the message does not contain a region")
+}
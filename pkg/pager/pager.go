@@ -0,0 +1,156 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package pager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/alertmanager/client"
+)
+
+// Backend lists the supported pager backends.
+var Backend = struct {
+	Pagerduty,
+	Opsgenie,
+	None string
+}{
+	"pagerduty",
+	"opsgenie",
+	"none",
+}
+
+// Pager is implemented by every backend used to page people, e.g. Pagerduty or Opsgenie.
+type Pager interface {
+	// Name returns the name of the backend.
+	Name() string
+
+	// Check verifies the connectivity to the backend and the configuration.
+	Check() error
+
+	// AcknowledgeIncident acknowledges the incident corresponding to the alert on behalf of the given user.
+	AcknowledgeIncident(alert *client.ExtendedAlert, user User) error
+
+	// ResolveIncident resolves the incident corresponding to the alert on behalf of the given user.
+	ResolveIncident(alert *client.ExtendedAlert, user User) error
+
+	// FindIncidentByAlert returns the triggered incident corresponding to the alert.
+	FindIncidentByAlert(alert *client.ExtendedAlert) (*Incident, error)
+
+	// ListIncidents returns all triggered incidents.
+	ListIncidents() ([]*Incident, error)
+
+	// TriggerIncident pages the on-call of the service given in the event.
+	TriggerIncident(event *Event) error
+
+	// Services returns the names of the services whose on-call can be paged.
+	Services() []string
+}
+
+// User is the backend agnostic representation of a user acting via the stargate.
+type User struct {
+	// ID of the user in the backend. Takes precedence over the email address if set.
+	ID string `json:"id,omitempty"`
+
+	// Name of the user.
+	Name string `json:"name,omitempty"`
+
+	// Email address of the user.
+	Email string `json:"email,omitempty"`
+}
+
+// String returns the ID or the email address of the user.
+func (u User) String() string {
+	if u.ID != "" {
+		return u.ID
+	}
+	return u.Email
+}
+
+// Incident is the backend agnostic representation of an incident.
+type Incident struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Region string `json:"region"`
+	Status string `json:"status"`
+}
+
+// Event is used to page the on-call of a service.
+type Event struct {
+	// Service whose on-call is paged. The default service is used if empty.
+	Service string
+
+	// Summary of the event.
+	Summary string
+
+	// Severity of the event. One of critical, error, warning, info.
+	Severity string
+
+	// DedupKey is used to deduplicate events.
+	DedupKey string
+
+	// Details are attached to the incident.
+	Details map[string]string
+}
+
+// ServiceOrDefault returns the service if found in the services or the default service.
+func ServiceOrDefault(services map[string]string, service, defaultService string) (string, error) {
+	if service == "" {
+		service = defaultService
+	}
+	if _, ok := services[service]; ok {
+		return service, nil
+	}
+	return "", fmt.Errorf("unknown service '%s'. known services: %s", service, strings.Join(SortedServiceNames(services), ", "))
+}
+
+// SortedServiceNames returns the sorted names of the services.
+func SortedServiceNames(services map[string]string) []string {
+	serviceNames := make([]string, 0, len(services))
+	for name := range services {
+		serviceNames = append(serviceNames, name)
+	}
+	sort.Strings(serviceNames)
+	return serviceNames
+}
+
+// NoteSyncer is implemented by backends supporting notes on incidents.
+type NoteSyncer interface {
+	// AddIncidentNote adds a note to the incident on behalf of the given user.
+	AddIncidentNote(incidentID string, user User, content string) error
+
+	// ListIncidentNotes returns the notes of the incident.
+	ListIncidentNotes(incidentID string) ([]*Note, error)
+}
+
+// Note is the backend agnostic representation of a note on an incident.
+type Note struct {
+	ID        string `json:"id"`
+	Author    string `json:"author"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+}
+
+// UserResolver is implemented by backends able to resolve the user acting on behalf of the given user.
+type UserResolver interface {
+	// ResolveUser returns the backend user acting on behalf of the given user, which might be the default user.
+	ResolveUser(user User) (*User, error)
+}
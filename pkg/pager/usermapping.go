@@ -0,0 +1,103 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package pager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sapcc/stargate/pkg/log"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// UserMapping maps Slack user IDs to user IDs of the pager backend.
+// The mapping is read from a YAML file like:
+//
+//	# slack user ID: pager user ID
+//	U0123ABCD: P0123AB
+type UserMapping struct {
+	logger   log.Logger
+	filePath string
+
+	mtx     sync.RWMutex
+	users   map[string]string
+	modTime time.Time
+}
+
+// NewUserMapping reads the user mapping from the given file.
+func NewUserMapping(filePath string, logger log.Logger) (*UserMapping, error) {
+	m := &UserMapping{
+		logger:   log.NewLoggerWith(logger, "component", "usermapping"),
+		filePath: filePath,
+		users:    make(map[string]string),
+	}
+	return m, m.Reload()
+}
+
+// Reload reads the user mapping file again if it was modified.
+func (m *UserMapping) Reload() error {
+	fileInfo, err := os.Stat(m.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat user mapping file: %s", err.Error())
+	}
+
+	m.mtx.RLock()
+	isModified := fileInfo.ModTime() != m.modTime
+	m.mtx.RUnlock()
+	if !isModified {
+		return nil
+	}
+
+	users, err := readUserMappingFile(m.filePath)
+	if err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.users = users
+	m.modTime = fileInfo.ModTime()
+	m.logger.LogInfo("loaded user mapping", "file", m.filePath, "users", len(users))
+	return nil
+}
+
+// Get returns the pager user ID mapped to the Slack user ID.
+func (m *UserMapping) Get(slackUserID string) (string, bool) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	pagerUserID, ok := m.users[slackUserID]
+	return pagerUserID, ok && pagerUserID != ""
+}
+
+func readUserMappingFile(filePath string) (map[string]string, error) {
+	fileBytes, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read user mapping file: %s", err.Error())
+	}
+
+	users := make(map[string]string)
+	if err := yaml.Unmarshal(fileBytes, &users); err != nil {
+		return nil, fmt.Errorf("parse user mapping file: %s", err.Error())
+	}
+	return users, nil
+}
@@ -0,0 +1,50 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package pager
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserMapping(t *testing.T) {
+	pwd, err := os.Getwd()
+	require.NoError(t, err, "getting the working directory must not raise an error")
+
+	userMapping, err := NewUserMapping(path.Join(pwd, "fixtures", "usermapping.yaml"), log.NewLogger(true))
+	require.NoError(t, err, "loading the user mapping must not raise an error")
+
+	pagerUserID, ok := userMapping.Get("U0123ABCD")
+	assert.True(t, ok, "the slack user should be mapped")
+	assert.Equal(t, "P0123AB", pagerUserID, "the pager user id should be equal")
+
+	_, ok = userMapping.Get("U8901IJKL")
+	assert.False(t, ok, "a slack user mapped to an empty id should be ignored")
+
+	_, ok = userMapping.Get("UNOTMAPPED")
+	assert.False(t, ok, "the slack user should not be mapped")
+
+	assert.NoError(t, userMapping.Reload(), "reloading the unmodified user mapping should not raise an error")
+}
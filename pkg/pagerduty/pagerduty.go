@@ -22,6 +22,7 @@ package pagerduty
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -30,6 +31,7 @@ import (
 	"github.com/sapcc/stargate/pkg/alert"
 	"github.com/sapcc/stargate/pkg/config"
 	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/pager"
 )
 
 const (
@@ -37,8 +39,14 @@ const (
 	StatusAcknowledged = "acknowledged"
 	// StatusTriggered ...
 	StatusTriggered = "triggered"
+	// StatusResolved ...
+	StatusResolved = "resolved"
 	// TypeUserReference ...
 	TypeUserReference = "user_reference"
+	// EventActionTrigger ...
+	EventActionTrigger = "trigger"
+	// EventClient is the name of the client shown in incidents created by the stargate.
+	EventClient = "Stargate"
 )
 
 // ErrUserNotFound is the error raised when a user was not found by its mail address in Pagerduty.
@@ -49,13 +57,10 @@ type Client struct {
 	logger          log.Logger
 	config          config.Config
 	pagerdutyClient *pagerduty.Client
-	defaultUser     *pagerduty.User
-}
 
-// ShortPagerdutyIncident ...
-type ShortPagerdutyIncident struct {
-	Name   string `json:"name"`
-	Region string `json:"region"`
+	// defaultUser is resolved on first use and only cached once found.
+	defaultUserMtx sync.Mutex
+	defaultUser    *pagerduty.User
 }
 
 // NewClient creates a new pagerduty client.
@@ -72,23 +77,60 @@ func NewClient(config config.Config, logger log.Logger) *Client {
 		pagerdutyClient: pagerdutyClient,
 	}
 
-	// fallback to default user.
-	defaultUserEmail := client.config.Pagerduty.DefaultUserEmail
-	if client.defaultUser == nil && defaultUserEmail != "" {
-		defaultUser, err := client.findUserIDByEmail(defaultUserEmail)
-		if err != nil {
-			logger.LogError("unable to get fallback user", err)
-		}
-		client.defaultUser = defaultUser
+	return client
+}
+
+// Name returns the name of the pager backend.
+func (p *Client) Name() string {
+	return pager.Backend.Pagerduty
+}
+
+// Check verifies the connectivity to Pagerduty, the permissions of the `auth_token` and resolves the default user.
+func (p *Client) Check() error {
+	_, err := p.pagerdutyClient.ListIncidents(pagerduty.ListIncidentsOptions{
+		APIListObject: pagerduty.APIListObject{Limit: 1},
+		Statuses:      []string{StatusTriggered},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list pagerduty incidents. check connectivity and permissions of the `pagerduty.auth_token`")
 	}
 
-	return client
+	// fallback to default user. resolving it is retried on first use if it fails here.
+	_, err = p.resolveDefaultUser()
+	return err
+}
+
+// resolveDefaultUser returns the default user or nil if none is configured.
+// The user is cached once found, so a failure, e.g. due to a network issue at startup, is retried with the next call.
+func (p *Client) resolveDefaultUser() (*pagerduty.User, error) {
+	p.defaultUserMtx.Lock()
+	defer p.defaultUserMtx.Unlock()
+
+	defaultUserEmail := p.config.Pagerduty.DefaultUserEmail
+	if p.defaultUser != nil || defaultUserEmail == "" {
+		return p.defaultUser, nil
+	}
+
+	defaultUser, err := p.findUserIDByEmail(defaultUserEmail)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve `pagerduty.default_user_email` '%s'", defaultUserEmail)
+	}
+	p.defaultUser = defaultUser
+	p.logger.LogInfo("resolved pagerduty default user", "defaultUserMail", defaultUser.Email, "defaultUserID", defaultUser.ID)
+	return defaultUser, nil
+}
+
+// cachedDefaultUser returns the default user if it was already resolved.
+func (p *Client) cachedDefaultUser() *pagerduty.User {
+	p.defaultUserMtx.Lock()
+	defer p.defaultUserMtx.Unlock()
+	return p.defaultUser
 }
 
 // AcknowledgeIncident acknowledges a currently firing incident.
-func (p *Client) AcknowledgeIncident(alert *client.ExtendedAlert, userEmail string) error {
-	if userEmail == "" {
-		return fmt.Errorf("cannot acknowledge alert '%s' without a mail address", alert.Alert)
+func (p *Client) AcknowledgeIncident(alert *client.ExtendedAlert, actingUser pager.User) error {
+	if actingUser.String() == "" {
+		return fmt.Errorf("cannot acknowledge alert '%s' without a user id or mail address", alert.Alert)
 	}
 
 	incident, err := p.findIncidentByAlert(alert)
@@ -96,20 +138,14 @@ func (p *Client) AcknowledgeIncident(alert *client.ExtendedAlert, userEmail stri
 		return err
 	}
 
-	// Attempt to find Pagerduty user by email address.
-	user, err := p.findUserIDByEmail(userEmail)
+	user, err := p.findUserOrDefault(actingUser)
 	if err != nil {
-		// Return here if there's an error that is not UserNotFound.
-		if !isUserNotFound(err) {
-			return err
-		}
-
-		// Getting here means, we didn't find the user in Pagerduty.
-		// Use the default user instead.
-		user = p.defaultUser
-		p.logger.LogInfo("pagerduty user not found. falling back to default user", "userMail", userEmail, "defaultUserMail", user.Email, "defaultUserID", user.ID)
+		return err
+	}
 
-		if err := p.addActualAcknowledgerAsNoteToIncident(incident, userEmail); err != nil {
+	// Document the actual acknowledger if the default user was used.
+	if p.isDefaultUser(user, actingUser) {
+		if err := p.addActualAcknowledgerAsNoteToIncident(incident, actingUser.String()); err != nil {
 			p.logger.LogError("failed to add note to incident", err, "incidentID", incident.ID)
 		}
 	}
@@ -128,36 +164,206 @@ func (p *Client) AcknowledgeIncident(alert *client.ExtendedAlert, userEmail stri
 	)
 }
 
-// ListParsedIncidents returns a list of parsed Pagerduty incidents or an error.
-func (p *Client) ListParsedIncidents() ([]*ShortPagerdutyIncident, error) {
+// ResolveIncident resolves a currently firing incident.
+func (p *Client) ResolveIncident(alert *client.ExtendedAlert, actingUser pager.User) error {
+	if actingUser.String() == "" {
+		return fmt.Errorf("cannot resolve alert '%s' without a user id or mail address", alert.Alert)
+	}
+
+	incident, err := p.findIncidentByAlert(alert)
+	if err != nil {
+		return err
+	}
+
+	user, err := p.findUserOrDefault(actingUser)
+	if err != nil {
+		return err
+	}
+
+	resolvedIncident := *incident
+	resolvedIncident.Status = StatusResolved
+	p.logger.LogDebug("resolve incident", "incidentID", resolvedIncident.Id)
+
+	return p.pagerdutyClient.ManageIncidents(
+		user.Email,
+		[]pagerduty.Incident{resolvedIncident},
+	)
+}
+
+// FindIncidentByAlert returns the triggered incident corresponding to the alert.
+func (p *Client) FindIncidentByAlert(alert *client.ExtendedAlert) (*pager.Incident, error) {
+	incident, err := p.findIncidentByAlert(alert)
+	if err != nil {
+		return nil, err
+	}
+	return p.toPagerIncident(*incident)
+}
+
+// ListIncidents returns a list of parsed Pagerduty incidents or an error.
+func (p *Client) ListIncidents() ([]*pager.Incident, error) {
 	incidentList, err := p.listIncidents()
 	if err != nil {
 		return nil, err
 	}
 
-	shortPagerdutyIncidentList := make([]*ShortPagerdutyIncident, 0)
+	pagerIncidentList := make([]*pager.Incident, 0)
 	for _, incident := range incidentList {
-		matchMap, err := parseRegionAndAlertnameFromPagerdutySummary(incident.APIObject.Summary)
+		pagerIncident, err := p.toPagerIncident(incident)
 		if err != nil {
 			p.logger.LogError("incident parsing failed", err)
 			continue
 		}
-		foundAlertname, nameOK := matchMap["alertname"]
-		foundRegion, regionOK := matchMap["region"]
-		if !nameOK || !regionOK {
-			p.logger.LogError(
-				"incident parsing failed",
-				errors.New("pagerduty incident summary does not contain a region and/or alertname"),
-			)
-			continue
+		pagerIncidentList = append(pagerIncidentList, pagerIncident)
+	}
+	return pagerIncidentList, nil
+}
+
+// TriggerIncident pages the on-call of a service via the Events API v2.
+func (p *Client) TriggerIncident(event *pager.Event) error {
+	service, err := pager.ServiceOrDefault(p.config.Pagerduty.Services, event.Service, p.config.Pagerduty.DefaultService)
+	if err != nil {
+		return err
+	}
+
+	p.logger.LogDebug("triggering incident", "service", service, "dedupKey", event.DedupKey, "summary", event.Summary)
+	res, err := pagerduty.ManageEvent(pagerduty.V2Event{
+		RoutingKey: p.config.Pagerduty.Services[service],
+		Action:     EventActionTrigger,
+		DedupKey:   event.DedupKey,
+		Client:     EventClient,
+		ClientURL:  p.config.ExternalURL,
+		Payload: &pagerduty.V2Payload{
+			Summary:   event.Summary,
+			Source:    EventClient,
+			Severity:  event.Severity,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Details:   event.Details,
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to trigger incident for service '%s'", service)
+	}
+	p.logger.LogInfo("triggered incident", "service", service, "dedupKey", res.DedupKey)
+	return nil
+}
+
+// Services returns the names of the services whose on-call can be paged.
+func (p *Client) Services() []string {
+	return pager.SortedServiceNames(p.config.Pagerduty.Services)
+}
+
+// AddIncidentNote adds a note to the incident on behalf of the given user.
+// The default user is used if the user is unknown to Pagerduty, in which case the actual author is added to the note.
+func (p *Client) AddIncidentNote(incidentID string, actingUser pager.User, content string) error {
+	if actingUser.String() == "" {
+		return fmt.Errorf("cannot add note to incident '%s' without a user id or mail address", incidentID)
+	}
+
+	user, err := p.findUserOrDefault(actingUser)
+	if err != nil {
+		return err
+	}
+
+	if p.isDefaultUser(user, actingUser) {
+		content = fmt.Sprintf("%s (on behalf of %s)", content, actingUser.String())
+	}
+	return p.createIncidentNote(incidentID, user, content)
+}
+
+// ListIncidentNotes returns the notes of the incident.
+func (p *Client) ListIncidentNotes(incidentID string) ([]*pager.Note, error) {
+	noteList, err := p.pagerdutyClient.ListIncidentNotes(incidentID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list notes of incident '%s'", incidentID)
+	}
+
+	notes := make([]*pager.Note, 0, len(noteList))
+	for _, note := range noteList {
+		notes = append(notes, &pager.Note{
+			ID:        note.ID,
+			Author:    note.User.Summary,
+			Content:   note.Content,
+			CreatedAt: note.CreatedAt,
+		})
+	}
+	return notes, nil
+}
+
+func (p *Client) toPagerIncident(incident pagerduty.Incident) (*pager.Incident, error) {
+	matchMap, err := parseRegionAndAlertnameFromPagerdutySummary(incident.APIObject.Summary)
+	if err != nil {
+		return nil, err
+	}
+	foundAlertname, nameOK := matchMap["alertname"]
+	foundRegion, regionOK := matchMap["region"]
+	if !nameOK || !regionOK {
+		return nil, errors.New("pagerduty incident summary does not contain a region and/or alertname")
+	}
+
+	return &pager.Incident{
+		ID:     incident.Id,
+		Name:   foundAlertname,
+		Region: foundRegion,
+		Status: incident.Status,
+	}, nil
+}
+
+// ResolveUser returns the Pagerduty user acting on behalf of the given user, which might be the default user.
+func (p *Client) ResolveUser(actingUser pager.User) (*pager.User, error) {
+	user, err := p.findUserOrDefault(actingUser)
+	if err != nil {
+		return nil, err
+	}
+	return &pager.User{ID: user.ID, Name: user.Name, Email: user.Email}, nil
+}
+
+// findUserOrDefault attempts to find the Pagerduty user by ID or email address and falls back to the default user.
+func (p *Client) findUserOrDefault(actingUser pager.User) (*pagerduty.User, error) {
+	// The ID is known if the user is mapped explicitly.
+	if actingUser.ID != "" {
+		user, err := p.pagerdutyClient.GetUser(actingUser.ID, pagerduty.GetUserOptions{})
+		if err == nil {
+			return user, nil
 		}
+		p.logger.LogError("failed to get pagerduty user by id. falling back to mail address", err, "userID", actingUser.ID)
+	}
+
+	userEmail := actingUser.Email
+	if userEmail == "" {
+		return p.defaultUserOrError(fmt.Errorf("mapped pagerduty user '%s' not found", actingUser.ID), actingUser.ID)
+	}
+
+	user, err := p.findUserIDByEmail(userEmail)
+	if err == nil {
+		return user, nil
+	}
+
+	// Return here if there's an error that is not UserNotFound.
+	if !isUserNotFound(err) {
+		return nil, err
+	}
 
-		shortPagerdutyIncidentList = append(
-			shortPagerdutyIncidentList,
-			&ShortPagerdutyIncident{Name: foundAlertname, Region: foundRegion},
-		)
+	// Getting here means, we didn't find the user in Pagerduty.
+	// Use the default user instead.
+	return p.defaultUserOrError(err, userEmail)
+}
+
+func (p *Client) defaultUserOrError(err error, userName string) (*pagerduty.User, error) {
+	defaultUser, resolveErr := p.resolveDefaultUser()
+	if resolveErr != nil {
+		return nil, errors.Wrapf(err, "pagerduty user '%s' not found and %s", userName, resolveErr.Error())
 	}
-	return shortPagerdutyIncidentList, nil
+	if defaultUser == nil {
+		return nil, errors.Wrapf(err, "pagerduty user '%s' not found and no default user configured", userName)
+	}
+	p.logger.LogInfo("pagerduty user not found. falling back to default user", "user", userName, "defaultUserMail", defaultUser.Email, "defaultUserID", defaultUser.ID)
+	return defaultUser, nil
+}
+
+// isDefaultUser checks whether the default user acts on behalf of the given user.
+func (p *Client) isDefaultUser(user *pagerduty.User, actingUser pager.User) bool {
+	defaultUser := p.cachedDefaultUser()
+	return defaultUser != nil && user.ID == defaultUser.ID && actingUser.ID != user.ID && !strings.EqualFold(actingUser.Email, user.Email)
 }
 
 // findIncident finds triggered incidents in pagerduty by alertname, region.
@@ -207,13 +413,14 @@ func (p *Client) findIncidentByAlert(extendedAlert *client.ExtendedAlert) (*page
 }
 
 func (p *Client) findUserIDByEmail(userEmail string) (*pagerduty.User, error) {
-	userList, err := p.pagerdutyClient.ListUsers(pagerduty.ListUsersOptions{})
+	userList, err := p.pagerdutyClient.ListUsers(pagerduty.ListUsersOptions{Query: userEmail})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list pagerduty users")
 	}
 
 	for _, user := range userList.Users {
-		if user.Email == userEmail {
+		// Mail addresses are compared case-insensitively.
+		if strings.EqualFold(user.Email, userEmail) {
 			return &user, nil
 		}
 	}
@@ -231,22 +438,26 @@ func (p *Client) listIncidents() ([]pagerduty.Incident, error) {
 
 func (p *Client) addActualAcknowledgerAsNoteToIncident(incident *pagerduty.Incident, actualAcknowledger string) error {
 	noteContent := fmt.Sprintf("Incident was acknowledged on behalf of %s. time: %s", actualAcknowledger, time.Now().UTC().String())
+	return p.createIncidentNote(incident.Id, p.cachedDefaultUser(), noteContent)
+}
+
+func (p *Client) createIncidentNote(incidentID string, user *pagerduty.User, noteContent string) error {
 	p.logger.LogDebug(
 		"adding note to incident",
-		"incidentID", incident.Id,
+		"incidentID", incidentID,
 		"content", noteContent,
 	)
 	note := pagerduty.IncidentNote{
 		Content: noteContent,
 		User: pagerduty.APIObject{
-			ID:      p.defaultUser.ID,
+			ID:      user.ID,
 			Type:    TypeUserReference,
-			Self:    p.defaultUser.Self,
-			HTMLURL: p.defaultUser.HTMLURL,
-			Summary: p.defaultUser.Summary,
+			Self:    user.Self,
+			HTMLURL: user.HTMLURL,
+			Summary: user.Summary,
 		},
 	}
-	return p.pagerdutyClient.CreateIncidentNote(incident.Id, p.defaultUser.Email, note)
+	return p.pagerdutyClient.CreateIncidentNote(incidentID, user.Email, note)
 }
 
 func acknowledgeIncident(incident *pagerduty.Incident, user *pagerduty.User) pagerduty.Incident {
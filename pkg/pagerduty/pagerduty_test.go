@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/sapcc/go-pagerduty"
+	"github.com/sapcc/stargate/pkg/pager"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,3 +50,12 @@ func isAcknowledgementsContainsUser(acknowledgements []pagerduty.Acknowledgement
 	}
 	return false
 }
+
+func TestIsDefaultUser(t *testing.T) {
+	defaultUser := &pagerduty.User{Email: "default@example.com", APIObject: pagerduty.APIObject{ID: "D1"}}
+	p := &Client{defaultUser: defaultUser}
+
+	assert.True(t, p.isDefaultUser(defaultUser, pager.User{Email: "user@example.com"}), "the default user should act on behalf of another user")
+	assert.False(t, p.isDefaultUser(defaultUser, pager.User{Email: "Default@Example.com"}), "mail addresses should be compared case-insensitively")
+	assert.False(t, p.isDefaultUser(defaultUser, pager.User{ID: "D1"}), "the default user should not act on behalf of itself")
+}
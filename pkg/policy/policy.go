@@ -0,0 +1,214 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// Action lists the actions that can be authorized by a policy.
+var Action = struct {
+	Acknowledge,
+	Silence,
+	ExpireSilence,
+	Page string
+}{
+	"acknowledge",
+	"silence",
+	"expire_silence",
+	"page",
+}
+
+// allActions is granted to the `authorized_groups` if no policies are configured.
+var allActions = []string{Action.Acknowledge, Action.Silence, Action.ExpireSilence, Action.Page}
+
+// Policy grants members of Slack user groups the given actions on alerts within the scopes.
+type Policy struct {
+	// Name of the policy.
+	Name string `yaml:"name"`
+
+	// Groups are the names of Slack user groups whose members are granted this policy.
+	Groups []string `yaml:"groups"`
+
+	// Actions allowed by this policy.
+	Actions []string `yaml:"actions"`
+
+	// MaxSilenceDuration limits the duration of silences. Unlimited if 0.
+	MaxSilenceDuration time.Duration `yaml:"max_silence_duration"`
+
+	// Scopes restrict the policy to alerts with the given label values, e.g. `region: [eu-de-1]`.
+	// Unrestricted if empty.
+	Scopes map[string][]string `yaml:"scopes"`
+}
+
+// Validate checks the policy.
+func (p *Policy) Validate() error {
+	if len(p.Groups) == 0 {
+		return fmt.Errorf("policy '%s' without groups", p.Name)
+	}
+	if len(p.Actions) == 0 {
+		return fmt.Errorf("policy '%s' without actions", p.Name)
+	}
+	for _, action := range p.Actions {
+		if !util.StringSliceContains(allActions, action) {
+			return fmt.Errorf("policy '%s' has unknown action '%s'. must be one of: %s", p.Name, action, strings.Join(allActions, ", "))
+		}
+	}
+	return nil
+}
+
+// Request to perform an action.
+type Request struct {
+	// Action to perform.
+	Action string
+
+	// Labels of the alert or silence the action is performed on.
+	Labels map[string]string
+
+	// SilenceDuration is the duration of the silence to be created.
+	SilenceDuration time.Duration
+}
+
+// Authorizer decides whether a request is allowed by the policies.
+type Authorizer struct {
+	policies []Policy
+}
+
+// NewAuthorizer returns a new Authorizer.
+// Members of the authorized groups are allowed to perform every action if no policies are given.
+func NewAuthorizer(policies []Policy, authorizedGroups []string) *Authorizer {
+	if len(policies) == 0 {
+		policies = []Policy{{
+			Name:    "authorized_groups",
+			Groups:  authorizedGroups,
+			Actions: allActions,
+		}}
+	}
+	return &Authorizer{policies: policies}
+}
+
+// Groups returns the names of all Slack user groups referenced by the policies.
+func (a *Authorizer) Groups() []string {
+	groups := make([]string, 0)
+	for _, p := range a.policies {
+		for _, g := range p.Groups {
+			if !util.StringSliceContains(groups, g) {
+				groups = append(groups, g)
+			}
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Authorize returns nil if a member of the user groups is allowed to perform the request.
+// Otherwise the error explains why the request was denied.
+func (a *Authorizer) Authorize(userGroups []string, req Request) error {
+	var withAction []Policy
+	isMember := false
+	for _, p := range a.policies {
+		if !containsAny(p.Groups, userGroups) {
+			continue
+		}
+		isMember = true
+		if util.StringSliceContains(p.Actions, req.Action) {
+			withAction = append(withAction, p)
+		}
+	}
+
+	if !isMember {
+		return errors.New("you are not a member of a user group authorized to interact with the stargate")
+	}
+	if len(withAction) == 0 {
+		return fmt.Errorf("your user groups are not authorized to %s", actionName(req.Action))
+	}
+
+	var inScope []Policy
+	for _, p := range withAction {
+		if isInScope(p.Scopes, req.Labels) {
+			inScope = append(inScope, p)
+		}
+	}
+	if len(inScope) == 0 {
+		return fmt.Errorf("your user groups are only authorized to %s alerts with %s", actionName(req.Action), scopesToString(withAction))
+	}
+
+	if req.Action != Action.Silence {
+		return nil
+	}
+
+	var maxDuration time.Duration
+	for _, p := range inScope {
+		if p.MaxSilenceDuration == 0 || req.SilenceDuration <= p.MaxSilenceDuration {
+			return nil
+		}
+		if p.MaxSilenceDuration > maxDuration {
+			maxDuration = p.MaxSilenceDuration
+		}
+	}
+	return fmt.Errorf("your user groups are only authorized to silence alerts for up to %s", util.HumanizedDurationString(maxDuration))
+}
+
+func isInScope(scopes map[string][]string, labels map[string]string) bool {
+	for labelName, allowedValues := range scopes {
+		value, ok := labels[labelName]
+		if !ok || !util.StringSliceContains(allowedValues, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(stringSlice, searchStrings []string) bool {
+	for _, s := range searchStrings {
+		if util.StringSliceContains(stringSlice, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func actionName(action string) string {
+	return strings.Replace(action, "_", " ", -1)
+}
+
+func scopesToString(policies []Policy) string {
+	scopes := make([]string, 0)
+	for _, p := range policies {
+		labelNames := make([]string, 0, len(p.Scopes))
+		for labelName := range p.Scopes {
+			labelNames = append(labelNames, labelName)
+		}
+		sort.Strings(labelNames)
+
+		scope := make([]string, 0, len(labelNames))
+		for _, labelName := range labelNames {
+			scope = append(scope, fmt.Sprintf("%s=%s", labelName, strings.Join(p.Scopes[labelName], "|")))
+		}
+		scopes = append(scopes, strings.Join(scope, ","))
+	}
+	return strings.Join(scopes, " or ")
+}
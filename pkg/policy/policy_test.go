@@ -0,0 +1,75 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAuthorizer() *Authorizer {
+	return NewAuthorizer([]Policy{
+		{
+			Name:    "operators",
+			Groups:  []string{"operators"},
+			Actions: []string{Action.Acknowledge, Action.Silence, Action.ExpireSilence, Action.Page},
+		},
+		{
+			Name:               "compute",
+			Groups:             []string{"compute"},
+			Actions:            []string{Action.Acknowledge, Action.Silence},
+			MaxSilenceDuration: 24 * time.Hour,
+			Scopes: map[string][]string{
+				"region":  {"eu-de-1", "eu-nl-1"},
+				"service": {"compute"},
+			},
+		},
+	}, nil)
+}
+
+func TestAuthorize(t *testing.T) {
+	a := newTestAuthorizer()
+	labels := map[string]string{"region": "eu-de-1", "service": "compute"}
+
+	assert.NoError(t, a.Authorize([]string{"operators"}, Request{Action: Action.Page}), "operators should be allowed to page")
+	assert.NoError(t, a.Authorize([]string{"compute"}, Request{Action: Action.Acknowledge, Labels: labels}), "compute should be allowed to acknowledge in scope")
+	assert.NoError(t, a.Authorize([]string{"compute"}, Request{Action: Action.Silence, Labels: labels, SilenceDuration: 24 * time.Hour}), "compute should be allowed to silence for a day")
+
+	assert.Error(t, a.Authorize([]string{"network"}, Request{Action: Action.Acknowledge, Labels: labels}), "non members should be denied")
+	assert.Error(t, a.Authorize([]string{"compute"}, Request{Action: Action.Page, Labels: labels}), "compute should not be allowed to page")
+	assert.Error(t, a.Authorize([]string{"compute"}, Request{Action: Action.Acknowledge, Labels: map[string]string{"region": "ap-ae-1", "service": "compute"}}), "compute should be denied outside of its regions")
+	assert.Error(t, a.Authorize([]string{"compute"}, Request{Action: Action.Acknowledge, Labels: map[string]string{"region": "eu-de-1"}}), "compute should be denied if the service is unknown")
+	assert.Error(t, a.Authorize([]string{"compute"}, Request{Action: Action.Silence, Labels: labels, SilenceDuration: 48 * time.Hour}), "compute should not be allowed to silence for 2 days")
+	assert.NoError(t, a.Authorize([]string{"compute", "operators"}, Request{Action: Action.Silence, Labels: labels, SilenceDuration: 48 * time.Hour}), "the most permissive policy should apply")
+}
+
+func TestAuthorizeWithoutPolicies(t *testing.T) {
+	a := NewAuthorizer(nil, []string{"admins"})
+	assert.Equal(t, []string{"admins"}, a.Groups(), "the authorized groups should be used")
+	assert.NoError(t, a.Authorize([]string{"admins"}, Request{Action: Action.Silence, SilenceDuration: 31 * 24 * time.Hour}), "members of authorized groups should be allowed everything")
+	assert.Error(t, a.Authorize([]string{"others"}, Request{Action: Action.Silence}), "non members should be denied")
+}
+
+func TestValidate(t *testing.T) {
+	p := Policy{Name: "invalid", Groups: []string{"admins"}, Actions: []string{"delete"}}
+	assert.Error(t, p.Validate(), "unknown actions should be invalid")
+}
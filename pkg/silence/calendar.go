@@ -0,0 +1,116 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// maxWorkingDaySearch is the number of days searched for a working day.
+// Holidays covering every weekday, e.g. a daily event without end, would otherwise be searched forever.
+const maxWorkingDaySearch = 366
+
+// Calendar defines business hours and holidays used to compute the end of silences.
+type Calendar struct {
+	// Location is used if the time zone of the user is unknown.
+	Location *time.Location
+
+	// BusinessDayStart and BusinessDayEnd are the times of day business starts and ends on working days.
+	BusinessDayStart,
+	BusinessDayEnd time.Duration
+
+	holidays Holidays
+}
+
+// DefaultCalendar returns a calendar in UTC with business from 09:00 to 17:00 on weekdays.
+func DefaultCalendar() *Calendar {
+	return &Calendar{
+		Location:         time.UTC,
+		BusinessDayStart: 9 * time.Hour,
+		BusinessDayEnd:   17 * time.Hour,
+		holidays:         Holidays{},
+	}
+}
+
+// NewCalendar returns a new calendar. Holidays are loaded from the iCal file if given.
+func NewCalendar(location *time.Location, businessDayStart, businessDayEnd time.Duration, holidaysFile string) (*Calendar, error) {
+	c := DefaultCalendar()
+	if location != nil {
+		c.Location = location
+	}
+	if businessDayStart != 0 {
+		c.BusinessDayStart = businessDayStart
+	}
+	if businessDayEnd != 0 {
+		c.BusinessDayEnd = businessDayEnd
+	}
+	if c.BusinessDayStart >= c.BusinessDayEnd {
+		return nil, fmt.Errorf("business day must start before it ends")
+	}
+
+	if holidaysFile == "" {
+		return c, nil
+	}
+
+	data, err := ioutil.ReadFile(holidaysFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holidays file: %s", err.Error())
+	}
+	holidays, err := ParseICal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse holidays file '%s': %s", holidaysFile, err.Error())
+	}
+	c.holidays = holidays
+	return c, nil
+}
+
+// IsWorkingDay checks whether the day of the given time is neither a weekend nor a holiday.
+func (c *Calendar) IsWorkingDay(t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return !c.holidays.Contains(t)
+}
+
+// endOfBusinessDay returns the end of the current business day or of the next one if business already ended.
+func (c *Calendar) endOfBusinessDay(now time.Time) (time.Time, error) {
+	day := startOfDay(now)
+	for i := 0; i <= maxWorkingDaySearch; i++ {
+		if c.IsWorkingDay(day) && dayAt(day, c.BusinessDayEnd).After(now) {
+			return dayAt(day, c.BusinessDayEnd), nil
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}, fmt.Errorf("no working day within %d days. check the holidays", maxWorkingDaySearch)
+}
+
+// startOfNextWorkingDay returns the start of business of the next working day after today.
+func (c *Calendar) startOfNextWorkingDay(now time.Time) (time.Time, error) {
+	day := startOfDay(now).AddDate(0, 0, 1)
+	for i := 0; i < maxWorkingDaySearch; i++ {
+		if c.IsWorkingDay(day) {
+			return dayAt(day, c.BusinessDayStart), nil
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}, fmt.Errorf("no working day within %d days. check the holidays", maxWorkingDaySearch)
+}
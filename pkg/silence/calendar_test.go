@@ -0,0 +1,110 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHolidays = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//stargate//holidays//EN
+BEGIN:VEVENT
+UID:christmas
+DTSTART;VALUE=DATE:20191224
+DTEND;VALUE=DATE:20191227
+RRULE:FREQ=YEARLY
+SUMMARY:Christmas
+END:VEVENT
+BEGIN:VEVENT
+UID:easter-monday
+DTSTART;VALUE=DATE:20190422
+SUMMARY:Easter
+  Monday
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestParseICal(t *testing.T) {
+	holidays, err := ParseICal([]byte(testHolidays))
+	require.NoError(t, err, "there should be no error parsing the iCal")
+
+	assert.True(t, holidays.Contains(time.Date(2019, time.April, 22, 12, 0, 0, 0, time.UTC)), "easter monday should be a holiday")
+	assert.False(t, holidays.Contains(time.Date(2020, time.April, 22, 12, 0, 0, 0, time.UTC)), "easter monday does not recur yearly")
+	assert.True(t, holidays.Contains(time.Date(2025, time.December, 26, 0, 0, 0, 0, time.UTC)), "christmas should recur yearly")
+	assert.False(t, holidays.Contains(time.Date(2025, time.December, 27, 0, 0, 0, 0, time.UTC)), "the end of an event is exclusive")
+
+	holidays, err = ParseICal([]byte("BEGIN:VEVENT\nDTSTART;VALUE=DATE:20190501\nRRULE:FREQ=YEARLY;COUNT=2\nEND:VEVENT\n"))
+	require.NoError(t, err, "there should be no error parsing the iCal")
+	assert.True(t, holidays.Contains(time.Date(2020, time.May, 1, 23, 0, 0, 0, time.UTC)), "the second occurrence should be a holiday")
+	assert.False(t, holidays.Contains(time.Date(2021, time.May, 1, 12, 0, 0, 0, time.UTC)), "the recurrence should end after 2 occurrences")
+
+	_, err = ParseICal([]byte("BEGIN:VEVENT\nSUMMARY:broken\nEND:VEVENT\n"))
+	assert.Error(t, err, "should throw an error as the event has no start")
+}
+
+func TestCalendar(t *testing.T) {
+	holidays, err := ParseICal([]byte(testHolidays))
+	require.NoError(t, err, "there should be no error parsing the iCal")
+	calendar := DefaultCalendar()
+	calendar.holidays = holidays
+
+	// Thursday before easter.
+	thursday := time.Date(2019, time.April, 18, 18, 0, 0, 0, time.UTC)
+	endsAt, err := EndsAt("until next working day", thursday, calendar)
+	require.NoError(t, err, "there should be no error parsing the expression")
+	assert.Equal(t, time.Date(2019, time.April, 19, 9, 0, 0, 0, time.UTC), endsAt, "friday should be the next working day")
+
+	goodFriday := time.Date(2019, time.April, 19, 0, 0, 0, 0, time.UTC)
+	calendar.holidays.events = append(calendar.holidays.events, Event{StartsAt: goodFriday, EndsAt: goodFriday.AddDate(0, 0, 1)})
+	endsAt, err = EndsAt("until next working day", thursday, calendar)
+	require.NoError(t, err, "there should be no error parsing the expression")
+	assert.Equal(t, time.Date(2019, time.April, 23, 9, 0, 0, 0, time.UTC), endsAt, "the weekend and holidays should be skipped")
+
+	endsAt, err = EndsAt("until end of business day", thursday, calendar)
+	require.NoError(t, err, "there should be no error parsing the expression")
+	assert.Equal(t, time.Date(2019, time.April, 23, 17, 0, 0, 0, time.UTC), endsAt, "business already ended on thursday")
+
+	saturday := time.Date(2019, time.March, 16, 10, 0, 0, 0, time.UTC)
+	endsAt, err = DefaultCalendar().endOfBusinessDay(saturday)
+	require.NoError(t, err, "there should be no error finding the end of business")
+	assert.Equal(t, time.Date(2019, time.March, 18, 17, 0, 0, 0, time.UTC), endsAt, "should be the end of business on monday")
+
+	_, err = NewCalendar(nil, 18*time.Hour, 8*time.Hour, "")
+	assert.Error(t, err, "should throw an error as business ends before it starts")
+}
+
+func TestCalendarWithoutWorkingDays(t *testing.T) {
+	holidays, err := ParseICal([]byte("BEGIN:VEVENT\nDTSTART;VALUE=DATE:20190101\nRRULE:FREQ=DAILY\nSUMMARY:every day\nEND:VEVENT\n"))
+	require.NoError(t, err, "there should be no error parsing the iCal")
+	calendar := DefaultCalendar()
+	calendar.holidays = holidays
+
+	now := time.Date(2019, time.April, 18, 10, 0, 0, 0, time.UTC)
+	_, err = EndsAt("until end of business day", now, calendar)
+	assert.Error(t, err, "should throw an error as there is no working day")
+
+	_, err = EndsAt("until next working day", now, calendar)
+	assert.Error(t, err, "should throw an error as business ends before it starts")
+}
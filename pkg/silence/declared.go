@@ -0,0 +1,161 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var (
+	declaredNameRegex = regexp.MustCompile(`^[\w./-]+$`)
+
+	// declaredMarkerRegex matches the markers appended to the comments of silences owned by the reconciler.
+	declaredMarkerRegex = regexp.MustCompile(`\[stargate declared: name=([\w./-]+)\]`)
+)
+
+// DeclaredSilence is a long-lived silence declared in a YAML file, e.g. kept in a Git repository.
+type DeclaredSilence struct {
+	// Name is the path of the file relative to the directory without extension, e.g. `team-a/legacy-service`.
+	Name string `yaml:"-" json:"name"`
+
+	// Matchers of the silence.
+	Matchers []Matcher `yaml:"matchers" json:"matchers"`
+
+	// EndsAt is the end of the silence, e.g. `2019-12-31T00:00:00Z`.
+	EndsAt time.Time `yaml:"ends_at" json:"endsAt"`
+
+	// Reason is the comment of the silence.
+	Reason string `yaml:"reason" json:"reason"`
+
+	// Owner is the creator of the silence.
+	Owner string `yaml:"owner" json:"owner"`
+}
+
+// Validate checks the declared silence.
+func (d *DeclaredSilence) Validate() error {
+	if !declaredNameRegex.MatchString(d.Name) {
+		return fmt.Errorf("invalid declared silence name '%s'. use letters, digits, '_', '.', '-' and '/'", d.Name)
+	}
+	if err := ValidateMatchers(d.SilenceMatchers()); err != nil {
+		return fmt.Errorf("declared silence '%s': %s", d.Name, err.Error())
+	}
+	if d.EndsAt.IsZero() {
+		return fmt.Errorf("declared silence '%s' without ends_at", d.Name)
+	}
+	if d.Reason == "" {
+		return fmt.Errorf("declared silence '%s' without reason", d.Name)
+	}
+	if d.Owner == "" {
+		return fmt.Errorf("declared silence '%s' without owner", d.Name)
+	}
+	return nil
+}
+
+// SilenceMatchers returns the sorted matchers of the silence.
+func (d *DeclaredSilence) SilenceMatchers() types.Matchers {
+	return silenceMatchers(d.Matchers)
+}
+
+// RenderComment returns the comment of the silence.
+// The marker at its end tags the silence as owned by the reconciler, e.g. '[stargate declared: name=team-a/legacy-service]'.
+func (d *DeclaredSilence) RenderComment() string {
+	return fmt.Sprintf("%s\n[stargate declared: name=%s]", d.Reason, d.Name)
+}
+
+// ParseDeclaredMarker returns the name of the declared silence a silence was created for.
+func ParseDeclaredMarker(comment string) (string, bool) {
+	m := declaredMarkerRegex.FindStringSubmatch(comment)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// LoadDeclaredSilences loads the silences declared in the `.yaml` and `.yml` files of the directory and its subdirectories.
+// Hidden files and directories like `.git` are skipped. An error is returned if any file is invalid.
+func LoadDeclaredSilences(dir string) ([]DeclaredSilence, error) {
+	// The directory might be a symlink, e.g. to the worktree checked out by git-sync.
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read declared silences directory: %s", err.Error())
+	}
+
+	silences := make([]DeclaredSilence, 0)
+	names := make(map[string]bool)
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if info.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(strings.TrimSuffix(relPath, ext))
+		if names[name] {
+			return fmt.Errorf("duplicate declared silence '%s'", name)
+		}
+		names[name] = true
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read declared silence '%s': %s", name, err.Error())
+		}
+		var d DeclaredSilence
+		if err := yaml.UnmarshalStrict(data, &d); err != nil {
+			return fmt.Errorf("failed to parse declared silence '%s': %s", name, err.Error())
+		}
+		d.Name = name
+		if err := d.Validate(); err != nil {
+			return err
+		}
+
+		silences = append(silences, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(silences, func(i, j int) bool {
+		return silences[i].Name < silences[j].Name
+	})
+	return silences, nil
+}
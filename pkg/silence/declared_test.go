@@ -0,0 +1,79 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDeclaredSilences(t *testing.T) {
+	silences, err := LoadDeclaredSilences(filepath.Join("fixtures", "declared"))
+	require.NoError(t, err, "loading the declared silences must not raise an error")
+	require.Len(t, silences, 2, "hidden directories and other files should be skipped")
+
+	assert.Equal(t, "network", silences[0].Name, "the name should be the file name without extension")
+
+	d := silences[1]
+	assert.Equal(t, "team-a/legacy-service", d.Name, "the name should be the path relative to the directory")
+	assert.Equal(t, `{region=~"eu-de-.*",service="legacy"}`, d.SilenceMatchers().String(), "the matchers should be equal")
+	assert.Equal(t, time.Date(2019, time.December, 31, 0, 0, 0, 0, time.UTC), d.EndsAt.UTC(), "the end should be equal")
+	assert.Equal(t, "team-a", d.Owner, "the owner should be equal")
+
+	name, ok := ParseDeclaredMarker(d.RenderComment())
+	require.True(t, ok, "the marker should be found")
+	assert.Equal(t, d.Name, name, "the name in the marker should be equal")
+
+	_, ok = ParseDeclaredMarker("silenced by the stargate\n[stargate: user=U0123]")
+	assert.False(t, ok, "silences created by users should not be owned by the reconciler")
+}
+
+func TestLoadDeclaredSilencesInvalid(t *testing.T) {
+	tests := map[string]map[string]string{
+		"without matchers":  {"a.yaml": "ends_at: 2019-12-31T00:00:00Z\nreason: test\nowner: test\n"},
+		"without owner":     {"a.yaml": "matchers: [{name: service, value: legacy}]\nends_at: 2019-12-31T00:00:00Z\nreason: test\n"},
+		"without ends_at":   {"a.yaml": "matchers: [{name: service, value: legacy}]\nreason: test\nowner: test\n"},
+		"unknown field":     {"a.yaml": "matchers: [{name: service, value: legacy}]\nends_at: 2019-12-31T00:00:00Z\nreason: test\nowner: test\nstarts_at: 2019-01-01T00:00:00Z\n"},
+		"duplicate name":    {"a.yaml": "matchers: [{name: service, value: legacy}]\nends_at: 2019-12-31T00:00:00Z\nreason: test\nowner: test\n", "a.yml": "matchers: [{name: service, value: legacy}]\nends_at: 2019-12-31T00:00:00Z\nreason: test\nowner: test\n"},
+		"name with a space": {"a b.yaml": "matchers: [{name: service, value: legacy}]\nends_at: 2019-12-31T00:00:00Z\nreason: test\nowner: test\n"},
+		"matching all":      {"a.yaml": "matchers: [{name: service, value: '.*', is_regex: true}]\nends_at: 2019-12-31T00:00:00Z\nreason: test\nowner: test\n"},
+	}
+
+	for name, files := range tests {
+		dir, err := ioutil.TempDir("", "declared")
+		require.NoError(t, err, "creating a temporary directory must not raise an error")
+		for fileName, content := range files {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, fileName), []byte(content), 0644), "writing the file must not raise an error")
+		}
+
+		_, err = LoadDeclaredSilences(dir)
+		assert.Error(t, err, "loading the declared silences should fail: %s", name)
+		os.RemoveAll(dir)
+	}
+
+	_, err := LoadDeclaredSilences("does-not-exist")
+	assert.Error(t, err, "loading a missing directory should fail")
+}
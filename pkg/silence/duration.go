@@ -0,0 +1,137 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+var (
+	untilEndOfBusinessDayRegex = regexp.MustCompile(`^until end of business day$`)
+	untilNextWorkingDayRegex   = regexp.MustCompile(`^until next working day$`)
+	untilTomorrowRegex         = regexp.MustCompile(`^until tomorrow(?: (\d{1,2}:\d{2}))?$`)
+	untilNextWeekdayRegex      = regexp.MustCompile(`^until next (monday|tuesday|wednesday|thursday|friday|saturday|sunday)(?: (\d{1,2}:\d{2}))?$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// EndsAt returns the end of a silence starting now given a duration expression.
+// Supported expressions are durations like `2h`, `1d` or `1w`, `until tomorrow [HH:MM]`, `until next <weekday> [HH:MM]`,
+// `until end of business day` and `until next working day`.
+// Times of day are in the location of now and default to the start of business of the calendar.
+func EndsAt(expression string, now time.Time, calendar *Calendar) (time.Time, error) {
+	if calendar == nil {
+		calendar = DefaultCalendar()
+	}
+	expr := strings.ToLower(strings.TrimSpace(expression))
+
+	if untilEndOfBusinessDayRegex.MatchString(expr) {
+		return calendar.endOfBusinessDay(now)
+	}
+
+	if untilNextWorkingDayRegex.MatchString(expr) {
+		return calendar.startOfNextWorkingDay(now)
+	}
+
+	if m := untilTomorrowRegex.FindStringSubmatch(expr); m != nil {
+		return atTimeOfDay(now.AddDate(0, 0, 1), m[1], calendar.BusinessDayStart)
+	}
+
+	if m := untilNextWeekdayRegex.FindStringSubmatch(expr); m != nil {
+		return atTimeOfDay(nextWeekday(now, weekdays[m[1]]), m[2], calendar.BusinessDayStart)
+	}
+
+	duration, err := parseDuration(expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid duration expression '%s'. use a duration like 2h, 1d, 1w, 'until tomorrow [HH:MM]', 'until next <weekday> [HH:MM]', 'until end of business day' or 'until next working day'", expression)
+	}
+	if duration <= 0 {
+		return time.Time{}, fmt.Errorf("duration expression '%s' must be greater than 0", expression)
+	}
+	return now.Add(duration), nil
+}
+
+// ValidateExpression checks whether the duration expression can be parsed.
+func ValidateExpression(expression string) error {
+	_, err := EndsAt(expression, time.Now(), nil)
+	return err
+}
+
+// parseDuration parses Go durations like `1h30m` as well as Prometheus durations like `1d` or `1w`.
+func parseDuration(expr string) (time.Duration, error) {
+	if d, err := time.ParseDuration(expr); err == nil {
+		return d, nil
+	}
+	d, err := model.ParseDuration(expr)
+	return time.Duration(d), err
+}
+
+// nextWeekday returns the start of the next given weekday. Always in the future, i.e. a week later if today is that weekday.
+func nextWeekday(now time.Time, weekday time.Weekday) time.Time {
+	days := (int(weekday) - int(now.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return startOfDay(now.AddDate(0, 0, days))
+}
+
+// atTimeOfDay returns the given day at the time of day in the format HH:MM or at the default time of day if empty.
+func atTimeOfDay(day time.Time, timeOfDay string, defaultTimeOfDay time.Duration) (time.Time, error) {
+	if timeOfDay == "" {
+		return dayAt(day, defaultTimeOfDay), nil
+	}
+
+	d, err := ParseTimeOfDay(timeOfDay)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return dayAt(day, d), nil
+}
+
+// ParseTimeOfDay parses a time of day in the format HH:MM.
+func ParseTimeOfDay(timeOfDay string) (time.Duration, error) {
+	t, err := time.Parse("15:04", timeOfDay)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day '%s'. use the format HH:MM", timeOfDay)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return dayAt(t, 0)
+}
+
+// dayAt returns the day of the given time at the time of day. Robust against daylight saving time changes.
+func dayAt(t time.Time, timeOfDay time.Duration) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), int(timeOfDay/time.Hour), int(timeOfDay%time.Hour/time.Minute), 0, 0, t.Location())
+}
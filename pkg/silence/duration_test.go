@@ -0,0 +1,72 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndsAt(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2019, time.March, 13, 10, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"2h", now.Add(2 * time.Hour)},
+		{"1h30m", now.Add(90 * time.Minute)},
+		{"1d", now.Add(24 * time.Hour)},
+		{"1w", now.Add(7 * 24 * time.Hour)},
+		{"until tomorrow", time.Date(2019, time.March, 14, 9, 0, 0, 0, time.UTC)},
+		{"until tomorrow 08:00", time.Date(2019, time.March, 14, 8, 0, 0, 0, time.UTC)},
+		{"until next Monday 09:00", time.Date(2019, time.March, 18, 9, 0, 0, 0, time.UTC)},
+		{"until next wednesday", time.Date(2019, time.March, 20, 9, 0, 0, 0, time.UTC)},
+		{"until end of business day", time.Date(2019, time.March, 13, 17, 0, 0, 0, time.UTC)},
+		{"until next working day", time.Date(2019, time.March, 14, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		endsAt, err := EndsAt(tc.expression, now, nil)
+		require.NoError(t, err, "there should be no error parsing '%s'", tc.expression)
+		assert.Equal(t, tc.expected, endsAt, "the end of '%s' should be equal", tc.expression)
+	}
+
+	for _, expression := range []string{"", "forever", "-1h", "until next month", "until tomorrow 25:00"} {
+		_, err := EndsAt(expression, now, nil)
+		assert.Error(t, err, "should throw an error parsing '%s'", expression)
+	}
+}
+
+func TestEndsAtInTimeZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err, "there should be no error loading the time zone")
+
+	// Sunday evening in Tokyo is still Sunday morning in UTC.
+	now := time.Date(2019, time.March, 17, 10, 0, 0, 0, time.UTC).In(tokyo)
+	endsAt, err := EndsAt("until next Monday", now, nil)
+	require.NoError(t, err, "there should be no error parsing the expression")
+	assert.Equal(t, time.Date(2019, time.March, 18, 9, 0, 0, 0, tokyo), endsAt, "the silence should end at the start of business in Tokyo")
+	assert.Equal(t, 14*time.Hour, endsAt.Sub(now), "the duration should be equal")
+}
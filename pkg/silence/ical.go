@@ -0,0 +1,370 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// icalWeekdays are the weekdays as used by BYDAY.
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// icalDurationRegex matches durations like `P1D`, `PT2H30M` or `P1W`.
+var icalDurationRegex = regexp.MustCompile(`^\+?P(?:(\d+)W|(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?)$`)
+
+// Holidays are the days without business.
+type Holidays struct {
+	events []Event
+}
+
+// Contains checks whether the day of the given time is a holiday.
+func (h Holidays) Contains(t time.Time) bool {
+	// All-day events are parsed in UTC, so the day of the given time is compared in UTC as well.
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+	for _, e := range h.events {
+		if start, _, ok := e.Next(day); ok && start.Before(nextDay) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseICal parses the all-day events of an iCal file as holidays.
+// Events spanning multiple days and recurring events are supported as by ParseICalEvents. Events without end last one day.
+func ParseICal(data []byte) (Holidays, error) {
+	events, err := parseICalEvents(data, time.UTC)
+	if err != nil {
+		return Holidays{}, err
+	}
+	for i, e := range events {
+		if !e.EndsAt.After(e.StartsAt) {
+			events[i].EndsAt = e.StartsAt.AddDate(0, 0, 1)
+		}
+	}
+	return Holidays{events: events}, nil
+}
+
+// Event is a timed event of an iCal file.
+type Event struct {
+	Summary  string
+	StartsAt time.Time
+	EndsAt   time.Time
+
+	recurrence *recurrence
+}
+
+// recurrence of an event every number of years or days.
+type recurrence struct {
+	years,
+	days,
+	count int
+	until time.Time
+
+	// byParts like BYDAY are only supported if they repeat the start of the event.
+	byParts map[string]string
+}
+
+// ParseICalEvents parses the events of an iCal file.
+// Date-times without time zone are in the time zone given via TZID or otherwise in the given location.
+// The end is given via DTEND or DURATION. Events must end after they start.
+// Daily, weekly and yearly recurring events are supported considering INTERVAL, COUNT and UNTIL.
+// BYDAY, BYMONTH and BYMONTHDAY are only supported if they repeat the start. Other parts of the RRULE, EXDATE and RDATE raise an error.
+func ParseICalEvents(data []byte, location *time.Location) ([]Event, error) {
+	events, err := parseICalEvents(data, location)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		if !e.EndsAt.After(e.StartsAt) {
+			return nil, fmt.Errorf("event '%s' must end after it starts", e.Summary)
+		}
+	}
+	return events, nil
+}
+
+// parseICalEvents parses the events of an iCal file. All-day events without end last one day. Other events without end end when they start.
+func parseICalEvents(data []byte, location *time.Location) ([]Event, error) {
+	events := make([]Event, 0)
+
+	var (
+		isEvent,
+		isAllDay,
+		hasEnd bool
+		duration string
+		event    Event
+	)
+	for _, line := range unfoldICalLines(data) {
+		name, params, value := parseICalLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			isEvent, isAllDay, hasEnd, duration, event = true, false, false, "", Event{}
+
+		case name == "END" && value == "VEVENT":
+			if !isEvent || event.StartsAt.IsZero() {
+				return nil, fmt.Errorf("event without DTSTART")
+			}
+			switch {
+			case hasEnd && duration != "":
+				return nil, fmt.Errorf("event '%s' must not have both DTEND and DURATION", event.Summary)
+			case duration != "":
+				endsAt, err := addICalDuration(event.StartsAt, duration)
+				if err != nil {
+					return nil, fmt.Errorf("event '%s': %s", event.Summary, err.Error())
+				}
+				event.EndsAt = endsAt
+			case !hasEnd && isAllDay:
+				event.EndsAt = event.StartsAt.AddDate(0, 0, 1)
+			case !hasEnd:
+				event.EndsAt = event.StartsAt
+			}
+			if event.recurrence != nil {
+				if err := event.recurrence.validateByParts(event.StartsAt); err != nil {
+					return nil, fmt.Errorf("event '%s': %s", event.Summary, err.Error())
+				}
+			}
+			events = append(events, event)
+			isEvent = false
+
+		case isEvent && (name == "DTSTART" || name == "DTEND"):
+			t, err := parseICalDateTime(params, value, location)
+			if err != nil {
+				return nil, err
+			}
+			if name == "DTSTART" {
+				event.StartsAt = t
+				isAllDay = isICalDate(params, value)
+			} else {
+				event.EndsAt = t
+				hasEnd = true
+			}
+
+		case isEvent && name == "DURATION":
+			duration = value
+
+		case isEvent && name == "SUMMARY":
+			event.Summary = value
+
+		case isEvent && name == "RRULE":
+			r, err := parseICalRecurrence(value, location)
+			if err != nil {
+				return nil, err
+			}
+			event.recurrence = r
+
+		case isEvent && (name == "EXDATE" || name == "RDATE"):
+			return nil, fmt.Errorf("event '%s': %s is not supported", event.Summary, name)
+		}
+	}
+	return events, nil
+}
+
+// Next returns the start and end of the first occurrence of the event ending after the given time.
+// Returns false if there is none.
+func (e Event) Next(t time.Time) (time.Time, time.Time, bool) {
+	duration := e.EndsAt.Sub(e.StartsAt)
+	if e.recurrence == nil {
+		return e.StartsAt, e.EndsAt, e.EndsAt.After(t)
+	}
+
+	// Skip the occurrences long before. Days might be shorter or longer than 24h due to daylight saving time and years have leap days.
+	i := 0
+	if elapsed := t.Add(-duration).Sub(e.StartsAt); elapsed > 0 {
+		period := time.Duration(e.recurrence.years*365+e.recurrence.days) * 24 * time.Hour
+		i = int(elapsed/period) - 1
+		if i < 0 {
+			i = 0
+		}
+	}
+
+	for ; ; i++ {
+		if e.recurrence.count > 0 && i >= e.recurrence.count {
+			return time.Time{}, time.Time{}, false
+		}
+		start := e.StartsAt.AddDate(i*e.recurrence.years, 0, i*e.recurrence.days)
+		if !e.recurrence.until.IsZero() && start.After(e.recurrence.until) {
+			return time.Time{}, time.Time{}, false
+		}
+		if end := start.Add(duration); end.After(t) {
+			return start, end, true
+		}
+	}
+}
+
+// parseICalRecurrence parses a rule like `FREQ=WEEKLY;INTERVAL=2;COUNT=10`.
+func parseICalRecurrence(rule string, location *time.Location) (*recurrence, error) {
+	r := &recurrence{byParts: map[string]string{}}
+	interval := 1
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid recurrence '%s'", rule)
+		}
+
+		var err error
+		switch key := strings.ToUpper(kv[0]); key {
+		case "FREQ":
+			switch strings.ToUpper(kv[1]) {
+			case "DAILY":
+				r.days = 1
+			case "WEEKLY":
+				r.days = 7
+			case "YEARLY":
+				r.years = 1
+			default:
+				return nil, fmt.Errorf("unsupported recurrence '%s'", rule)
+			}
+		case "INTERVAL":
+			interval, err = strconv.Atoi(kv[1])
+		case "COUNT":
+			r.count, err = strconv.Atoi(kv[1])
+		case "UNTIL":
+			r.until, err = parseICalDateTime("", kv[1], location)
+		case "BYDAY", "BYMONTH", "BYMONTHDAY":
+			r.byParts[key] = strings.ToUpper(kv[1])
+		case "WKST":
+			// The start of the week only matters for multiple days per week.
+		default:
+			return nil, fmt.Errorf("unsupported recurrence '%s': %s is not supported", rule, key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid recurrence '%s': %s", rule, err.Error())
+		}
+	}
+
+	if r.days == 0 && r.years == 0 || interval < 1 {
+		return nil, fmt.Errorf("invalid recurrence '%s'", rule)
+	}
+	r.years *= interval
+	r.days *= interval
+	return r, nil
+}
+
+// validateByParts checks that the BYDAY, BYMONTH and BYMONTHDAY parts of the rule select the same occurrences as the start alone.
+func (r *recurrence) validateByParts(start time.Time) error {
+	for key, value := range r.byParts {
+		var isRedundant bool
+		switch key {
+		case "BYDAY":
+			weekday, ok := icalWeekdays[value]
+			isRedundant = ok && r.days == 7 && weekday == start.Weekday()
+		case "BYMONTH":
+			isRedundant = r.years > 0 && value == strconv.Itoa(int(start.Month()))
+		case "BYMONTHDAY":
+			isRedundant = r.years > 0 && value == strconv.Itoa(start.Day())
+		}
+		if !isRedundant {
+			return fmt.Errorf("%s=%s is not supported unless it repeats the start of the event", key, value)
+		}
+	}
+	return nil
+}
+
+// addICalDuration adds a duration like `P1D`, `PT2H30M` or `P1W` to the time. Weeks and days are added as calendar days.
+func addICalDuration(t time.Time, value string) (time.Time, error) {
+	m := icalDurationRegex.FindStringSubmatch(value)
+	if m == nil || strings.HasSuffix(value, "P") || strings.HasSuffix(value, "T") {
+		return time.Time{}, fmt.Errorf("invalid duration '%s'", value)
+	}
+
+	n := make([]int, len(m))
+	for i := 1; i < len(m); i++ {
+		n[i], _ = strconv.Atoi(m[i])
+	}
+	return t.AddDate(0, 0, 7*n[1]+n[2]).Add(time.Duration(n[3])*time.Hour + time.Duration(n[4])*time.Minute + time.Duration(n[5])*time.Second), nil
+}
+
+// unfoldICalLines returns the lines of an iCal file. Long lines are folded by starting the continuation with a space or tab.
+func unfoldICalLines(data []byte) []string {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseICalLine splits a line like `DTSTART;VALUE=DATE:20191225` into its name, parameters and value.
+func parseICalLine(line string) (string, string, string) {
+	idx := strings.Index(line, ":")
+	if idx < 0 {
+		return "", "", ""
+	}
+	name, value := line[:idx], line[idx+1:]
+	params := ""
+	if i := strings.Index(name, ";"); i >= 0 {
+		name, params = name[:i], name[i+1:]
+	}
+	return strings.ToUpper(name), params, strings.TrimSpace(value)
+}
+
+// parseICalDateTime returns the time of a date-time like `20191225T220000Z`, `20191225T220000` or a date like `20191225`.
+func parseICalDateTime(params, value string, location *time.Location) (time.Time, error) {
+	for _, p := range strings.Split(params, ";") {
+		if strings.HasPrefix(strings.ToUpper(p), "TZID=") {
+			tzLocation, err := time.LoadLocation(p[len("TZID="):])
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid time zone '%s': %s", p, err.Error())
+			}
+			location = tzLocation
+		}
+	}
+
+	var (
+		t   time.Time
+		err error
+	)
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse("20060102T150405Z", value)
+	case len(value) == len("20060102"):
+		t, err = time.ParseInLocation("20060102", value, location)
+	default:
+		t, err = time.ParseInLocation("20060102T150405", value, location)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date-time '%s': %s", value, err.Error())
+	}
+	return t, nil
+}
+
+// isICalDate checks whether the value of DTSTART is a date like `20191225` rather than a date-time.
+func isICalDate(params, value string) bool {
+	return strings.Contains(strings.ToUpper(params), "VALUE=DATE") && !strings.Contains(strings.ToUpper(params), "VALUE=DATE-TIME") || len(value) == len("20060102")
+}
@@ -0,0 +1,123 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMaintenanceWindows = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+SUMMARY:Database maintenance
+DTSTART;TZID=Europe/Berlin:20190302T220000
+DTEND;TZID=Europe/Berlin:20190303T020000
+RRULE:FREQ=WEEKLY;COUNT=10
+END:VEVENT
+BEGIN:VEVENT
+SUMMARY:Network maintenance
+DTSTART:20190310T080000Z
+DTEND:20190310T100000Z
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestParseICalEvents(t *testing.T) {
+	events, err := ParseICalEvents([]byte(testMaintenanceWindows), time.UTC)
+	require.NoError(t, err, "there should be no error parsing the iCal")
+	require.Len(t, events, 2, "there should be 2 events")
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err, "loading the time zone must not raise an error")
+
+	start, end, ok := events[0].Next(time.Date(2019, time.March, 2, 12, 0, 0, 0, time.UTC))
+	require.True(t, ok, "the first occurrence should be found")
+	assert.Equal(t, time.Date(2019, time.March, 2, 22, 0, 0, 0, berlin).Unix(), start.Unix(), "the start of the first occurrence should be equal")
+	assert.Equal(t, time.Date(2019, time.March, 3, 2, 0, 0, 0, berlin).Unix(), end.Unix(), "the end of the first occurrence should be equal")
+
+	// The current occurrence is returned until it ends. The occurrence after the change to daylight saving time starts at the same local time.
+	start, _, ok = events[0].Next(time.Date(2019, time.March, 31, 0, 30, 0, 0, berlin))
+	require.True(t, ok, "the current occurrence should be found")
+	assert.Equal(t, time.Date(2019, time.March, 30, 22, 0, 0, 0, berlin).Unix(), start.Unix(), "the current occurrence should be found")
+	start, _, ok = events[0].Next(time.Date(2019, time.March, 31, 3, 0, 0, 0, berlin))
+	require.True(t, ok, "the next occurrence should be found")
+	assert.Equal(t, time.Date(2019, time.April, 6, 22, 0, 0, 0, berlin).Unix(), start.Unix(), "the next occurrence should start at the same local time")
+
+	_, _, ok = events[0].Next(time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok, "there should be no occurrence after the last one")
+
+	start, _, ok = events[1].Next(time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC))
+	require.True(t, ok, "the single event should be found")
+	assert.Equal(t, time.Date(2019, time.March, 10, 8, 0, 0, 0, time.UTC), start.UTC(), "the start of the single event should be equal")
+
+	_, err = ParseICalEvents([]byte("BEGIN:VEVENT\nDTSTART:20190310T080000Z\nDTEND:20190310T100000Z\nRRULE:FREQ=MONTHLY\nEND:VEVENT\n"), time.UTC)
+	assert.Error(t, err, "monthly recurrences are not supported")
+}
+
+func TestParseICalEventsWithDuration(t *testing.T) {
+	events, err := ParseICalEvents([]byte("BEGIN:VEVENT\nDTSTART:20190310T080000Z\nDURATION:P1DT2H30M\nEND:VEVENT\n"), time.UTC)
+	require.NoError(t, err, "there should be no error parsing the iCal")
+	require.Len(t, events, 1, "there should be 1 event")
+	assert.Equal(t, time.Date(2019, time.March, 11, 10, 30, 0, 0, time.UTC), events[0].EndsAt.UTC(), "the end should be the start plus the duration")
+
+	events, err = ParseICalEvents([]byte("BEGIN:VEVENT\nDTSTART:20190310T080000Z\nDURATION:P2W\nEND:VEVENT\n"), time.UTC)
+	require.NoError(t, err, "there should be no error parsing the iCal")
+	assert.Equal(t, time.Date(2019, time.March, 24, 8, 0, 0, 0, time.UTC), events[0].EndsAt.UTC(), "weeks should be supported")
+
+	for _, duration := range []string{"P", "PT", "1H", "-PT1H", "PT0S"} {
+		_, err = ParseICalEvents([]byte("BEGIN:VEVENT\nDTSTART:20190310T080000Z\nDURATION:"+duration+"\nEND:VEVENT\n"), time.UTC)
+		assert.Error(t, err, "the duration %s should be invalid", duration)
+	}
+
+	_, err = ParseICalEvents([]byte("BEGIN:VEVENT\nDTSTART:20190310T080000Z\nDTEND:20190310T100000Z\nDURATION:PT2H\nEND:VEVENT\n"), time.UTC)
+	assert.Error(t, err, "either DTEND or DURATION should be given")
+}
+
+func TestParseICalEventsUnsupportedRecurrences(t *testing.T) {
+	event := "BEGIN:VEVENT\nDTSTART:20190310T080000Z\nDTEND:20190310T100000Z\n%s\nEND:VEVENT\n"
+	testCases := []struct {
+		line    string
+		isValid bool
+	}{
+		{"RRULE:FREQ=WEEKLY;BYDAY=SU;WKST=MO", true},
+		{"RRULE:FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=10", true},
+		{"RRULE:FREQ=WEEKLY;BYDAY=MO,WE", false},
+		{"RRULE:FREQ=DAILY;BYDAY=SU", false},
+		{"RRULE:FREQ=YEARLY;BYMONTHDAY=11", false},
+		{"RRULE:FREQ=WEEKLY;BYSETPOS=1", false},
+		{"RRULE:FREQ=MONTHLY", false},
+		{"EXDATE:20190317T080000Z", false},
+		{"RDATE:20190320T080000Z", false},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseICalEvents([]byte(fmt.Sprintf(event, tc.line)), time.UTC)
+		if tc.isValid {
+			assert.NoError(t, err, "%s should be supported", tc.line)
+		} else {
+			assert.Error(t, err, "%s should not be supported", tc.line)
+		}
+	}
+}
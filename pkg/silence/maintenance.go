@@ -0,0 +1,179 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+)
+
+var (
+	maintenanceNameRegex = regexp.MustCompile(`^[\w.-]+$`)
+
+	// maintenanceMarkerRegex matches the markers appended to the comments of silences created for maintenance windows.
+	maintenanceMarkerRegex = regexp.MustCompile(`\[stargate maintenance: window=([\w.-]+) start=(\d+)\]`)
+)
+
+// MaintenanceWindow is a recurring maintenance during which alerts are silenced.
+// Its occurrences are defined by either a cron schedule and a duration or the events of an iCal file.
+type MaintenanceWindow struct {
+	// Name identifies the window, e.g. `weekly-db-maintenance`.
+	Name string `yaml:"name" json:"name"`
+
+	// Matchers of the silences created for each occurrence.
+	Matchers []Matcher `yaml:"matchers" json:"matchers"`
+
+	// Comment of the silences. Default: `maintenance window <name>`.
+	Comment string `yaml:"comment" json:"comment,omitempty"`
+
+	// Schedule is a cron expression of the start of each occurrence, e.g. `0 22 * * SAT`.
+	Schedule string `yaml:"schedule" json:"schedule,omitempty"`
+
+	// Duration of each occurrence started by the schedule, e.g. `4h`.
+	Duration string `yaml:"duration" json:"duration,omitempty"`
+
+	// TimeZone the schedule and iCal date-times without time zone are in, e.g. `Europe/Berlin`. Default: UTC.
+	TimeZone string `yaml:"time_zone" json:"timeZone,omitempty"`
+
+	// ICal is the content of an iCal file whose events are the occurrences.
+	ICal string `yaml:"ical" json:"ical,omitempty"`
+
+	// ICalFile is the path to an iCal file whose events are the occurrences.
+	ICalFile string `yaml:"ical_file" json:"-"`
+
+	schedule Schedule
+	duration time.Duration
+	location *time.Location
+	events   []Event
+}
+
+// Validate checks the window and parses its schedule or iCal file.
+func (w *MaintenanceWindow) Validate() error {
+	if !maintenanceNameRegex.MatchString(w.Name) {
+		return fmt.Errorf("invalid maintenance window name '%s'. use letters, digits, '_', '.' and '-'", w.Name)
+	}
+	if err := ValidateMatchers(w.SilenceMatchers()); err != nil {
+		return fmt.Errorf("maintenance window '%s': %s", w.Name, err.Error())
+	}
+
+	w.location = time.UTC
+	if w.TimeZone != "" {
+		location, err := time.LoadLocation(w.TimeZone)
+		if err != nil {
+			return fmt.Errorf("maintenance window '%s' has an invalid time zone: %s", w.Name, err.Error())
+		}
+		w.location = location
+	}
+
+	sources := 0
+	for _, source := range []string{w.Schedule, w.ICal, w.ICalFile} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("maintenance window '%s' requires either a schedule, an ical or an ical_file", w.Name)
+	}
+
+	if w.Schedule != "" {
+		schedule, err := ParseSchedule(w.Schedule)
+		if err != nil {
+			return fmt.Errorf("maintenance window '%s': %s", w.Name, err.Error())
+		}
+		duration, err := parseDuration(w.Duration)
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("maintenance window '%s' requires a duration greater than 0 like 4h", w.Name)
+		}
+		w.schedule, w.duration = schedule, duration
+		return nil
+	}
+
+	data := []byte(w.ICal)
+	if w.ICalFile != "" {
+		var err error
+		if data, err = ioutil.ReadFile(w.ICalFile); err != nil {
+			return fmt.Errorf("failed to read iCal file of maintenance window '%s': %s", w.Name, err.Error())
+		}
+	}
+	events, err := ParseICalEvents(data, w.location)
+	if err != nil {
+		return fmt.Errorf("failed to parse iCal of maintenance window '%s': %s", w.Name, err.Error())
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("iCal of maintenance window '%s' has no events", w.Name)
+	}
+	w.events = events
+	return nil
+}
+
+// SilenceMatchers returns the sorted matchers of the silences created for the window.
+func (w *MaintenanceWindow) SilenceMatchers() types.Matchers {
+	return silenceMatchers(w.Matchers)
+}
+
+// Next returns the start and end of the current occurrence or the upcoming one if there is none at the given time.
+// Returns false if there are no more occurrences. The window must be validated first.
+func (w *MaintenanceWindow) Next(now time.Time) (time.Time, time.Time, bool) {
+	if w.Schedule != "" {
+		start := w.schedule.Next(now.Add(-w.duration).In(w.location))
+		return start, start.Add(w.duration), !start.IsZero()
+	}
+
+	var (
+		nextStart time.Time
+		nextEnd   time.Time
+		found     bool
+	)
+	for _, e := range w.events {
+		start, end, ok := e.Next(now)
+		if ok && (!found || start.Before(nextStart)) {
+			nextStart, nextEnd, found = start, end, true
+		}
+	}
+	return nextStart, nextEnd, found
+}
+
+// RenderComment returns the comment of the silence created for the occurrence starting at the given time.
+// The marker at its end identifies the window and occurrence, e.g. '[stargate maintenance: window=db start=1551564000]'.
+func (w *MaintenanceWindow) RenderComment(start time.Time) string {
+	comment := w.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("maintenance window %s", w.Name)
+	}
+	return fmt.Sprintf("%s\n[stargate maintenance: window=%s start=%d]", comment, w.Name, start.Unix())
+}
+
+// ParseMaintenanceMarker returns the name of the window and the start of the occurrence a silence was created for.
+func ParseMaintenanceMarker(comment string) (string, time.Time, bool) {
+	m := maintenanceMarkerRegex.FindStringSubmatch(comment)
+	if m == nil {
+		return "", time.Time{}, false
+	}
+	start, err := strconv.ParseInt(m[2], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return m[1], time.Unix(start, 0), true
+}
@@ -0,0 +1,105 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindowSchedule(t *testing.T) {
+	w := MaintenanceWindow{
+		Name:     "weekly-db-maintenance",
+		Matchers: []Matcher{{Name: "service", Value: "database"}, {Name: "region", Value: "eu-de-.*", IsRegex: true}},
+		Schedule: "0 22 * * SAT",
+		Duration: "4h",
+		TimeZone: "Europe/Berlin",
+	}
+	require.NoError(t, w.Validate(), "validating the window must not raise an error")
+	assert.Equal(t, `{region=~"eu-de-.*",service="database"}`, w.SilenceMatchers().String(), "the matchers should be sorted")
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err, "loading the time zone must not raise an error")
+	saturday := time.Date(2019, time.March, 2, 22, 0, 0, 0, berlin)
+
+	start, end, ok := w.Next(time.Date(2019, time.March, 1, 12, 0, 0, 0, berlin))
+	require.True(t, ok, "the upcoming occurrence should be found")
+	assert.Equal(t, saturday.Unix(), start.Unix(), "the start of the upcoming occurrence should be equal")
+	assert.Equal(t, saturday.Add(4*time.Hour).Unix(), end.Unix(), "the end of the upcoming occurrence should be equal")
+
+	start, _, ok = w.Next(saturday.Add(3 * time.Hour))
+	require.True(t, ok, "the current occurrence should be found")
+	assert.Equal(t, saturday.Unix(), start.Unix(), "the current occurrence should be returned until it ends")
+
+	start, _, ok = w.Next(saturday.Add(4 * time.Hour))
+	require.True(t, ok, "the next occurrence should be found")
+	assert.Equal(t, saturday.AddDate(0, 0, 7).Unix(), start.Unix(), "the next occurrence should be a week later")
+}
+
+func TestMaintenanceWindowICal(t *testing.T) {
+	w := MaintenanceWindow{
+		Name:     "network",
+		Matchers: []Matcher{{Name: "service", Value: "network"}},
+		ICal:     testMaintenanceWindows,
+	}
+	require.NoError(t, w.Validate(), "validating the window must not raise an error")
+
+	start, _, ok := w.Next(time.Date(2019, time.March, 9, 12, 0, 0, 0, time.UTC))
+	require.True(t, ok, "the upcoming occurrence should be found")
+	assert.Equal(t, time.Date(2019, time.March, 9, 21, 0, 0, 0, time.UTC), start.UTC(), "the earliest event should be the upcoming occurrence")
+}
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	tests := map[string]MaintenanceWindow{
+		"name with spaces":      {Name: "db maintenance", Matchers: []Matcher{{Name: "service", Value: "database"}}, Schedule: "0 22 * * SAT", Duration: "4h"},
+		"without matchers":      {Name: "db", Schedule: "0 22 * * SAT", Duration: "4h"},
+		"invalid regex":         {Name: "db", Matchers: []Matcher{{Name: "service", Value: "(", IsRegex: true}}, Schedule: "0 22 * * SAT", Duration: "4h"},
+		"matching all alerts":   {Name: "db", Matchers: []Matcher{{Name: "service", Value: ".*", IsRegex: true}}, Schedule: "0 22 * * SAT", Duration: "4h"},
+		"without duration":      {Name: "db", Matchers: []Matcher{{Name: "service", Value: "database"}}, Schedule: "0 22 * * SAT"},
+		"without schedule":      {Name: "db", Matchers: []Matcher{{Name: "service", Value: "database"}}, Duration: "4h"},
+		"schedule and ical":     {Name: "db", Matchers: []Matcher{{Name: "service", Value: "database"}}, Schedule: "0 22 * * SAT", Duration: "4h", ICal: testMaintenanceWindows},
+		"invalid time zone":     {Name: "db", Matchers: []Matcher{{Name: "service", Value: "database"}}, Schedule: "0 22 * * SAT", Duration: "4h", TimeZone: "Mars/Olympus"},
+		"missing ical file":     {Name: "db", Matchers: []Matcher{{Name: "service", Value: "database"}}, ICalFile: "does-not-exist.ics"},
+		"ical without an event": {Name: "db", Matchers: []Matcher{{Name: "service", Value: "database"}}, ICal: "BEGIN:VCALENDAR\nEND:VCALENDAR\n"},
+	}
+
+	for name, w := range tests {
+		assert.Error(t, w.Validate(), "the window should be invalid: %s", name)
+	}
+}
+
+func TestMaintenanceMarker(t *testing.T) {
+	w := MaintenanceWindow{Name: "weekly-db-maintenance", Comment: "database maintenance"}
+	start := time.Date(2019, time.March, 2, 21, 0, 0, 0, time.UTC)
+
+	comment := w.RenderComment(start)
+	assert.Equal(t, "database maintenance\n[stargate maintenance: window=weekly-db-maintenance start=1551560400]", comment, "the comment should be equal")
+
+	name, parsedStart, ok := ParseMaintenanceMarker(comment)
+	require.True(t, ok, "the marker should be found")
+	assert.Equal(t, "weekly-db-maintenance", name, "the name of the window should be equal")
+	assert.Equal(t, start.Unix(), parsedStart.Unix(), "the start of the occurrence should be equal")
+
+	_, _, ok = ParseMaintenanceMarker("silenced by the stargate\n[stargate: user=U0123]")
+	assert.False(t, ok, "silences created by users should not have a maintenance marker")
+}
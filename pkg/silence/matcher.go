@@ -0,0 +1,70 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+)
+
+// Matcher of a silence.
+type Matcher struct {
+	Name    string `yaml:"name" json:"name"`
+	Value   string `yaml:"value" json:"value"`
+	IsRegex bool   `yaml:"is_regex" json:"isRegex"`
+}
+
+// silenceMatchers returns the sorted Alertmanager matchers.
+func silenceMatchers(matchers []Matcher) types.Matchers {
+	result := make([]*types.Matcher, 0, len(matchers))
+	for _, m := range matchers {
+		result = append(result, &types.Matcher{Name: m.Name, Value: m.Value, IsRegex: m.IsRegex})
+	}
+	return types.NewMatchers(result...)
+}
+
+// ValidateMatchers checks the matchers of a silence before it is created.
+// Regular expressions must be valid and the silence must not match every alert.
+func ValidateMatchers(matchers types.Matchers) error {
+	if len(matchers) == 0 {
+		return errors.New("matchers must not be empty")
+	}
+
+	matchesEverything := true
+	for _, m := range matchers {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("invalid matcher %s: %s", m.String(), err.Error())
+		}
+		if err := m.Init(); err != nil {
+			return fmt.Errorf("invalid matcher %s: %s", m.String(), err.Error())
+		}
+		if !m.Match(model.LabelSet{}) {
+			matchesEverything = false
+		}
+	}
+
+	if matchesEverything {
+		return errors.New("at least one matcher must not match the empty string")
+	}
+	return nil
+}
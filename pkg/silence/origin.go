@@ -0,0 +1,63 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// originRegex matches the markers appended to the comments of silences created via the stargate.
+var originRegex = regexp.MustCompile(`\[stargate: user=(\w+)(?: channel=(\w+))?(?: thread=([0-9.]+))?\]`)
+
+// Origin is the Slack user and thread a silence was requested in.
+type Origin struct {
+	UserID,
+	Channel,
+	ThreadTimestamp string
+}
+
+// Marker returns the marker appended to the comment of a silence, e.g. '[stargate: user=U0123 channel=C0123 thread=1550000000.000100]'.
+func (o Origin) Marker() string {
+	marker := fmt.Sprintf("[stargate: user=%s", o.UserID)
+	if o.Channel != "" {
+		marker += fmt.Sprintf(" channel=%s", o.Channel)
+	}
+	if o.ThreadTimestamp != "" {
+		marker += fmt.Sprintf(" thread=%s", o.ThreadTimestamp)
+	}
+	return marker + "]"
+}
+
+// ParseOrigin returns the origin of the last marker in the comment of a silence.
+// The comments of extended silences contain a marker per request.
+func ParseOrigin(comment string) (Origin, bool) {
+	matches := originRegex.FindAllStringSubmatch(comment, -1)
+	if len(matches) == 0 {
+		return Origin{}, false
+	}
+
+	last := matches[len(matches)-1]
+	return Origin{
+		UserID:          last[1],
+		Channel:         last[2],
+		ThreadTimestamp: last[3],
+	}, true
+}
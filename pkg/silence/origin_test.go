@@ -0,0 +1,48 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrigin(t *testing.T) {
+	testCases := []Origin{
+		{UserID: "U0123"},
+		{UserID: "U0123", Channel: "D0123"},
+		{UserID: "U0123", Channel: "C0123", ThreadTimestamp: "1550000000.000100"},
+	}
+
+	for _, origin := range testCases {
+		parsed, ok := ParseOrigin("silenced by someone\n" + origin.Marker())
+		assert.True(t, ok, "the marker %s should be found", origin.Marker())
+		assert.Equal(t, origin, parsed, "the origin should be equal")
+	}
+
+	comment := "silenced\n" + Origin{UserID: "U0123"}.Marker() + "\nextended by someone else: longer\n" + Origin{UserID: "U0456", Channel: "C0123"}.Marker()
+	parsed, ok := ParseOrigin(comment)
+	assert.True(t, ok, "the marker should be found")
+	assert.Equal(t, Origin{UserID: "U0456", Channel: "C0123"}, parsed, "the last marker should be used")
+
+	_, ok = ParseOrigin("created via the alertmanager")
+	assert.False(t, ok, "comments without marker should not have an origin")
+}
@@ -0,0 +1,140 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// DefaultComment is used if a preset does not define a comment.
+const DefaultComment = "silenced by the stargate"
+
+// Preset is a silence that can be created via a button, a reaction or a dialog.
+type Preset struct {
+	// Action is the value of the button or the action mapped to a reaction, e.g. `silence1Day`.
+	Action string `yaml:"action"`
+
+	// Name is shown on buttons and in dialogs, e.g. `Silence for 1 day`.
+	Name string `yaml:"name"`
+
+	// Duration expression, e.g. `24h`, `until next Monday 09:00` or `until end of business day`.
+	Duration string `yaml:"duration"`
+
+	// Comment template of the silence. The Alertname, Labels, UserName and EndsAt can be used, e.g. `{{ .UserName }} silenced {{ .Alertname }}`.
+	Comment string `yaml:"comment"`
+
+	// Labels of the alert the silence matches. All labels if empty.
+	Labels []string `yaml:"labels"`
+
+	commentTemplate *template.Template
+}
+
+// CommentData is available in the comment template of a preset.
+type CommentData struct {
+	Alertname,
+	UserName string
+	Labels map[string]string
+	EndsAt time.Time
+}
+
+// DefaultPresets are used if no `silence_presets` are configured.
+// They match the buttons of the Alertmanager Slack template.
+func DefaultPresets() []Preset {
+	return []Preset{
+		{Action: "silence1Day", Name: "Silence for 1 day", Duration: "1d"},
+		{Action: "silenceUntilMonday", Name: "Silence until Monday", Duration: "until next Monday"},
+		{Action: "silence1Month", Name: "Silence for 1 month", Duration: "31d"},
+	}
+}
+
+// Validate checks the preset and parses its comment template.
+func (p *Preset) Validate() error {
+	if p.Action == "" {
+		return fmt.Errorf("silence preset '%s' without action", p.Name)
+	}
+	if p.Name == "" {
+		p.Name = p.Action
+	}
+	if err := ValidateExpression(p.Duration); err != nil {
+		return fmt.Errorf("silence preset '%s': %s", p.Action, err.Error())
+	}
+
+	comment := p.Comment
+	if comment == "" {
+		comment = DefaultComment
+	}
+	tmpl, err := template.New(p.Action).Option("missingkey=zero").Parse(comment)
+	if err != nil {
+		return fmt.Errorf("silence preset '%s' has an invalid comment template: %s", p.Action, err.Error())
+	}
+	p.commentTemplate = tmpl
+	return nil
+}
+
+// EndsAt returns the end of a silence created now using the preset.
+// The time of day is interpreted in the location of now.
+func (p *Preset) EndsAt(now time.Time, calendar *Calendar) (time.Time, error) {
+	return EndsAt(p.Duration, now, calendar)
+}
+
+// RenderComment renders the comment template of the preset.
+func (p *Preset) RenderComment(data CommentData) (string, error) {
+	if p.commentTemplate == nil {
+		if err := p.Validate(); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := p.commentTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render comment of silence preset '%s': %s", p.Action, err.Error())
+	}
+	return buf.String(), nil
+}
+
+// FilterLabels returns the labels of the alert the silence matches.
+func (p *Preset) FilterLabels(labels map[string]string) map[string]string {
+	if len(p.Labels) == 0 {
+		return labels
+	}
+
+	filtered := make(map[string]string, len(p.Labels))
+	for k, v := range labels {
+		if util.StringSliceContains(p.Labels, k) {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+// FindPreset returns the preset for the action.
+func FindPreset(presets []Preset, action string) (Preset, bool) {
+	for _, p := range presets {
+		if p.Action == action {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
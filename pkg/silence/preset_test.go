@@ -0,0 +1,69 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreset(t *testing.T) {
+	p := Preset{
+		Action:   "silenceRegion",
+		Duration: "until end of business day",
+		Comment:  "{{ .UserName }} silenced {{ .Alertname }} in {{ .Labels.region }}",
+		Labels:   []string{"alertname", "region"},
+	}
+	require.NoError(t, p.Validate(), "the preset should be valid")
+	assert.Equal(t, "silenceRegion", p.Name, "the name should default to the action")
+
+	labels := map[string]string{"alertname": "KubernetesNodeNotReady", "region": "staging", "node": "node001"}
+	comment, err := p.RenderComment(CommentData{Alertname: "KubernetesNodeNotReady", UserName: "Jane Doe", Labels: labels, EndsAt: time.Now()})
+	require.NoError(t, err, "there should be no error rendering the comment")
+	assert.Equal(t, "Jane Doe silenced KubernetesNodeNotReady in staging", comment, "the comment should be equal")
+
+	assert.Equal(t, map[string]string{"alertname": "KubernetesNodeNotReady", "region": "staging"}, p.FilterLabels(labels), "only the labels of the preset should be used")
+
+	invalid := []Preset{
+		{Duration: "1d"},
+		{Action: "silence", Duration: "sometime"},
+		{Action: "silence", Duration: "1d", Comment: "{{ .UserName "},
+	}
+	for _, p := range invalid {
+		assert.Error(t, p.Validate(), "the preset %v should be invalid", p)
+	}
+}
+
+func TestDefaultPresets(t *testing.T) {
+	for _, p := range DefaultPresets() {
+		require.NoError(t, p.Validate(), "the default preset '%s' should be valid", p.Action)
+		comment, err := p.RenderComment(CommentData{})
+		require.NoError(t, err, "there should be no error rendering the comment")
+		assert.Equal(t, DefaultComment, comment, "the default comment should be used")
+		assert.Equal(t, map[string]string{"region": "staging"}, p.FilterLabels(map[string]string{"region": "staging"}), "all labels should be used")
+	}
+
+	p, ok := FindPreset(DefaultPresets(), "silence1Day")
+	require.True(t, ok, "the preset should be found")
+	assert.Equal(t, "1d", p.Duration, "the duration should be equal")
+}
@@ -0,0 +1,181 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	scheduleMonths   = []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	scheduleWeekdays = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// Schedule is a cron expression with the fields minute, hour, day of month, month and day of week, e.g. `0 22 * * SAT`.
+type Schedule struct {
+	minutes,
+	hours,
+	days,
+	months,
+	weekdays map[int]bool
+
+	// If both day of month and day of week are restricted, a day matching either of them matches.
+	isAnyDay,
+	isAnyWeekday bool
+}
+
+// ParseSchedule parses a cron expression.
+// Fields can be `*`, values, ranges like `1-5`, steps like `*/15` and lists of those. Months and weekdays can be given by name, e.g. `MON-FRI`.
+func ParseSchedule(expression string) (Schedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("invalid schedule '%s'. expected 5 fields: minute hour day-of-month month day-of-week", expression)
+	}
+
+	var (
+		s   Schedule
+		err error
+	)
+	if s.minutes, err = parseScheduleField(fields[0], 0, 59, nil); err != nil {
+		return Schedule{}, fmt.Errorf("invalid minute in schedule '%s': %s", expression, err.Error())
+	}
+	if s.hours, err = parseScheduleField(fields[1], 0, 23, nil); err != nil {
+		return Schedule{}, fmt.Errorf("invalid hour in schedule '%s': %s", expression, err.Error())
+	}
+	if s.days, err = parseScheduleField(fields[2], 1, 31, nil); err != nil {
+		return Schedule{}, fmt.Errorf("invalid day of month in schedule '%s': %s", expression, err.Error())
+	}
+	if s.months, err = parseScheduleField(fields[3], 1, 12, scheduleMonths); err != nil {
+		return Schedule{}, fmt.Errorf("invalid month in schedule '%s': %s", expression, err.Error())
+	}
+	// 7 is Sunday as well.
+	if s.weekdays, err = parseScheduleField(fields[4], 0, 7, scheduleWeekdays); err != nil {
+		return Schedule{}, fmt.Errorf("invalid day of week in schedule '%s': %s", expression, err.Error())
+	}
+	if s.weekdays[7] {
+		s.weekdays[0] = true
+	}
+
+	s.isAnyDay = strings.HasPrefix(fields[2], "*")
+	s.isAnyWeekday = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// Next returns the first time after t matching the schedule in the location of t.
+// Returns the zero time if there is none within the next years, e.g. for `0 0 30 2 *`.
+func (s Schedule) Next(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	// Leap days are at most 8 years apart.
+	for i := 0; i < 8*366; i++ {
+		if s.matchesDay(day) {
+			for hour := 0; hour < 24; hour++ {
+				if !s.hours[hour] {
+					continue
+				}
+				for minute := 0; minute < 60; minute++ {
+					if !s.minutes[minute] {
+						continue
+					}
+					next := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, t.Location())
+					if next.After(t) {
+						return next
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+func (s Schedule) matchesDay(day time.Time) bool {
+	if !s.months[int(day.Month())] {
+		return false
+	}
+
+	isDay, isWeekday := s.days[day.Day()], s.weekdays[int(day.Weekday())]
+	switch {
+	case s.isAnyDay:
+		return isWeekday
+	case s.isAnyWeekday:
+		return isDay
+	default:
+		return isDay || isWeekday
+	}
+}
+
+// parseScheduleField parses a comma-separated list of values, ranges and steps between min and max.
+// Names are matched case-insensitively to their index.
+func parseScheduleField(field string, min, max int, names []string) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step '%s'", part)
+			}
+			part = part[:i]
+		}
+
+		start, end := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if start, err = parseScheduleValue(bounds[0], min, max, names); err != nil {
+				return nil, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = parseScheduleValue(bounds[1], min, max, names); err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				// `5/15` is equivalent to `5-59/15`.
+				end = max
+			}
+			if start > end {
+				return nil, fmt.Errorf("invalid range '%s'", part)
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+func parseScheduleValue(value string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if name != "" && strings.EqualFold(name, value) {
+			return i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid value '%s'. must be between %d and %d", value, min, max)
+	}
+	return v, nil
+}
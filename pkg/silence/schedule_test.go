@@ -0,0 +1,61 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package silence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Friday, 2019-03-01 12:00 UTC.
+	now := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		schedule string
+		expected time.Time
+	}{
+		{"0 22 * * SAT", time.Date(2019, time.March, 2, 22, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, time.March, 1, 12, 15, 0, 0, time.UTC)},
+		{"30 8 * * mon-fri", time.Date(2019, time.March, 4, 8, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2019, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 6 15 * 0", time.Date(2019, time.March, 3, 6, 0, 0, 0, time.UTC)},
+		{"0 6 * * 7", time.Date(2019, time.March, 3, 6, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 MAR *", time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.schedule)
+		require.NoError(t, err, "parsing the schedule '%s' must not raise an error", tt.schedule)
+		assert.Equal(t, tt.expected, s.Next(now), "the next time of schedule '%s' should be equal", tt.schedule)
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, schedule := range []string{"", "0 22 * *", "60 * * * *", "0 24 * * *", "0 0 0 * *", "0 0 * 13 *", "0 0 * * FOO", "5-1 * * * *", "*/0 * * * *"} {
+		_, err := ParseSchedule(schedule)
+		assert.Error(t, err, "the schedule '%s' should be invalid", schedule)
+	}
+}
@@ -0,0 +1,263 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/common/model"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+const (
+	// AlertsPageSize is the number of alerts per page. Slack allows at most 50 blocks per message.
+	AlertsPageSize = 10
+
+	// maxButtonValueLength is the maximum length of the value of a button.
+	maxButtonValueLength = 2000
+)
+
+// severityOrder in which alerts are listed. Other severities are listed last.
+var severityOrder = map[string]int{
+	"critical": 0,
+	"warning":  1,
+	"info":     2,
+}
+
+// severityEmoji is shown next to the severity.
+var severityEmoji = map[string]string{
+	"critical": ":fire:",
+	"warning":  ":warning:",
+	"info":     ":information_source:",
+}
+
+// AlertsPageValue is the value of the pagination buttons.
+type AlertsPageValue struct {
+	// Command listing the alerts, e.g. 'alerts eu-de-1 severity=critical'.
+	Command string `json:"command"`
+	Page    int    `json:"page"`
+}
+
+// AlertListBlocks renders a page of the alerts as Block Kit sections grouped by severity.
+// Every alert has buttons to acknowledge and silence it unless its labels are too long. The command is used to fetch other pages.
+func AlertListBlocks(header, command string, alertList []*client.ExtendedAlert, presets []silence.Preset, page int) []Block {
+	alertList = sortAlertsBySeverity(alertList)
+
+	pageCount := (len(alertList) + AlertsPageSize - 1) / AlertsPageSize
+	if page < 1 {
+		page = 1
+	}
+	if page > pageCount {
+		page = pageCount
+	}
+
+	blocks := []Block{NewSectionBlock(header)}
+	if len(alertList) == 0 {
+		return blocks
+	}
+
+	start := (page - 1) * AlertsPageSize
+	end := start + AlertsPageSize
+	if end > len(alertList) {
+		end = len(alertList)
+	}
+
+	countBySeverity := make(map[string]int)
+	for _, a := range alertList {
+		countBySeverity[string(a.Labels[alertmanager.SeverityLabel])]++
+	}
+
+	severity := ""
+	for i, a := range alertList[start:end] {
+		alertSeverity := string(a.Labels[alertmanager.SeverityLabel])
+		if i == 0 || alertSeverity != severity {
+			severity = alertSeverity
+			blocks = append(blocks,
+				NewDividerBlock(),
+				NewSectionBlock(fmt.Sprintf("%s *%s (%d)*", severityEmoji[severity], strings.Title(orUnknown(severity)), countBySeverity[severity])),
+			)
+		}
+
+		// Acting on a subset of the labels could affect other alerts, so the buttons are left off instead.
+		labelsValue, err := alertButtonValue(a.Labels)
+		if err != nil {
+			blocks = append(blocks,
+				NewSectionBlock(alertText(a)),
+				NewContextBlock("The labels of this alert are too long for buttons. Please use the message of the alert."),
+			)
+			continue
+		}
+		buttons := []*ButtonElement{NewButton("Acknowledge", BlockActionID.AlertReaction+Reaction.Acknowledge, labelsValue, ButtonStyle.Primary)}
+		for _, p := range presets {
+			buttons = append(buttons, NewButton(p.Name, BlockActionID.AlertReaction+p.Action, labelsValue, ButtonStyle.Default))
+		}
+		blocks = append(blocks,
+			NewSectionBlock(alertText(a)),
+			NewActionsBlock("", buttons...),
+		)
+	}
+
+	if pageCount > 1 {
+		buttons := make([]*ButtonElement, 0, 2)
+		if page > 1 {
+			buttons = append(buttons, NewButton("Previous page", BlockActionID.AlertsPage+"previous", alertsPageValue(command, page-1), ButtonStyle.Default))
+		}
+		if page < pageCount {
+			buttons = append(buttons, NewButton("Next page", BlockActionID.AlertsPage+"next", alertsPageValue(command, page+1), ButtonStyle.Default))
+		}
+		blocks = append(blocks,
+			NewDividerBlock(),
+			NewContextBlock(fmt.Sprintf("Page %d of %d. %d alerts in total.", page, pageCount, len(alertList))),
+			NewActionsBlock("", buttons...),
+		)
+	}
+
+	return blocks
+}
+
+// AlertFromBlockAction returns the reaction and the alert of a button in the alert list.
+func AlertFromBlockAction(action BlockAction) (string, *client.ExtendedAlert, error) {
+	if !strings.HasPrefix(action.ActionID, BlockActionID.AlertReaction) {
+		return "", nil, fmt.Errorf("unexpected action '%s'", action.ActionID)
+	}
+
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(action.Value), &labels); err != nil {
+		return "", nil, fmt.Errorf("failed to parse labels of action '%s': %s", action.ActionID, err.Error())
+	}
+
+	labelSet := make(client.LabelSet, len(labels))
+	for k, v := range labels {
+		labelSet[client.LabelName(k)] = client.LabelValue(v)
+	}
+
+	return strings.TrimPrefix(action.ActionID, BlockActionID.AlertReaction), &client.ExtendedAlert{
+		Alert: client.Alert{
+			Labels:      labelSet,
+			Annotations: client.LabelSet{},
+		},
+	}, nil
+}
+
+// AlertsPageFromBlockAction returns the command and page of a pagination button.
+func AlertsPageFromBlockAction(action BlockAction) (AlertsPageValue, error) {
+	var value AlertsPageValue
+	if !strings.HasPrefix(action.ActionID, BlockActionID.AlertsPage) {
+		return value, fmt.Errorf("unexpected action '%s'", action.ActionID)
+	}
+	err := json.Unmarshal([]byte(action.Value), &value)
+	return value, err
+}
+
+func alertText(a *client.ExtendedAlert) string {
+	alertname := string(a.Labels[model.AlertNameLabel])
+	text := fmt.Sprintf("*%s*", alertname)
+	if a.GeneratorURL != "" {
+		text = fmt.Sprintf("*<%s|%s>*", a.GeneratorURL, alertname)
+	}
+	if summary, ok := a.Annotations["summary"]; ok && summary != "" {
+		text += fmt.Sprintf(" - %s", summary)
+	}
+
+	details := []string{
+		fmt.Sprintf("region: %s", orUnknown(string(a.Labels[alertmanager.RegionLabel]))),
+		fmt.Sprintf("firing for %s", util.HumanizedDurationString(time.Now().UTC().Sub(a.StartsAt.UTC()))),
+	}
+	if service, ok := a.Labels["service"]; ok {
+		details = append(details, fmt.Sprintf("service: %s", service))
+	}
+	if acknowledgedBy, ok := a.Annotations[alertmanager.AcknowledgedByLabel]; ok {
+		details = append(details, fmt.Sprintf("acknowledged by %s", acknowledgedBy))
+	}
+	return fmt.Sprintf("%s\n%s", text, strings.Join(details, " · "))
+}
+
+// alertButtonValue encodes the labels of the alert.
+// Fails if the labels exceed the maximum length of a button value.
+func alertButtonValue(labelSet client.LabelSet) (string, error) {
+	labels, err := compactLabels(labelSet)
+	if err != nil {
+		return "", err
+	}
+	value, err := json.Marshal(labels)
+	return string(value), err
+}
+
+// compactLabels returns the labels of the alert.
+// Fails if the labels exceed the maximum length of a button value as acting on fewer labels might affect other alerts.
+func compactLabels(labelSet client.LabelSet) (map[string]string, error) {
+	labels := make(map[string]string, len(labelSet))
+	for k, v := range labelSet {
+		labels[string(k)] = string(v)
+	}
+
+	value, err := json.Marshal(labels)
+	if err != nil {
+		return nil, err
+	}
+	if len(value) > maxButtonValueLength {
+		return nil, fmt.Errorf("the labels of the alert exceed the maximum length of %d characters", maxButtonValueLength)
+	}
+	return labels, nil
+}
+
+func alertsPageValue(command string, page int) string {
+	value, _ := json.Marshal(AlertsPageValue{Command: command, Page: page})
+	return string(value)
+}
+
+func sortAlertsBySeverity(alertList []*client.ExtendedAlert) []*client.ExtendedAlert {
+	sorted := make([]*client.ExtendedAlert, len(alertList))
+	copy(sorted, alertList)
+
+	order := func(a *client.ExtendedAlert) int {
+		if o, ok := severityOrder[string(a.Labels[alertmanager.SeverityLabel])]; ok {
+			return o
+		}
+		return len(severityOrder)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		oi, oj := order(sorted[i]), order(sorted[j])
+		if oi != oj {
+			return oi < oj
+		}
+		si, sj := string(sorted[i].Labels[alertmanager.SeverityLabel]), string(sorted[j].Labels[alertmanager.SeverityLabel])
+		if si != sj {
+			return si < sj
+		}
+		return sorted[i].StartsAt.Before(sorted[j].StartsAt)
+	})
+	return sorted
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAlerts(count int, severity string) []*client.ExtendedAlert {
+	alertList := make([]*client.ExtendedAlert, 0, count)
+	for i := 0; i < count; i++ {
+		alertList = append(alertList, &client.ExtendedAlert{
+			Alert: client.Alert{
+				Labels: client.LabelSet{
+					"alertname": client.LabelValue(fmt.Sprintf("Alert%d", i)),
+					"region":    "staging",
+					"severity":  client.LabelValue(severity),
+				},
+				StartsAt: time.Now().Add(-time.Duration(i) * time.Minute),
+			},
+		})
+	}
+	return alertList
+}
+
+func TestAlertListBlocks(t *testing.T) {
+	alertList := append(newTestAlerts(8, "warning"), newTestAlerts(7, "critical")...)
+
+	blocks := AlertListBlocks("alerts in staging", "alerts staging", alertList, silence.DefaultPresets(), 1)
+	assert.True(t, len(blocks) <= 50, "slack allows at most 50 blocks per message")
+	assert.Equal(t, "alerts in staging", blocks[0].Text.Text, "the first block should be the header")
+	assert.Contains(t, blocks[2].Text.Text, "Critical (7)", "critical alerts should be listed first")
+
+	// The last block contains the pagination buttons.
+	buttons := blocks[len(blocks)-1].Elements
+	require.Len(t, buttons, 1, "the first page should only have a next page button")
+	next := buttons[0].(*ButtonElement)
+	assert.Equal(t, BlockActionID.AlertsPage+"next", next.ActionID, "should be the next page button")
+
+	page, err := AlertsPageFromBlockAction(BlockAction{ActionID: next.ActionID, Value: next.Value})
+	require.NoError(t, err, "there should be no error parsing the page button")
+	assert.Equal(t, AlertsPageValue{Command: "alerts staging", Page: 2}, page, "the page should be equal")
+
+	blocks = AlertListBlocks("alerts in staging", "alerts staging", alertList, silence.DefaultPresets(), 2)
+	buttons = blocks[len(blocks)-1].Elements
+	require.Len(t, buttons, 1, "the last page should only have a previous page button")
+	assert.Equal(t, BlockActionID.AlertsPage+"previous", buttons[0].(*ButtonElement).ActionID, "should be the previous page button")
+
+	blocks = AlertListBlocks("alerts in staging", "alerts staging", alertList[:3], silence.DefaultPresets(), 1)
+	assert.Equal(t, BlockType.Actions, blocks[len(blocks)-1].Type, "the last block should be the buttons of the last alert")
+	assert.Len(t, blocks, 1+2+3*2, "a single page should not be paginated")
+}
+
+func TestAlertFromBlockAction(t *testing.T) {
+	alertList := newTestAlerts(1, "critical")
+	blocks := AlertListBlocks("alerts", "alerts", alertList, silence.DefaultPresets(), 1)
+
+	ack := blocks[len(blocks)-1].Elements[0].(*ButtonElement)
+	reaction, actionAlert, err := AlertFromBlockAction(BlockAction{ActionID: ack.ActionID, Value: ack.Value})
+	require.NoError(t, err, "there should be no error parsing the alert from the button")
+	assert.Equal(t, Reaction.Acknowledge, reaction, "the reaction should be equal")
+	assert.Equal(t, alertList[0].Labels, actionAlert.Labels, "the labels should be equal")
+
+	_, _, err = AlertFromBlockAction(BlockAction{ActionID: BlockActionID.AlertsPage + "next", Value: "{}"})
+	assert.Error(t, err, "should throw an error as the action is not an alert reaction")
+}
+
+func TestAlertListBlocksWithLongLabels(t *testing.T) {
+	alertList := newTestAlerts(1, "critical")
+	alertList[0].Labels["description"] = client.LabelValue(strings.Repeat("x", maxButtonValueLength))
+
+	blocks := AlertListBlocks("alerts", "alerts", alertList, silence.DefaultPresets(), 1)
+	last := blocks[len(blocks)-1]
+	assert.Equal(t, BlockType.Context, last.Type, "the alert should not have buttons")
+
+	_, err := compactLabels(alertList[0].Labels)
+	assert.Error(t, err, "should throw an error instead of dropping labels")
+}
+
+func TestInteractionTypeFromPayload(t *testing.T) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":    InteractionType.BlockActions,
+		"actions": []BlockAction{{ActionID: BlockActionID.AlertReaction + "silence1Day"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, InteractionType.BlockActions, InteractionTypeFromPayload(string(payload)), "should be block actions")
+	blockActions, err := parseBlockActions(string(payload))
+	require.NoError(t, err, "there should be no error parsing the block actions")
+	assert.Equal(t, BlockActionID.AlertReaction+"silence1Day", blockActions.Actions[0].ActionID, "the action ID should be equal")
+}
@@ -0,0 +1,177 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+const (
+	// AppHomeTab is the tab of the App Home showing the alert situation.
+	AppHomeTab = "home"
+
+	// maxAppHomeAlerts is the maximum number of alerts per section. Slack allows at most 100 blocks per view.
+	maxAppHomeAlerts = 15
+
+	// maxAppHomeSilences is the maximum number of silences per section.
+	maxAppHomeSilences = 10
+)
+
+// AppHomeOpenedEvent is sent if a user opens the App Home.
+type AppHomeOpenedEvent struct {
+	Type    string `json:"type"`
+	User    string `json:"user"`
+	Channel string `json:"channel"`
+	Tab     string `json:"tab"`
+}
+
+// ParseAppHomeOpenedEvent parses an app_home_opened event.
+func ParseAppHomeOpenedEvent(event json.RawMessage) (AppHomeOpenedEvent, error) {
+	var e AppHomeOpenedEvent
+	err := json.Unmarshal(event, &e)
+	return e, err
+}
+
+// AppHome is the personalized alert situation shown in the App Home of a user.
+type AppHome struct {
+	UserID string
+
+	// Regions the user cares about. All regions if empty.
+	Regions []string
+
+	// Alerts are the firing critical and warning alerts in the regions.
+	Alerts []*client.ExtendedAlert
+
+	// Acknowledged are the alerts acknowledged by the user.
+	Acknowledged []*client.ExtendedAlert
+
+	// Silences are the active silences created by the user.
+	Silences []*types.Silence
+
+	// ExpiringSilences are the silences in the regions expiring soon.
+	ExpiringSilences []*types.Silence
+
+	// Command used to set the regions, e.g. '/stargate'.
+	Command string
+
+	// LinkToSilence returns the URL of a silence.
+	LinkToSilence func(silenceID string) string
+}
+
+type publishViewRequest struct {
+	UserID string `json:"user_id"`
+	View   view   `json:"view"`
+}
+
+type view struct {
+	Type   string  `json:"type"`
+	Blocks []Block `json:"blocks"`
+}
+
+// PublishAppHome publishes the App Home view of the user.
+func (s *Client) PublishAppHome(home AppHome) error {
+	return s.callWebAPI("views.publish", publishViewRequest{
+		UserID: home.UserID,
+		View: view{
+			Type:   AppHomeTab,
+			Blocks: home.Blocks(),
+		},
+	}, nil)
+}
+
+// Blocks renders the App Home.
+func (h AppHome) Blocks() []Block {
+	regions := "all regions"
+	if len(h.Regions) > 0 {
+		regions = strings.Join(h.Regions, ", ")
+	}
+
+	blocks := []Block{
+		NewSectionBlock(fmt.Sprintf("*Alert situation in %s*", regions)),
+		NewContextBlock(fmt.Sprintf("Updated %s. Choose your regions via `%s regions <region> ...` or `%s regions all`.", time.Now().UTC().Format(time.RFC1123), h.Command, h.Command)),
+		NewDividerBlock(),
+	}
+
+	alerts := sortAlertsBySeverity(h.Alerts)
+	if len(alerts) == 0 {
+		blocks = append(blocks, NewSectionBlock(":green_heart: There are no critical or warning alerts."))
+	} else {
+		blocks = append(blocks, NewSectionBlock(fmt.Sprintf("*Firing critical and warning alerts (%d)*", len(alerts))))
+		blocks = append(blocks, alertSections(alerts, maxAppHomeAlerts)...)
+	}
+
+	blocks = append(blocks, NewDividerBlock(), NewSectionBlock(fmt.Sprintf("*Acknowledged by you (%d)*", len(h.Acknowledged))))
+	blocks = append(blocks, alertSections(sortAlertsBySeverity(h.Acknowledged), maxAppHomeAlerts)...)
+
+	blocks = append(blocks, NewDividerBlock(), NewSectionBlock(fmt.Sprintf("*Silenced by you (%d)*", len(h.Silences))))
+	blocks = append(blocks, h.silenceSections(h.Silences)...)
+
+	blocks = append(blocks, NewDividerBlock(), NewSectionBlock(fmt.Sprintf("*Silences expiring soon (%d)*", len(h.ExpiringSilences))))
+	blocks = append(blocks, h.silenceSections(h.ExpiringSilences)...)
+
+	return blocks
+}
+
+func alertSections(alertList []*client.ExtendedAlert, max int) []Block {
+	blocks := make([]Block, 0, len(alertList))
+	for i, a := range alertList {
+		if i == max {
+			blocks = append(blocks, NewContextBlock(fmt.Sprintf("and %d more.", len(alertList)-max)))
+			break
+		}
+		blocks = append(blocks, NewSectionBlock(fmt.Sprintf("%s %s", severityEmoji[string(a.Labels[alertmanager.SeverityLabel])], alertText(a))))
+	}
+	return blocks
+}
+
+func (h AppHome) silenceSections(silences []*types.Silence) []Block {
+	sorted := make([]*types.Silence, len(silences))
+	copy(sorted, silences)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].EndsAt.Before(sorted[j].EndsAt)
+	})
+
+	blocks := make([]Block, 0, len(sorted))
+	for i, sil := range sorted {
+		if i == maxAppHomeSilences {
+			blocks = append(blocks, NewContextBlock(fmt.Sprintf("and %d more.", len(sorted)-maxAppHomeSilences)))
+			break
+		}
+
+		id := sil.ID
+		if h.LinkToSilence != nil {
+			id = fmt.Sprintf("<%s|%s>", h.LinkToSilence(sil.ID), sil.ID)
+		}
+		blocks = append(blocks, NewSectionBlock(fmt.Sprintf(
+			"%s `%s`\nends in %s · created by %s: %s",
+			id, sil.Matchers.String(), util.HumanizedDurationString(time.Until(sil.EndsAt)), sil.CreatedBy, sil.Comment,
+		)))
+	}
+	return blocks
+}
@@ -0,0 +1,203 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+// Block Kit is not supported by the slack library. Only the parts used by the stargate are implemented.
+
+// BlockType lists the supported Block Kit layout blocks.
+var BlockType = struct {
+	Section,
+	Divider,
+	Actions,
+	Context string
+}{
+	"section",
+	"divider",
+	"actions",
+	"context",
+}
+
+// TextType lists the Block Kit text object types.
+var TextType = struct {
+	PlainText,
+	Markdown string
+}{
+	"plain_text",
+	"mrkdwn",
+}
+
+// ButtonStyle lists the Block Kit button styles.
+var ButtonStyle = struct {
+	Default,
+	Primary,
+	Danger string
+}{
+	"",
+	"primary",
+	"danger",
+}
+
+// Block is a Block Kit layout block.
+type Block struct {
+	Type     string        `json:"type"`
+	BlockID  string        `json:"block_id,omitempty"`
+	Text     *TextObject   `json:"text,omitempty"`
+	Fields   []*TextObject `json:"fields,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+// TextObject is a Block Kit text object.
+type TextObject struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// ButtonElement is a Block Kit button.
+type ButtonElement struct {
+	Type     string      `json:"type"`
+	Text     *TextObject `json:"text"`
+	ActionID string      `json:"action_id"`
+	Value    string      `json:"value,omitempty"`
+	Style    string      `json:"style,omitempty"`
+}
+
+// NewMarkdownText returns a markdown text object.
+func NewMarkdownText(text string) *TextObject {
+	return &TextObject{Type: TextType.Markdown, Text: text}
+}
+
+// NewPlainText returns a plain text object.
+func NewPlainText(text string) *TextObject {
+	return &TextObject{Type: TextType.PlainText, Text: text, Emoji: true}
+}
+
+// NewSectionBlock returns a section with the markdown text.
+func NewSectionBlock(text string) Block {
+	return Block{Type: BlockType.Section, Text: NewMarkdownText(text)}
+}
+
+// NewDividerBlock returns a divider.
+func NewDividerBlock() Block {
+	return Block{Type: BlockType.Divider}
+}
+
+// NewContextBlock returns a context block with the markdown text.
+func NewContextBlock(text string) Block {
+	return Block{Type: BlockType.Context, Elements: []interface{}{NewMarkdownText(text)}}
+}
+
+// NewActionsBlock returns an actions block with the buttons.
+func NewActionsBlock(blockID string, buttons ...*ButtonElement) Block {
+	elements := make([]interface{}, 0, len(buttons))
+	for _, b := range buttons {
+		elements = append(elements, b)
+	}
+	return Block{Type: BlockType.Actions, BlockID: blockID, Elements: elements}
+}
+
+// NewButton returns a button.
+func NewButton(text, actionID, value, style string) *ButtonElement {
+	return &ButtonElement{
+		Type:     "button",
+		Text:     NewPlainText(text),
+		ActionID: actionID,
+		Value:    value,
+		Style:    style,
+	}
+}
+
+type postBlocksRequest struct {
+	Channel         string  `json:"channel"`
+	Text            string  `json:"text"`
+	Blocks          []Block `json:"blocks"`
+	ThreadTimestamp string  `json:"thread_ts,omitempty"`
+	Username        string  `json:"username,omitempty"`
+	IconEmoji       string  `json:"icon_emoji,omitempty"`
+	LinkNames       bool    `json:"link_names"`
+}
+
+type postBlocksResponse struct {
+	Timestamp string `json:"ts"`
+}
+
+// PostBlocks posts a Block Kit message to the channel or the thread if a timestamp is given and returns its timestamp.
+// The text is shown in notifications and by clients not supporting blocks.
+func (s *Client) PostBlocks(channel, text string, blocks []Block, threadTimestamp string) (string, error) {
+	var res postBlocksResponse
+	err := s.callWebAPI("chat.postMessage", postBlocksRequest{
+		Channel:         channel,
+		Text:            text,
+		Blocks:          blocks,
+		ThreadTimestamp: threadTimestamp,
+		Username:        s.config.Slack.UserName,
+		IconEmoji:       s.config.Slack.UserIcon,
+		LinkNames:       true,
+	}, &res)
+	return res.Timestamp, err
+}
+
+type updateBlocksRequest struct {
+	Channel   string  `json:"channel"`
+	Timestamp string  `json:"ts"`
+	Text      string  `json:"text"`
+	Blocks    []Block `json:"blocks"`
+	LinkNames bool    `json:"link_names"`
+}
+
+// UpdateBlocks replaces the text and the blocks of a message posted by the stargate.
+func (s *Client) UpdateBlocks(channel, timestamp, text string, blocks []Block) error {
+	if blocks == nil {
+		blocks = []Block{}
+	}
+	return s.callWebAPI("chat.update", updateBlocksRequest{
+		Channel:   channel,
+		Timestamp: timestamp,
+		Text:      text,
+		Blocks:    blocks,
+		LinkNames: true,
+	}, nil)
+}
+
+type postEphemeralBlocksRequest struct {
+	Channel   string  `json:"channel"`
+	User      string  `json:"user"`
+	Text      string  `json:"text"`
+	Blocks    []Block `json:"blocks"`
+	Username  string  `json:"username,omitempty"`
+	IconEmoji string  `json:"icon_emoji,omitempty"`
+}
+
+// PostEphemeralBlocks posts a Block Kit message only visible to the user.
+// The message is sent as a direct message if no channel is given.
+func (s *Client) PostEphemeralBlocks(channel, userID, text string, blocks []Block) error {
+	if channel == "" {
+		_, err := s.PostBlocks(userID, text, blocks, "")
+		return err
+	}
+	return s.callWebAPI("chat.postEphemeral", postEphemeralBlocksRequest{
+		Channel:   channel,
+		User:      userID,
+		Text:      text,
+		Blocks:    blocks,
+		Username:  s.config.Slack.UserName,
+		IconEmoji: s.config.Slack.UserIcon,
+	}, nil)
+}
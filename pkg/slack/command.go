@@ -24,59 +24,22 @@ import (
 	"net/http"
 
 	"github.com/nlopes/slack"
-	"github.com/sapcc/stargate/pkg/alert"
-	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/pkg/errors"
 )
 
-// HandleSlackCommand responds to slack commands
-func (s *Client) HandleSlackCommand(r *http.Request) {
+// SlashCommandFromRequest parses and verifies a slash command.
+func (s *Client) SlashCommandFromRequest(r *http.Request) (slack.SlashCommand, error) {
 	slashCommand, err := slack.SlashCommandParse(r)
 	if err != nil {
-		s.logger.LogError("failed to parse slash command", err)
+		return slashCommand, errors.Wrap(err, "failed to parse slash command")
 	}
 
 	if !slashCommand.ValidateToken(s.config.Slack.GetValidationToken()) {
-		s.logger.LogInfo("failed to validate token for slash command")
-		return
+		return slashCommand, errors.New("failed to validate token for slash command")
 	}
 
-	if slashCommand.Command == s.config.Slack.Command {
-		action := parseActionFromText(slashCommand.Text)
-		region := parseRegionFromText(slashCommand.Text)
-
-		if region == "" {
-			s.PostMessage(
-				slashCommand.UserID,
-				fmt.Sprintf("missing region. usage: %s %s <region>", slashCommand.Command, slashCommand.Text),
-				"")
-			return
-		}
-
-		switch action {
-		case Action.ShowAlerts:
-			filter := alertmanager.NewDefaultFilter()
-			filter.WithAdditionalFilter(map[string]string{"region": region})
-
-			alertList, err := s.alertmanagerClient.ListAlerts(filter)
-			if err != nil {
-				s.logger.LogError("error listing alerts in region", err, "region", region)
-			}
-
-			alertsBySeverity, err := alert.MapExtendedAlertsBySeverity(alertList)
-			if err != nil {
-				s.logger.LogError("", err)
-				return
-			}
-
-			var msg string
-			if alert.IsNoCriticalOrWarningAlerts(alertsBySeverity) {
-				msg = fmt.Sprintf("Hey <@%s>, Relax! :green_heart:\nThere are no critical or warning alerts in %s.", slashCommand.UserID, region)
-			} else {
-				msg = fmt.Sprintf("Hey <@%s>, region %s shows:\n\n", slashCommand.UserID, region)
-				msg += alert.PrintableAlertDetails(alertsBySeverity)
-			}
-
-			s.PostMessage(slashCommand.ChannelID, msg, "")
-		}
+	if slashCommand.Command != s.config.Slack.Command {
+		return slashCommand, fmt.Errorf("unknown slash command '%s'", slashCommand.Command)
 	}
+	return slashCommand, nil
 }
@@ -0,0 +1,331 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// CommandName lists the commands understood by the stargate via mentions, direct messages and slash commands.
+var CommandName = struct {
+	Alerts,
+	Silences,
+	Silence,
+	Ack,
+	Expire,
+	Page,
+	Note,
+	WhoAmI,
+	Regions,
+	Help string
+}{
+	"alerts",
+	"silences",
+	"silence",
+	"ack",
+	"expire",
+	"page",
+	"note",
+	"whoami",
+	"regions",
+	"help",
+}
+
+// commandUsages in the order shown by the help command.
+var commandUsages = []struct{ name, usage, description string }{
+	{CommandName.Alerts, "alerts [region] [severity=<severity>] [<label>=<value>]", "list firing alerts"},
+	{CommandName.Silences, "silences [region]", "list active silences"},
+	{CommandName.Silence, "silence <label>=<value> [<label>=~<regex>] for <duration> because <reason>", "create a silence, e.g. for 2h, 1d or 1w"},
+	{CommandName.Ack, "ack <alertname> <region>", "acknowledge an alert"},
+	{CommandName.Expire, "expire <silenceID>", "expire a silence"},
+	{CommandName.Page, "page <service> <message>", "page the on-call of a service"},
+	{CommandName.Note, "note <alertname> <region> <note>", "add a note to the incident of an alert"},
+	{CommandName.WhoAmI, "whoami", "show your identities"},
+	{CommandName.Regions, "regions [<region>...|all]", "show or choose the regions shown in your App Home"},
+	{CommandName.Help, "help", "show this help"},
+}
+
+// Command is a parsed command.
+type Command struct {
+	// Name of the command.
+	Name string
+
+	// Region used by alerts, silences, ack and note.
+	Region string
+
+	// Severity used to filter alerts.
+	Severity string
+
+	// Labels used to filter alerts.
+	Labels map[string]string
+
+	// Matchers of a silence.
+	Matchers []Matcher
+
+	// Duration of a silence.
+	Duration time.Duration
+
+	// Reason of a silence.
+	Reason string
+
+	// Alertname used by ack and note.
+	Alertname string
+
+	// SilenceID of the silence to expire.
+	SilenceID string
+
+	// Service to page.
+	Service string
+
+	// Text is the message used to page or the note.
+	Text string
+
+	// Regions shown in the App Home.
+	Regions []string
+}
+
+// Matcher of a silence.
+type Matcher struct {
+	Name    string
+	Value   string
+	IsRegex bool
+}
+
+// UsageError is returned if a command is used incorrectly.
+type UsageError struct {
+	Reason string
+	Usage  string
+}
+
+// Error returns the reason and the correct usage.
+func (e *UsageError) Error() string {
+	if e.Usage == "" {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s. usage: %s", e.Reason, e.Usage)
+}
+
+// Message returns the reason and the correct usage prefixed by the given slash command or mention.
+func (e *UsageError) Message(prefix string) string {
+	if e.Usage == "" {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s. usage: `%s %s`", e.Reason, prefix, e.Usage)
+}
+
+// IsUsageError checks whether the error is a UsageError.
+func IsUsageError(err error) bool {
+	_, ok := err.(*UsageError)
+	return ok
+}
+
+// IsCommand checks whether the text starts with a known command.
+func IsCommand(text string) bool {
+	fields := strings.Fields(strings.ToLower(text))
+	if len(fields) == 0 {
+		return false
+	}
+	if fields[0] == "show" {
+		return len(fields) > 1 && fields[1] == CommandName.Alerts
+	}
+	for _, u := range commandUsages {
+		if fields[0] == u.name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCommand parses a command like 'alerts eu-de-1 severity=critical'.
+// The legacy 'show alerts <region>' is understood as well.
+func ParseCommand(text string) (*Command, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil, &UsageError{Reason: "missing command. try `help`"}
+	}
+
+	name := strings.ToLower(fields[0])
+	args := fields[1:]
+	if name == "show" && len(args) > 0 && strings.ToLower(args[0]) == CommandName.Alerts {
+		name = CommandName.Alerts
+		args = args[1:]
+	}
+
+	cmd := &Command{Name: name}
+	var err error
+	switch name {
+	case CommandName.Alerts:
+		err = cmd.parseAlerts(args)
+	case CommandName.Silences:
+		err = cmd.parseSilences(args)
+	case CommandName.Silence:
+		err = cmd.parseSilence(args)
+	case CommandName.Ack:
+		if len(args) != 2 {
+			return nil, usageError(name, "missing alertname or region")
+		}
+		cmd.Alertname, cmd.Region = args[0], strings.ToLower(args[1])
+	case CommandName.Expire:
+		if len(args) != 1 {
+			return nil, usageError(name, "expected exactly one silence ID")
+		}
+		cmd.SilenceID = args[0]
+	case CommandName.Page:
+		cmd.Service, cmd.Text, err = ParsePageCommand(text)
+		if err != nil {
+			return nil, usageError(name, "missing service or message")
+		}
+	case CommandName.Note:
+		cmd.Alertname, cmd.Region, cmd.Text, err = ParseNoteCommand(text)
+		if err != nil {
+			return nil, usageError(name, "missing alertname, region or note")
+		}
+	case CommandName.Regions:
+		for _, region := range args {
+			cmd.Regions = append(cmd.Regions, strings.ToLower(region))
+		}
+	case CommandName.WhoAmI, CommandName.Help:
+		if len(args) != 0 {
+			return nil, usageError(name, "unexpected arguments")
+		}
+	default:
+		return nil, &UsageError{Reason: fmt.Sprintf("unknown command '%s'. try `help`", fields[0])}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// HelpText lists the commands prefixed by the given slash command or mention.
+func HelpText(prefix string) string {
+	lines := make([]string, 0, len(commandUsages))
+	for _, u := range commandUsages {
+		lines = append(lines, fmt.Sprintf("`%s %s` - %s", prefix, u.usage, u.description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *Command) parseAlerts(args []string) error {
+	c.Labels = make(map[string]string)
+	for _, arg := range args {
+		if !strings.Contains(arg, "=") {
+			if c.Region != "" {
+				return usageError(c.Name, fmt.Sprintf("unexpected argument '%s'", arg))
+			}
+			c.Region = strings.ToLower(arg)
+			continue
+		}
+
+		m, err := parseMatcher(arg)
+		if err != nil || m.IsRegex {
+			return usageError(c.Name, fmt.Sprintf("invalid filter '%s'", arg))
+		}
+		if m.Name == "severity" {
+			c.Severity = strings.ToLower(m.Value)
+			continue
+		}
+		c.Labels[m.Name] = m.Value
+	}
+	return nil
+}
+
+func (c *Command) parseSilences(args []string) error {
+	if len(args) > 1 {
+		return usageError(c.Name, "unexpected arguments")
+	}
+	if len(args) == 1 {
+		c.Region = strings.ToLower(args[0])
+	}
+	return nil
+}
+
+func (c *Command) parseSilence(args []string) error {
+	forIdx, becauseIdx := -1, -1
+	for i, arg := range args {
+		switch strings.ToLower(arg) {
+		case "for":
+			if forIdx == -1 {
+				forIdx = i
+			}
+		case "because":
+			if becauseIdx == -1 && forIdx != -1 {
+				becauseIdx = i
+			}
+		}
+	}
+
+	if forIdx < 1 {
+		return usageError(c.Name, "missing matchers or duration")
+	}
+	if becauseIdx != forIdx+2 || becauseIdx == len(args)-1 {
+		return usageError(c.Name, "missing duration or reason")
+	}
+
+	for _, arg := range args[:forIdx] {
+		m, err := parseMatcher(arg)
+		if err != nil {
+			return usageError(c.Name, err.Error())
+		}
+		c.Matchers = append(c.Matchers, m)
+	}
+
+	duration, err := model.ParseDuration(args[forIdx+1])
+	if err != nil || duration <= 0 {
+		return usageError(c.Name, fmt.Sprintf("invalid duration '%s'", args[forIdx+1]))
+	}
+	c.Duration = time.Duration(duration)
+	c.Reason = strings.Join(args[becauseIdx+1:], " ")
+	return nil
+}
+
+// parseMatcher parses 'name=value' or 'name=~regex'. Quotes around the value are removed.
+func parseMatcher(s string) (Matcher, error) {
+	idx := strings.Index(s, "=")
+	if idx < 1 {
+		return Matcher{}, fmt.Errorf("invalid matcher '%s'", s)
+	}
+
+	m := Matcher{Name: s[:idx], Value: s[idx+1:]}
+	if strings.HasPrefix(m.Value, "~") {
+		m.IsRegex = true
+		m.Value = m.Value[1:]
+	}
+	m.Value = strings.Trim(m.Value, `"'`)
+
+	if m.Value == "" {
+		return Matcher{}, fmt.Errorf("invalid matcher '%s'", s)
+	}
+	return m, nil
+}
+
+func usageError(commandName, reason string) *UsageError {
+	for _, u := range commandUsages {
+		if u.name == commandName {
+			return &UsageError{Reason: reason, Usage: u.usage}
+		}
+	}
+	return &UsageError{Reason: reason}
+}
@@ -0,0 +1,96 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlertsCommand(t *testing.T) {
+	cmd, err := ParseCommand("alerts EU-DE-1 severity=Critical service=nova")
+	require.NoError(t, err, "there should be no error parsing the alerts command")
+	assert.Equal(t, CommandName.Alerts, cmd.Name, "the command should be equal")
+	assert.Equal(t, "eu-de-1", cmd.Region, "the region should be equal")
+	assert.Equal(t, "critical", cmd.Severity, "the severity should be equal")
+	assert.Equal(t, map[string]string{"service": "nova"}, cmd.Labels, "the labels should be equal")
+
+	cmd, err = ParseCommand("show alerts staging")
+	require.NoError(t, err, "there should be no error parsing the legacy show alerts command")
+	assert.Equal(t, CommandName.Alerts, cmd.Name, "the command should be equal")
+	assert.Equal(t, "staging", cmd.Region, "the region should be equal")
+
+	_, err = ParseCommand("alerts eu-de-1 eu-de-2")
+	assert.True(t, IsUsageError(err), "should return a usage error as only one region is allowed")
+}
+
+func TestParseSilenceCommand(t *testing.T) {
+	cmd, err := ParseCommand(`silence alertname=NodeDown instance=~"node00[12]" for 2d because maintenance of the nodes`)
+	require.NoError(t, err, "there should be no error parsing the silence command")
+	assert.Equal(t, []Matcher{
+		{Name: "alertname", Value: "NodeDown"},
+		{Name: "instance", Value: "node00[12]", IsRegex: true},
+	}, cmd.Matchers, "the matchers should be equal")
+	assert.Equal(t, 48*time.Hour, cmd.Duration, "the duration should be equal")
+	assert.Equal(t, "maintenance of the nodes", cmd.Reason, "the reason should be equal")
+
+	invalidCommands := []string{
+		"silence for 2h because maintenance",
+		"silence alertname=NodeDown for 2h",
+		"silence alertname=NodeDown for because maintenance",
+		"silence alertname=NodeDown for 2x because maintenance",
+		"silence alertname= for 2h because maintenance",
+	}
+	for _, text := range invalidCommands {
+		_, err := ParseCommand(text)
+		assert.True(t, IsUsageError(err), "should return a usage error for '%s'", text)
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	cmd, err := ParseCommand("ack KubernetesNodeNotReady EU-DE-1")
+	require.NoError(t, err, "there should be no error parsing the ack command")
+	assert.Equal(t, "KubernetesNodeNotReady", cmd.Alertname, "the alertname should be equal")
+	assert.Equal(t, "eu-de-1", cmd.Region, "the region should be equal")
+
+	cmd, err = ParseCommand("expire 3c4f4a4e-7b8a-4b8c-9a51-1f1b0c4c9f8e")
+	require.NoError(t, err, "there should be no error parsing the expire command")
+	assert.Equal(t, "3c4f4a4e-7b8a-4b8c-9a51-1f1b0c4c9f8e", cmd.SilenceID, "the silence ID should be equal")
+
+	cmd, err = ParseCommand("silences")
+	require.NoError(t, err, "there should be no error parsing the silences command")
+	assert.Empty(t, cmd.Region, "the region should be empty")
+
+	cmd, err = ParseCommand("page compute nova-api is down")
+	require.NoError(t, err, "there should be no error parsing the page command")
+	assert.Equal(t, "compute", cmd.Service, "the service should be equal")
+	assert.Equal(t, "nova-api is down", cmd.Text, "the message should be equal")
+
+	for _, text := range []string{"", "ack KubernetesNodeNotReady", "expire", "help me", "unknown command"} {
+		_, err := ParseCommand(text)
+		assert.True(t, IsUsageError(err), "should return a usage error for '%s'", text)
+	}
+
+	assert.True(t, IsCommand("Show alerts eu-de-1"), "should be a command")
+	assert.False(t, IsCommand("looking into it"), "should not be a command")
+}
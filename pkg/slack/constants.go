@@ -32,6 +32,9 @@ const (
 	// AcknowledgeReactionEmoji is applied to a message after it was successfully acknowledged
 	AcknowledgeReactionEmoji = "male-firefighter"
 
-	// SilenceDefaultComment is the default comment used for a silence
-	SilenceDefaultComment = "silenced by the stargate"
+	// PageReactionEmoji is applied to a message after the on-call was paged
+	PageReactionEmoji = "rotating_light"
+
+	// NoteReactionEmoji is applied to a message after it was added as a note to an incident
+	NoteReactionEmoji = "memo"
 )
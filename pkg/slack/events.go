@@ -0,0 +1,202 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nlopes/slack/slackevents"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EventTypeURLVerification is sent by Slack to verify the events endpoint.
+	EventTypeURLVerification = "url_verification"
+
+	// EventTypeCallback wraps the actual event.
+	EventTypeCallback = "event_callback"
+
+	// ChannelTypeIM is the type of a direct message channel.
+	ChannelTypeIM = "im"
+
+	// maxRequestAge is the maximal age of a signed request.
+	maxRequestAge = 5 * time.Minute
+)
+
+// InnerEventType lists the events of the Events API handled by the stargate.
+var InnerEventType = struct {
+	AppMention,
+	Message,
+	AppHomeOpened,
+	ReactionAdded,
+	SubteamMembersChanged,
+	SubteamUpdated string
+}{
+	"app_mention",
+	"message",
+	"app_home_opened",
+	"reaction_added",
+	"subteam_members_changed",
+	"subteam_updated",
+}
+
+// EventsAPIEvent is the outer event sent via the Events API.
+type EventsAPIEvent struct {
+	Token     string          `json:"token"`
+	Type      string          `json:"type"`
+	Challenge string          `json:"challenge"`
+	TeamID    string          `json:"team_id"`
+	EventID   string          `json:"event_id"`
+	Event     json.RawMessage `json:"event"`
+}
+
+type innerEvent struct {
+	Type string `json:"type"`
+}
+
+// EventHandler handles an inner event of the Events API.
+type EventHandler func(event json.RawMessage)
+
+// SetEventHandler sets the handler for an inner event type of the Events API.
+func (s *Client) SetEventHandler(eventType string, handler EventHandler) {
+	if s.eventHandlers == nil {
+		s.eventHandlers = make(map[string]EventHandler)
+	}
+	s.eventHandlers[eventType] = handler
+}
+
+// EventsAPIEventFromRequest verifies and parses an event sent via the Events API.
+func (s *Client) EventsAPIEventFromRequest(r *http.Request) (*EventsAPIEvent, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read request body")
+	}
+
+	var event EventsAPIEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, errors.Wrap(err, "failed to parse event")
+	}
+
+	if err := s.verifyRequest(r.Header, body, event.Token); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// HandleEventsAPIEvent dispatches an event sent via the Events API or Socket Mode.
+func (s *Client) HandleEventsAPIEvent(event *EventsAPIEvent) {
+	if event.Type != EventTypeCallback {
+		s.logger.LogDebug("ignoring event", "type", event.Type)
+		return
+	}
+
+	var inner innerEvent
+	if err := json.Unmarshal(event.Event, &inner); err != nil {
+		s.logger.LogError("failed to parse inner event", err, "eventID", event.EventID)
+		return
+	}
+
+	switch inner.Type {
+	case InnerEventType.AppMention:
+		var e slackevents.AppMentionEvent
+		if err := json.Unmarshal(event.Event, &e); err != nil {
+			s.logger.LogError("failed to parse app mention event", err, "eventID", event.EventID)
+			return
+		}
+		s.HandleMessage(Message{
+			Channel:         e.Channel,
+			UserID:          e.User,
+			Text:            e.Text,
+			Timestamp:       e.TimeStamp,
+			ThreadTimestamp: e.ThreadTimeStamp,
+		})
+
+	case InnerEventType.Message:
+		var e slackevents.MessageEvent
+		if err := json.Unmarshal(event.Event, &e); err != nil {
+			s.logger.LogError("failed to parse message event", err, "eventID", event.EventID)
+			return
+		}
+		// Only direct messages are handled. Mentions in channels are sent as app_mention.
+		if e.ChannelType != ChannelTypeIM || e.BotID != "" || e.SubType != "" {
+			return
+		}
+		s.HandleMessage(Message{
+			Channel:         e.Channel,
+			UserID:          e.User,
+			Text:            e.Text,
+			Timestamp:       e.TimeStamp,
+			ThreadTimestamp: e.ThreadTimeStamp,
+		})
+
+	default:
+		s.dispatchEvent(inner.Type, event.Event)
+	}
+}
+
+// dispatchEvent passes an event received via the Events API, Socket Mode or RTM to the handler registered for its type.
+func (s *Client) dispatchEvent(eventType string, event json.RawMessage) {
+	handler, ok := s.eventHandlers[eventType]
+	if !ok {
+		s.logger.LogDebug("ignoring event", "type", eventType)
+		return
+	}
+	handler(event)
+}
+
+// verifyRequest verifies the signature of a request if a `signing_secret` is configured.
+// Otherwise the verification token sent with the request is compared.
+func (s *Client) verifyRequest(header http.Header, body []byte, token string) error {
+	signingSecret := s.config.Slack.SigningSecret
+	if signingSecret == "" {
+		if subtle.ConstantTimeCompare([]byte(s.config.Slack.VerificationToken), []byte(token)) != 1 {
+			return errors.New("failed to validate token")
+		}
+		return nil
+	}
+
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "invalid request timestamp")
+	}
+	if math.Abs(time.Since(time.Unix(ts, 0)).Seconds()) > maxRequestAge.Seconds() {
+		return fmt.Errorf("request timestamp '%s' is too old", timestamp)
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte(fmt.Sprintf("v0:%s:%s", timestamp, body)))
+	expectedSignature := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expectedSignature), []byte(header.Get("X-Slack-Signature"))) {
+		return errors.New("failed to verify request signature")
+	}
+	return nil
+}
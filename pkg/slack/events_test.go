@@ -0,0 +1,126 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nlopes/slack"
+	"github.com/sapcc/stargate/pkg/config"
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSigningSecret = "secret"
+
+	eventURLVerification = `{"token":"token","challenge":"3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P","type":"url_verification"}`
+	eventAppMention      = `{"token":"token","team_id":"T0123","type":"event_callback","event_id":"Ev0123","event":{"type":"app_mention","user":"U0123","text":"<@U0BOT> show alerts eu-de-1","ts":"1515449522.000016","channel":"C0123"}}`
+	eventDirectMessage   = `{"token":"token","team_id":"T0123","type":"event_callback","event_id":"Ev0124","event":{"type":"message","user":"U0123","text":"show alerts eu-de-1","ts":"1515449522.000017","channel":"D0123","channel_type":"im"}}`
+	eventChannelMessage  = `{"token":"token","team_id":"T0123","type":"event_callback","event_id":"Ev0125","event":{"type":"message","user":"U0123","text":"hello","ts":"1515449522.000018","channel":"C0123","channel_type":"channel"}}`
+)
+
+func newTestClient() *Client {
+	cfg := config.Config{}
+	cfg.Slack.SigningSecret = testSigningSecret
+	return &Client{config: cfg, logger: log.NewLogger(false)}
+}
+
+func newSignedRequest(body string, timestamp time.Time) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	mac.Write([]byte(fmt.Sprintf("v0:%s:%s", ts, body)))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/slack/events", strings.NewReader(body))
+	r.Header.Set("X-Slack-Request-Timestamp", ts)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+func TestEventsAPIEventFromRequest(t *testing.T) {
+	s := newTestClient()
+
+	event, err := s.EventsAPIEventFromRequest(newSignedRequest(eventURLVerification, time.Now()))
+	require.NoError(t, err, "parsing a signed event must not raise an error")
+	assert.Equal(t, EventTypeURLVerification, event.Type, "the event type should be equal")
+	assert.Equal(t, "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P", event.Challenge, "the challenge should be equal")
+
+	_, err = s.EventsAPIEventFromRequest(newSignedRequest(eventURLVerification, time.Now().Add(-10*time.Minute)))
+	assert.Error(t, err, "should throw an error as the request is too old")
+
+	r := newSignedRequest(eventURLVerification, time.Now())
+	r.Header.Set("X-Slack-Signature", "v0=invalid")
+	_, err = s.EventsAPIEventFromRequest(r)
+	assert.Error(t, err, "should throw an error as the signature is invalid")
+}
+
+func TestHandleEventsAPIEvent(t *testing.T) {
+	s := newTestClient()
+	var messages []Message
+	s.SetMessageHandler(func(msg Message) {
+		messages = append(messages, msg)
+	})
+
+	for _, body := range []string{eventAppMention, eventDirectMessage, eventChannelMessage} {
+		event, err := s.EventsAPIEventFromRequest(newSignedRequest(body, time.Now()))
+		require.NoError(t, err, "parsing a signed event must not raise an error")
+		s.HandleEventsAPIEvent(event)
+	}
+
+	require.Len(t, messages, 2, "the app mention and the direct message should be handled")
+	assert.Equal(t, "C0123", messages[0].Channel, "the channel should be equal")
+	assert.Equal(t, "show alerts eu-de-1", messages[0].TextWithoutMentions(), "the text should be equal")
+	assert.Equal(t, "D0123", messages[1].Channel, "the channel should be equal")
+}
+
+func TestDispatchRTMEvent(t *testing.T) {
+	s := newTestClient()
+	events := make(chan slack.ReactionAddedEvent, 1)
+	s.SetEventHandler(InnerEventType.ReactionAdded, func(event json.RawMessage) {
+		var e slack.ReactionAddedEvent
+		assert.NoError(t, json.Unmarshal(event, &e), "parsing the reaction event must not raise an error")
+		events <- e
+	})
+
+	rtmEvent := &slack.ReactionAddedEvent{Type: InnerEventType.ReactionAdded, User: "U0123", Reaction: "eyes"}
+	rtmEvent.Item.Channel = "C0123"
+	rtmEvent.Item.Timestamp = "1550000000.000100"
+	s.dispatchRTMEvent(InnerEventType.ReactionAdded, rtmEvent)
+
+	select {
+	case e := <-events:
+		assert.Equal(t, "eyes", e.Reaction, "the reaction should be equal")
+		assert.Equal(t, "C0123", e.Item.Channel, "the channel should be equal")
+		assert.Equal(t, "1550000000.000100", e.Item.Timestamp, "the timestamp should be equal")
+	case <-time.After(time.Second):
+		t.Fatal("the reaction event was not dispatched")
+	}
+}
@@ -0,0 +1,122 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+
+	"github.com/nlopes/slack/slackevents"
+	"github.com/pkg/errors"
+)
+
+// InteractionType is the type of an interactive payload.
+var InteractionType = struct {
+	InteractiveMessage,
+	BlockActions,
+	MessageAction,
+	DialogSubmission string
+}{
+	"interactive_message",
+	"block_actions",
+	"message_action",
+	"dialog_submission",
+}
+
+// BlockActionID identifies the buttons of Block Kit messages posted by the stargate.
+// Action IDs must be unique per block, so the ID is a prefix followed by the reaction or page direction.
+var BlockActionID = struct {
+	AlertReaction,
+	AlertsPage,
+	SilencePreview,
+	SilenceApproval,
+	SilenceReminder,
+	StaleSilence string
+}{
+	"alert_reaction.",
+	"alerts_page.",
+	"silence_preview.",
+	"silence_approval.",
+	"silence_reminder.",
+	"stale_silence.",
+}
+
+// BlockActions is sent if a user clicks a button of a Block Kit message.
+type BlockActions struct {
+	Type        string                          `json:"type"`
+	Token       string                          `json:"token"`
+	TriggerID   string                          `json:"trigger_id"`
+	ResponseURL string                          `json:"response_url"`
+	User        slackevents.MessageActionEntity `json:"user"`
+	Channel     slackevents.MessageActionEntity `json:"channel"`
+	Message     struct {
+		Timestamp       string `json:"ts"`
+		ThreadTimestamp string `json:"thread_ts"`
+	} `json:"message"`
+	Actions []BlockAction `json:"actions"`
+}
+
+// BlockAction is a clicked button.
+type BlockAction struct {
+	ActionID string `json:"action_id"`
+	BlockID  string `json:"block_id"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+}
+
+// InteractionTypeFromPayload returns the type of an interactive payload.
+func InteractionTypeFromPayload(payload string) string {
+	var p struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return ""
+	}
+	return p.Type
+}
+
+// BlockActionsFromPayload parses and verifies block actions.
+func (s *Client) BlockActionsFromPayload(payload string) (BlockActions, error) {
+	blockActions, err := parseBlockActions(payload)
+	if err != nil {
+		return blockActions, err
+	}
+
+	verifier := slackevents.TokenComparator{VerificationToken: s.config.Slack.GetValidationToken()}
+	if !verifier.Verify(blockActions.Token) {
+		return BlockActions{}, errors.New("invalid verification token")
+	}
+	return blockActions, nil
+}
+
+// BlockActionsFromSocketModePayload parses block actions received via the authenticated Socket Mode websocket.
+func (s *Client) BlockActionsFromSocketModePayload(payload string) (BlockActions, error) {
+	return parseBlockActions(payload)
+}
+
+func parseBlockActions(payload string) (BlockActions, error) {
+	var blockActions BlockActions
+	if err := json.Unmarshal([]byte(payload), &blockActions); err != nil {
+		return BlockActions{}, errors.Wrap(err, "failed to parse block actions")
+	}
+	if blockActions.Type != InteractionType.BlockActions {
+		return BlockActions{}, errors.Errorf("unexpected interaction type '%s'", blockActions.Type)
+	}
+	return blockActions, nil
+}
@@ -0,0 +1,120 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"github.com/pkg/errors"
+)
+
+// mentionRegex matches user mentions like <@U1234> in a message text.
+var mentionRegex = regexp.MustCompile(`<@[A-Z0-9]+>`)
+
+// Message is a message addressed to the stargate, e.g. by mentioning it.
+type Message struct {
+	Channel,
+	UserID,
+	Text,
+	Timestamp,
+	ThreadTimestamp string
+}
+
+// IsThreadReply checks whether the message was posted in a thread.
+func (m Message) IsThreadReply() bool {
+	return m.ThreadTimestamp != "" && m.ThreadTimestamp != m.Timestamp
+}
+
+// TextWithoutMentions returns the text of the message without user mentions.
+func (m Message) TextWithoutMentions() string {
+	return strings.TrimSpace(mentionRegex.ReplaceAllString(m.Text, ""))
+}
+
+// MessageHandler handles messages addressed to the stargate.
+type MessageHandler func(msg Message)
+
+// SetMessageHandler sets the handler for messages addressed to the stargate.
+func (s *Client) SetMessageHandler(handler MessageHandler) {
+	s.messageHandler = handler
+}
+
+// HandleMessage passes a message to the message handler.
+func (s *Client) HandleMessage(msg Message) {
+	if s.messageHandler == nil {
+		s.logger.LogDebug("ignoring message as no handler is set", "user", msg.UserID, "channel", msg.Channel)
+		return
+	}
+	s.messageHandler(msg)
+}
+
+// isMention checks whether the text mentions the bot user.
+func (s *Client) isMention(text string) bool {
+	s.botUserIDLock.RLock()
+	defer s.botUserIDLock.RUnlock()
+	return s.botUserID != "" && strings.Contains(text, fmt.Sprintf("<@%s>", s.botUserID))
+}
+
+// setBotUserID remembers the bot user to recognize mentions.
+func (s *Client) setBotUserID(botUserID string) {
+	s.botUserIDLock.Lock()
+	defer s.botUserIDLock.Unlock()
+	s.botUserID = botUserID
+}
+
+// IsDirectMessageChannel checks whether the channel is a direct message channel.
+func IsDirectMessageChannel(channel string) bool {
+	return strings.HasPrefix(channel, "D")
+}
+
+// GetMessage returns the message with the timestamp in the channel.
+func (s *Client) GetMessage(channel, timestamp string) (slack.Message, error) {
+	res, err := s.Client.GetConversationHistory(&slack.GetConversationHistoryParameters{
+		ChannelID: channel,
+		Latest:    timestamp,
+		Inclusive: true,
+		Limit:     1,
+	})
+	if err != nil {
+		return slack.Message{}, errors.Wrapf(err, "failed to get message '%s' in channel '%s'", timestamp, channel)
+	}
+	if len(res.Messages) == 0 || res.Messages[0].Timestamp != timestamp {
+		return slack.Message{}, fmt.Errorf("message '%s' not found in channel '%s'", timestamp, channel)
+	}
+	return res.Messages[0], nil
+}
+
+// GetThreadParentMessage returns the message that started a thread.
+func (s *Client) GetThreadParentMessage(channel, threadTimestamp string) (slack.Message, error) {
+	msgs, _, _, err := s.Client.GetConversationReplies(&slack.GetConversationRepliesParameters{
+		ChannelID: channel,
+		Timestamp: threadTimestamp,
+		Limit:     1,
+	})
+	if err != nil {
+		return slack.Message{}, errors.Wrapf(err, "failed to get replies in channel '%s' for thread '%s'", channel, threadTimestamp)
+	}
+	if len(msgs) == 0 {
+		return slack.Message{}, fmt.Errorf("thread '%s' not found in channel '%s'", threadTimestamp, channel)
+	}
+	return msgs[0], nil
+}
@@ -20,6 +20,7 @@
 package slack
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -70,16 +71,20 @@ func parseAlertFromSlackMessageText(text string) (map[string]string, error) {
 	return matchMap, nil
 }
 
-func parseRegionFromText(text string) string {
-	r := regexp.MustCompile(RegionRegex)
-	return r.FindString(strings.ToLower(text))
+// ParsePageCommand parses the service and message from a text like 'page <service> <message>'.
+func ParsePageCommand(text string) (service, message string, err error) {
+	fields := strings.Fields(text)
+	if len(fields) < 3 || strings.ToLower(fields[0]) != "page" {
+		return "", "", errors.New("usage: page <service> <message>")
+	}
+	return fields[1], strings.Join(fields[2:], " "), nil
 }
 
-func parseActionFromText(text string) string {
-	for cmd, keywords := range commandActions {
-		if textContainsAllKeyWords(text, keywords) {
-			return cmd
-		}
+// ParseNoteCommand parses the alertname, region and note from a text like 'note <alertname> <region> <note>'.
+func ParseNoteCommand(text string) (alertname, region, note string, err error) {
+	fields := strings.Fields(text)
+	if len(fields) < 4 || strings.ToLower(fields[0]) != "note" {
+		return "", "", "", errors.New("usage: note <alertname> <region> <note>")
 	}
-	return ""
+	return fields[1], strings.ToLower(fields[2]), strings.Join(fields[3:], " "), nil
 }
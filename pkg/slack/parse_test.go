@@ -90,3 +90,24 @@ func TestParseSlackMessageTextResolvedAlert(t *testing.T) {
 		"should throw an error as resolved messages are ignored",
 	)
 }
+
+func TestParsePageCommand(t *testing.T) {
+	service, message, err := ParsePageCommand("page compute  nova-api is down in eu-de-1")
+	assert.NoError(t, err, "there should be no error parsing the page command")
+	assert.Equal(t, "compute", service, "the service should be equal")
+	assert.Equal(t, "nova-api is down in eu-de-1", message, "the message should be equal")
+
+	_, _, err = ParsePageCommand("page compute")
+	assert.Error(t, err, "should throw an error as the message is missing")
+}
+
+func TestParseNoteCommand(t *testing.T) {
+	alertname, region, note, err := ParseNoteCommand("note KubernetesNodeNotReady EU-DE-1 looking into it")
+	assert.NoError(t, err, "there should be no error parsing the note command")
+	assert.Equal(t, "KubernetesNodeNotReady", alertname, "the alertname should be equal")
+	assert.Equal(t, "eu-de-1", region, "the region should be equal")
+	assert.Equal(t, "looking into it", note, "the note should be equal")
+
+	_, _, _, err = ParseNoteCommand("note KubernetesNodeNotReady eu-de-1")
+	assert.Error(t, err, "should throw an error as the note is missing")
+}
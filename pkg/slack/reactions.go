@@ -20,14 +20,19 @@
 package slack
 
 // Reaction must match the slack action.Value
+// Silences are created via the configurable `silence_presets` instead.
 var Reaction = struct {
 	Acknowledge,
-	SilenceUntilMonday,
-	Silence1Month,
-	Silence1Day string
+	Page string
 }{
 	"acknowledge",
-	"silenceUntilMonday",
-	"silence1Month",
-	"silence1Day",
+	"page",
+}
+
+// Reactions returns all values of the Reaction struct.
+func Reactions() []string {
+	return []string{
+		Reaction.Acknowledge,
+		Reaction.Page,
+	}
 }
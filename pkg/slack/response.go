@@ -0,0 +1,160 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// ResponseTypeEphemeral is used for responses only visible to the acting user.
+const ResponseTypeEphemeral = "ephemeral"
+
+// Responder reports the outcome of an action to the acting user.
+// The correlation ID is part of every failure message and every log line written via the Logger.
+type Responder struct {
+	client *Client
+
+	Channel,
+	UserID,
+	ResponseURL,
+	CorrelationID string
+
+	Logger log.Logger
+}
+
+type responseMessage struct {
+	ResponseType    string  `json:"response_type"`
+	ReplaceOriginal bool    `json:"replace_original"`
+	Text            string  `json:"text"`
+	Blocks          []Block `json:"blocks,omitempty"`
+}
+
+// NewResponder returns a new Responder for the user.
+// The response URL is preferred. The message is posted as an ephemeral message to the channel if none is given.
+func (s *Client) NewResponder(channel, userID, responseURL string) *Responder {
+	correlationID := util.NewCorrelationID()
+	return &Responder{
+		client:        s,
+		Channel:       channel,
+		UserID:        userID,
+		ResponseURL:   responseURL,
+		CorrelationID: correlationID,
+		Logger:        log.NewLoggerWith(s.logger, "correlationID", correlationID),
+	}
+}
+
+// Respond sends the message to the acting user.
+func (r *Responder) Respond(message string) {
+	if r.ResponseURL != "" {
+		err := r.respondViaResponseURL(message)
+		if err == nil {
+			return
+		}
+		r.Logger.LogError("failed to respond via response_url. posting ephemeral message", err)
+	}
+	r.client.PostEphemeral(r.Channel, r.UserID, message)
+}
+
+// Respondf formats and sends the message to the acting user.
+func (r *Responder) Respondf(format string, a ...interface{}) {
+	r.Respond(fmt.Sprintf(format, a...))
+}
+
+// Fail logs the error and tells the acting user what went wrong.
+func (r *Responder) Fail(reason string, err error, keyvals ...interface{}) {
+	r.Logger.LogError(reason, err, keyvals...)
+
+	msg := fmt.Sprintf("Sorry, %s", reason)
+	if err != nil {
+		msg += fmt.Sprintf(": %s", err.Error())
+	}
+	r.Respond(fmt.Sprintf("%s. (correlation ID: %s)", msg, r.CorrelationID))
+}
+
+// Deny tells the acting user why the action was denied.
+func (r *Responder) Deny(reason string) {
+	r.Logger.LogInfo("user is not authorized", "userID", r.UserID, "reason", reason)
+	r.Respond(fmt.Sprintf("Sorry, you are not allowed to do that: %s. (correlation ID: %s)", reason, r.CorrelationID))
+}
+
+// RespondBlocks sends the Block Kit message to the acting user.
+func (r *Responder) RespondBlocks(text string, blocks []Block) {
+	if r.ResponseURL != "" {
+		err := r.postToResponseURL(responseMessage{
+			ResponseType: ResponseTypeEphemeral,
+			Text:         text,
+			Blocks:       blocks,
+		})
+		if err == nil {
+			return
+		}
+		r.Logger.LogError("failed to respond via response_url. posting ephemeral message", err)
+	}
+	if err := r.client.PostEphemeralBlocks(r.Channel, r.UserID, text, blocks); err != nil {
+		r.Logger.LogError("failed to post ephemeral message", err)
+	}
+}
+
+// ReplaceOriginal replaces the message the user interacted with, e.g. to show another page.
+func (r *Responder) ReplaceOriginal(text string, blocks []Block) error {
+	if r.ResponseURL == "" {
+		return errors.New("missing response_url")
+	}
+	return r.postToResponseURL(responseMessage{
+		ReplaceOriginal: true,
+		Text:            text,
+		Blocks:          blocks,
+	})
+}
+
+func (r *Responder) respondViaResponseURL(message string) error {
+	return r.postToResponseURL(responseMessage{
+		ResponseType:    ResponseTypeEphemeral,
+		ReplaceOriginal: false,
+		Text:            message,
+	})
+}
+
+func (r *Responder) postToResponseURL(msg responseMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	res, err := httpClient.Post(r.ResponseURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	return nil
+}
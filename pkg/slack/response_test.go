@@ -0,0 +1,49 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponderFail(t *testing.T) {
+	var received responseMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received), "decoding the response message should not raise an error")
+	}))
+	defer server.Close()
+
+	s := &Client{logger: log.NewLogger(false)}
+	responder := s.NewResponder("C0123", "U0123", server.URL)
+	require.NotEmpty(t, responder.CorrelationID, "the correlation id must not be empty")
+
+	responder.Fail("failed to create silence", nil)
+	assert.Equal(t, ResponseTypeEphemeral, received.ResponseType, "the response should be ephemeral")
+	assert.False(t, received.ReplaceOriginal, "the original message should not be replaced")
+	assert.Contains(t, received.Text, "failed to create silence", "the response should contain the reason")
+	assert.Contains(t, received.Text, responder.CorrelationID, "the response should contain the correlation id")
+}
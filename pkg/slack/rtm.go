@@ -1,12 +1,9 @@
 package slack
 
 import (
-	"fmt"
+	"encoding/json"
 
 	"github.com/nlopes/slack"
-	"github.com/nlopes/slack/slackevents"
-	"github.com/sapcc/stargate/pkg/alert"
-	"github.com/sapcc/stargate/pkg/alertmanager"
 	"github.com/sapcc/stargate/pkg/config"
 )
 
@@ -14,6 +11,9 @@ import (
 func NewSlackRTM(config config.Config, opts config.Options) *slack.RTM {
 	client := slack.New(config.Slack.BotUserAccessToken)
 	client.SetDebug(opts.IsDebug)
+
+	// The slack library knows the event type but does not map it to the corresponding struct.
+	slack.EventMapping[InnerEventType.SubteamMembersChanged] = slack.SubteamMembersChangedEvent{}
 	return client.NewRTM()
 }
 
@@ -29,47 +29,46 @@ func (s *Client) HandleRTMEvent() {
 	for msg := range s.slackRTMClient.IncomingEvents {
 		switch event := msg.Data.(type) {
 
-		// respond if the app was mentioned
-		case *slackevents.AppMentionEvent:
-			s.logger.LogDebug("app was mentioned. responding")
-
-			region := parseRegionFromText(event.Text)
-			action := parseActionFromText(event.Text)
-
-			switch action {
-			case Action.ShowAlerts:
-				filter := alertmanager.NewDefaultFilter()
-				filter.WithAdditionalFilter(map[string]string{"region": region})
-
-				alertList, err := s.alertmanagerClient.ListAlerts(filter)
-				if err != nil {
-					s.logger.LogError("error listing alerts", err, "region", region)
-				}
-
-				alertsBySeverity, err := alert.MapExtendedAlertsBySeverity(alertList)
-				if err != nil {
-					s.logger.LogError("error mapping alerts by severity", err)
-					return
-				}
-
-				var msg string
-				if alert.IsNoCriticalOrWarningAlerts(alertsBySeverity) {
-					msg = fmt.Sprintf("Hey <@%s>, Relax! :green_heart:\nThere are no critical or warning alerts in %s.", event.User, region)
-				} else {
-					msg = fmt.Sprintf("Hey <@%s>, region %s shows:\n\n", event.User, region)
-					msg += alert.PrintableAlertDetails(alertsBySeverity)
-				}
-
-				s.PostMessage(event.Channel, msg, "")
+		// remember the bot user to recognize mentions
+		case *slack.ConnectedEvent:
+			if event.Info != nil && event.Info.User != nil {
+				s.setBotUserID(event.Info.User.ID)
+				s.logger.LogDebug("connected to slack RTM", "botUserID", event.Info.User.ID)
 			}
 
-			s.logger.LogDebug("responding to action", "user", event.User, "channel", event.Channel, "text", event.Text)
+		// respond if the app was mentioned or in direct messages
+		case *slack.MessageEvent:
+			if event.BotID != "" || event.SubType != "" || !(s.isMention(event.Text) || IsDirectMessageChannel(event.Channel)) {
+				continue
+			}
+			s.logger.LogDebug("app was mentioned. responding", "user", event.User, "channel", event.Channel, "text", event.Text)
 
-		case *slackevents.MessageEvent:
-			s.logger.LogDebug("received message event")
+			go s.HandleMessage(Message{
+				Channel:         event.Channel,
+				UserID:          event.User,
+				Text:            event.Text,
+				Timestamp:       event.Timestamp,
+				ThreadTimestamp: event.ThreadTimestamp,
+			})
 
-		case *slackevents.MessageAction:
-			s.logger.LogDebug("received message action")
+		// events handled like the ones received via the Events API
+		case *slack.ReactionAddedEvent:
+			s.dispatchRTMEvent(InnerEventType.ReactionAdded, event)
+		case *slack.SubteamMembersChangedEvent:
+			s.dispatchRTMEvent(InnerEventType.SubteamMembersChanged, event)
+		case *slack.SubteamUpdatedEvent:
+			s.dispatchRTMEvent(InnerEventType.SubteamUpdated, event)
 		}
 	}
 }
+
+// dispatchRTMEvent passes an RTM event to the handler registered for the corresponding event of the Events API.
+// Both share the same JSON representation.
+func (s *Client) dispatchRTMEvent(eventType string, event interface{}) {
+	raw, err := json.Marshal(event)
+	if err != nil {
+		s.logger.LogError("failed to marshal RTM event", err, "type", eventType)
+		return
+	}
+	go s.dispatchEvent(eventType, raw)
+}
@@ -0,0 +1,152 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+
+	"github.com/nlopes/slack"
+	"github.com/nlopes/slack/slackevents"
+	"github.com/pkg/errors"
+	"github.com/prometheus/alertmanager/client"
+)
+
+// DialogElementName is the name of the select element of the alert dialog.
+const DialogElementName = "action"
+
+// DialogSubmission is sent if a user submits a dialog.
+type DialogSubmission struct {
+	Type        string                          `json:"type"`
+	Token       string                          `json:"token"`
+	CallbackID  string                          `json:"callback_id"`
+	ResponseURL string                          `json:"response_url"`
+	State       string                          `json:"state"`
+	User        slackevents.MessageActionEntity `json:"user"`
+	Channel     slackevents.MessageActionEntity `json:"channel"`
+	Submission  map[string]string               `json:"submission"`
+}
+
+// alertDialog is a dialog carrying the alert as state as the slack library does not support the state.
+type alertDialog struct {
+	slack.Dialog
+	State string `json:"state"`
+}
+
+// alertDialogState identifies the alert and the message the shortcut was used on.
+type alertDialogState struct {
+	MessageTimestamp string            `json:"ts"`
+	Labels           map[string]string `json:"labels"`
+}
+
+// OpenAlertDialog opens a dialog to acknowledge or silence the alert of the given message.
+func (s *Client) OpenAlertDialog(triggerID, callbackID, messageTimestamp string, alert *client.ExtendedAlert) error {
+	labels, err := compactLabels(alert.Labels)
+	if err != nil {
+		return err
+	}
+
+	state, err := json.Marshal(alertDialogState{
+		MessageTimestamp: messageTimestamp,
+		Labels:           labels,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal dialog state")
+	}
+
+	// Acknowledge or silence using one of the presets.
+	options := []slack.DialogSelectOption{{Label: "Acknowledge", Value: Reaction.Acknowledge}}
+	for _, p := range s.config.SilencePresets {
+		options = append(options, slack.DialogSelectOption{Label: p.Name, Value: p.Action})
+	}
+
+	actionSelect := slack.NewStaticSelectDialogInput(DialogElementName, "Action", options)
+	actionSelect.Optional = false
+	if len(s.config.SilencePresets) > 0 {
+		actionSelect.Value = s.config.SilencePresets[0].Action
+	}
+
+	return s.callWebAPI("dialog.open", map[string]interface{}{
+		"trigger_id": triggerID,
+		"dialog": alertDialog{
+			Dialog: slack.Dialog{
+				CallbackID:  callbackID,
+				Title:       "Silence with Stargate",
+				SubmitLabel: "Submit",
+				Elements:    []slack.DialogElement{actionSelect},
+			},
+			State: string(state),
+		},
+	}, nil)
+}
+
+// DialogSubmissionFromPayload parses and verifies a dialog submission.
+func (s *Client) DialogSubmissionFromPayload(payload string) (DialogSubmission, error) {
+	submission, err := parseDialogSubmission(payload)
+	if err != nil {
+		return submission, err
+	}
+
+	verifier := slackevents.TokenComparator{VerificationToken: s.config.Slack.GetValidationToken()}
+	if !verifier.Verify(submission.Token) {
+		return DialogSubmission{}, errors.New("invalid verification token")
+	}
+	return submission, nil
+}
+
+// DialogSubmissionFromSocketModePayload parses a dialog submission received via the authenticated Socket Mode websocket.
+func (s *Client) DialogSubmissionFromSocketModePayload(payload string) (DialogSubmission, error) {
+	return parseDialogSubmission(payload)
+}
+
+// AlertFromDialogSubmission returns the selected action, the timestamp of the message and the alert of a submitted alert dialog.
+func AlertFromDialogSubmission(submission DialogSubmission) (string, string, *client.ExtendedAlert, error) {
+	action, ok := submission.Submission[DialogElementName]
+	if !ok || action == "" {
+		return "", "", nil, errors.New("no action selected")
+	}
+
+	var state alertDialogState
+	if err := json.Unmarshal([]byte(submission.State), &state); err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to parse dialog state")
+	}
+
+	labelSet := make(client.LabelSet, len(state.Labels))
+	for k, v := range state.Labels {
+		labelSet[client.LabelName(k)] = client.LabelValue(v)
+	}
+
+	return action, state.MessageTimestamp, &client.ExtendedAlert{
+		Alert: client.Alert{
+			Labels:      labelSet,
+			Annotations: client.LabelSet{},
+		},
+	}, nil
+}
+
+func parseDialogSubmission(payload string) (DialogSubmission, error) {
+	var submission DialogSubmission
+	if err := json.Unmarshal([]byte(payload), &submission); err != nil {
+		return DialogSubmission{}, errors.Wrap(err, "failed to parse dialog submission")
+	}
+	if submission.Type != InteractionType.DialogSubmission {
+		return DialogSubmission{}, errors.Errorf("unexpected interaction type '%s'", submission.Type)
+	}
+	return submission, nil
+}
@@ -0,0 +1,64 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertFromDialogSubmission(t *testing.T) {
+	alert := newTestAlerts(1, "critical")[0]
+	labels, err := compactLabels(alert.Labels)
+	require.NoError(t, err, "there should be no error compacting the labels")
+	state, err := json.Marshal(alertDialogState{MessageTimestamp: "1550000000.000100", Labels: labels})
+	require.NoError(t, err, "there should be no error marshalling the dialog state")
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":        InteractionType.DialogSubmission,
+		"token":       "token",
+		"callback_id": "silence_with_stargate",
+		"state":       string(state),
+		"user":        map[string]string{"id": "U0123"},
+		"channel":     map[string]string{"id": "C0123"},
+		"submission":  map[string]string{DialogElementName: "silence1Day"},
+	})
+	require.NoError(t, err, "there should be no error marshalling the payload")
+
+	submission, err := parseDialogSubmission(string(payload))
+	require.NoError(t, err, "there should be no error parsing the dialog submission")
+	assert.Equal(t, "C0123", submission.Channel.Id, "the channel should be equal")
+
+	action, messageTimestamp, slackAlert, err := AlertFromDialogSubmission(submission)
+	require.NoError(t, err, "there should be no error parsing the alert")
+	assert.Equal(t, "silence1Day", action, "the action should be equal")
+	assert.Equal(t, "1550000000.000100", messageTimestamp, "the message timestamp should be equal")
+	assert.Equal(t, alert.Labels, slackAlert.Labels, "the labels should be equal")
+
+	submission.Submission = map[string]string{}
+	_, _, _, err = AlertFromDialogSubmission(submission)
+	assert.Error(t, err, "should throw an error as no action was selected")
+
+	_, err = parseDialogSubmission(`{"type":"block_actions"}`)
+	assert.Error(t, err, "should throw an error as the interaction type is unexpected")
+}
@@ -0,0 +1,150 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SilencePreviewAction lists the buttons of a silence preview.
+var SilencePreviewAction = struct {
+	Confirm,
+	Cancel string
+}{
+	"confirm",
+	"cancel",
+}
+
+// SilenceApprovalAction lists the buttons of a silence approval request.
+var SilenceApprovalAction = struct {
+	Approve,
+	Reject string
+}{
+	"approve",
+	"reject",
+}
+
+// SilenceReminderAction lists the buttons of a reminder of an expiring silence.
+var SilenceReminderAction = struct {
+	Extend,
+	LetExpire string
+}{
+	"extend",
+	"let_expire",
+}
+
+// StaleSilenceAction lists the buttons of a report of a stale silence.
+var StaleSilenceAction = struct {
+	Expire,
+	Keep string
+}{
+	"expire",
+	"keep",
+}
+
+// SilenceButtons are buttons acting on the silence or pending silence identified by their value.
+type SilenceButtons struct {
+	// ActionIDPrefix identifies the message the buttons belong to. The action is appended to it.
+	ActionIDPrefix string
+	Buttons        []SilenceButton
+}
+
+// SilenceButton is one of the SilenceButtons.
+type SilenceButton struct {
+	Text,
+	Action,
+	Style string
+}
+
+var (
+	// SilencePreviewButtons confirm or cancel a pending silence after its preview.
+	SilencePreviewButtons = SilenceButtons{BlockActionID.SilencePreview, []SilenceButton{
+		{"Confirm", SilencePreviewAction.Confirm, ButtonStyle.Primary},
+		{"Cancel", SilencePreviewAction.Cancel, ButtonStyle.Danger},
+	}}
+
+	// SilenceApprovalButtons approve or reject a pending silence.
+	SilenceApprovalButtons = SilenceButtons{BlockActionID.SilenceApproval, []SilenceButton{
+		{"Approve", SilenceApprovalAction.Approve, ButtonStyle.Primary},
+		{"Reject", SilenceApprovalAction.Reject, ButtonStyle.Danger},
+	}}
+
+	// SilenceReminderButtons extend an expiring silence or let it expire.
+	SilenceReminderButtons = SilenceButtons{BlockActionID.SilenceReminder, []SilenceButton{
+		{"Extend", SilenceReminderAction.Extend, ButtonStyle.Primary},
+		{"Let expire", SilenceReminderAction.LetExpire, ButtonStyle.Default},
+	}}
+
+	// StaleSilenceButtons expire or keep a stale silence.
+	StaleSilenceButtons = SilenceButtons{BlockActionID.StaleSilence, []SilenceButton{
+		{"Expire", StaleSilenceAction.Expire, ButtonStyle.Danger},
+		{"Keep", StaleSilenceAction.Keep, ButtonStyle.Default},
+	}}
+)
+
+// WithText returns a copy of the buttons with another text for the button of the action.
+func (b SilenceButtons) WithText(action, text string) SilenceButtons {
+	buttons := make([]SilenceButton, len(b.Buttons))
+	copy(buttons, b.Buttons)
+	for i := range buttons {
+		if buttons[i].Action == action {
+			buttons[i].Text = text
+		}
+	}
+	return SilenceButtons{ActionIDPrefix: b.ActionIDPrefix, Buttons: buttons}
+}
+
+// Blocks renders the text followed by the buttons for the silence or pending silence with the ID.
+func (b SilenceButtons) Blocks(text, id string) []Block {
+	return []Block{NewSectionBlock(text), b.ActionsBlock(id)}
+}
+
+// ActionsBlock renders the buttons for the silence or pending silence with the ID.
+func (b SilenceButtons) ActionsBlock(id string) Block {
+	elements := make([]*ButtonElement, 0, len(b.Buttons))
+	for _, button := range b.Buttons {
+		elements = append(elements, NewButton(button.Text, b.ActionIDPrefix+button.Action, id, button.Style))
+	}
+	return NewActionsBlock("", elements...)
+}
+
+// FromBlockAction returns the action and the ID of the silence or pending silence of one of the buttons.
+func (b SilenceButtons) FromBlockAction(action BlockAction) (string, string, error) {
+	if !strings.HasPrefix(action.ActionID, b.ActionIDPrefix) {
+		return "", "", fmt.Errorf("unexpected action '%s'", action.ActionID)
+	}
+
+	silenceAction := strings.TrimPrefix(action.ActionID, b.ActionIDPrefix)
+	isKnown := false
+	for _, button := range b.Buttons {
+		if button.Action == silenceAction {
+			isKnown = true
+			break
+		}
+	}
+	if !isKnown {
+		return "", "", fmt.Errorf("unexpected action '%s'", action.ActionID)
+	}
+	if action.Value == "" {
+		return "", "", fmt.Errorf("missing silence of action '%s'", action.ActionID)
+	}
+	return silenceAction, action.Value, nil
+}
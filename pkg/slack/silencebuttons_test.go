@@ -0,0 +1,71 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilenceButtons(t *testing.T) {
+	testCases := []struct {
+		name    string
+		buttons SilenceButtons
+		actions []string
+	}{
+		{"preview", SilencePreviewButtons, []string{SilencePreviewAction.Confirm, SilencePreviewAction.Cancel}},
+		{"approval", SilenceApprovalButtons, []string{SilenceApprovalAction.Approve, SilenceApprovalAction.Reject}},
+		{"reminder", SilenceReminderButtons, []string{SilenceReminderAction.Extend, SilenceReminderAction.LetExpire}},
+		{"stale silence", StaleSilenceButtons, []string{StaleSilenceAction.Expire, StaleSilenceAction.Keep}},
+	}
+
+	for _, tc := range testCases {
+		blocks := tc.buttons.Blocks("text", "silence-id")
+		require.Len(t, blocks, 2, "%s: there should be the text and the buttons", tc.name)
+
+		elements := blocks[1].Elements
+		require.Len(t, elements, len(tc.actions), "%s: there should be a button per action", tc.name)
+		for i, expectedAction := range tc.actions {
+			button := elements[i].(*ButtonElement)
+			action, id, err := tc.buttons.FromBlockAction(BlockAction{ActionID: button.ActionID, Value: button.Value})
+			require.NoError(t, err, "%s: there should be no error parsing the button", tc.name)
+			assert.Equal(t, expectedAction, action, "%s: the action should be equal", tc.name)
+			assert.Equal(t, "silence-id", id, "%s: the ID should be equal", tc.name)
+		}
+
+		_, _, err := tc.buttons.FromBlockAction(BlockAction{ActionID: tc.buttons.ActionIDPrefix + "unknown", Value: "silence-id"})
+		assert.Error(t, err, "%s: unknown actions should raise an error", tc.name)
+		_, _, err = tc.buttons.FromBlockAction(BlockAction{ActionID: tc.buttons.ActionIDPrefix + tc.actions[0]})
+		assert.Error(t, err, "%s: buttons without a silence should raise an error", tc.name)
+
+		for _, other := range testCases {
+			if other.name != tc.name {
+				_, _, err := tc.buttons.FromBlockAction(BlockAction{ActionID: other.buttons.ActionIDPrefix + other.actions[0], Value: "silence-id"})
+				assert.Error(t, err, "%s: %s buttons should not be parsed", tc.name, other.name)
+			}
+		}
+	}
+
+	reminder := SilenceReminderButtons.WithText(SilenceReminderAction.Extend, "Extend by 1d")
+	assert.Equal(t, "Extend by 1d", reminder.ActionsBlock("silence-id").Elements[0].(*ButtonElement).Text.Text, "the text should be replaced")
+	assert.Equal(t, "Extend", SilenceReminderButtons.Buttons[0].Text, "the original buttons should be unchanged")
+}
@@ -0,0 +1,53 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"fmt"
+
+	"github.com/sapcc/stargate/pkg/alertmanager"
+)
+
+// SilencePreviewBlocks renders the alerts matched by a silence with buttons to confirm or cancel it.
+// The ID identifies the pending silence.
+func SilencePreviewBlocks(header string, blastRadius *alertmanager.BlastRadius, id string) []Block {
+	alertList := sortAlertsBySeverity(blastRadius.Alerts)
+
+	blocks := []Block{
+		NewSectionBlock(header),
+		NewDividerBlock(),
+	}
+
+	end := len(alertList)
+	if end > AlertsPageSize {
+		end = AlertsPageSize
+	}
+	for _, a := range alertList[:end] {
+		blocks = append(blocks, NewSectionBlock(alertText(a)))
+	}
+	if more := len(alertList) - end; more > 0 {
+		blocks = append(blocks, NewContextBlock(fmt.Sprintf("and %d more alerts.", more)))
+	}
+
+	return append(blocks,
+		NewDividerBlock(),
+		SilencePreviewButtons.ActionsBlock(id),
+	)
+}
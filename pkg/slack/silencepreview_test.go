@@ -0,0 +1,37 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"testing"
+
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSilencePreviewBlocks(t *testing.T) {
+	blastRadius := &alertmanager.BlastRadius{Alerts: append(newTestAlerts(8, "warning"), newTestAlerts(7, "critical")...)}
+
+	blocks := SilencePreviewBlocks("this will silence 15 alerts", blastRadius, "pending-id")
+	assert.Equal(t, "this will silence 15 alerts", blocks[0].Text.Text, "the first block should be the header")
+	assert.Len(t, blocks, 2+AlertsPageSize+1+2, "at most a page of alerts should be listed")
+	assert.Equal(t, "and 5 more alerts.", blocks[2+AlertsPageSize].Elements[0].(*TextObject).Text, "the remaining alerts should be counted")
+	assert.Equal(t, SilencePreviewButtons.ActionsBlock("pending-id"), blocks[len(blocks)-1], "the last block should be the buttons")
+}
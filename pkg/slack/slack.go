@@ -21,7 +21,11 @@ package slack
 
 import (
 	"fmt"
+	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/nlopes/slack"
 	"github.com/nlopes/slack/slackevents"
@@ -40,12 +44,24 @@ type Client struct {
 
 	// list of slack user ids that are authorized to interact with stargate messages.
 	authorizedUserIDs []string
+	// userGroupMembers maps the name of an authorized slack user group to the ids of its members.
+	userGroupMembers map[string][]string
+	// userGroupIDs maps the name of an authorized slack user group to its id.
+	userGroupIDs        map[string]string
+	authorizedUsersLock sync.RWMutex
 
 	Client         *slack.Client
 	slackRTMClient *slack.RTM
+	// httpClient is used for Web API methods not supported by the slack library.
+	httpClient *http.Client
 
 	// only used in bot mode
 	alertmanagerClient *alertmanager.Client
+	// botUserID is set by the RTM client once connected and read by the message handlers.
+	botUserID      string
+	botUserIDLock  sync.RWMutex
+	messageHandler MessageHandler
+	eventHandlers  map[string]EventHandler
 }
 
 // NewClient returns a new slack client.
@@ -54,9 +70,11 @@ func NewClient(config config.Config, opts config.Options, logger log.Logger) *Cl
 	s.SetDebug(opts.IsDebug)
 
 	Client := &Client{
-		config: config,
-		logger: logger,
-		Client: s,
+		config:             config,
+		logger:             logger,
+		Client:             s,
+		httpClient:         &http.Client{Timeout: 30 * time.Second},
+		alertmanagerClient: alertmanager.New(config, logger),
 	}
 
 	logger = log.NewLoggerWith(logger, "component", "slack")
@@ -65,7 +83,9 @@ func NewClient(config config.Config, opts config.Options, logger log.Logger) *Cl
 		Client.slackRTMClient = NewSlackRTM(config, opts)
 	}
 
-	// get the list initially. refresh every slack.recheck_interval.
+	// get the list initially. refresh every slack.recheck_interval and on user group change events.
+	Client.SetEventHandler(InnerEventType.SubteamMembersChanged, Client.handleSubteamMembersChanged)
+	Client.SetEventHandler(InnerEventType.SubteamUpdated, Client.handleSubteamUpdated)
 	if err := Client.GetAuthorizedSlackUserGroupMembers(); err != nil {
 		logger.LogFatal("failed to get authorized slack users", "err", err)
 	}
@@ -112,37 +132,55 @@ func messageTextFromSlack(message slack.Message) string {
 
 // GetAuthorizedSlackUserGroupMembers sets the authorized slack users based on the membership in slack groups.
 func (s *Client) GetAuthorizedSlackUserGroupMembers() error {
-	userGroupIDs, err := s.userGroupNamesToIDs(s.config.Slack.AuthorizedGroups)
+	groupNames := s.authorizedGroupNames()
+	userGroupIDsByName, err := s.userGroupNamesToIDs(groupNames)
 	if err != nil {
 		return err
 	}
 
-	var authorizedUserIDs []string
-	for _, groupID := range userGroupIDs {
+	userGroupMembers := make(map[string][]string, len(userGroupIDsByName))
+	for groupName, groupID := range userGroupIDsByName {
 		members, err := s.Client.GetUserGroupMembers(groupID)
 		if err != nil {
 			s.logger.LogError("error while getting members of group", err, "groupID", groupID)
 			continue
 		}
-		authorizedUserIDs = append(authorizedUserIDs, members...)
+		userGroupMembers[groupName] = members
 	}
 
-	if authorizedUserIDs == nil || len(authorizedUserIDs) == 0 {
-		return errors.New("not a single user is authorized to respond to slack messages. check `authorized_groups` in config")
+	if len(authorizedUserIDsFromGroups(userGroupMembers)) == 0 {
+		return errors.New("not a single user is authorized to respond to slack messages. check `authorized_groups` and `authorization.policies` in config")
 	}
 
 	s.logger.LogInfo("authorizing members of slack users groups",
-		"groups", strings.Join(s.config.Slack.AuthorizedGroups, ", "),
+		"groups", strings.Join(groupNames, ", "),
 	)
 
-	s.authorizedUserIDs = authorizedUserIDs
+	s.authorizedUsersLock.Lock()
+	defer s.authorizedUsersLock.Unlock()
+	s.userGroupIDs = userGroupIDsByName
+	s.setUserGroupMembers(userGroupMembers)
 	return nil
 }
 
+// authorizedGroupNames returns the names of the `authorized_groups` and the groups used in policies.
+func (s *Client) authorizedGroupNames() []string {
+	groupNames := make([]string, 0)
+	groupNames = append(groupNames, s.config.Slack.AuthorizedGroups...)
+	for _, p := range s.config.Authorization.Policies {
+		for _, groupName := range p.Groups {
+			if !util.StringSliceContains(groupNames, groupName) {
+				groupNames = append(groupNames, groupName)
+			}
+		}
+	}
+	return groupNames
+}
+
 // for convenience slack user groups are configured by name rather than ID.
 // userGroupNamesToIDs finds the ID based on the name of a slack user group.
-func (s *Client) userGroupNamesToIDs(userGroupNames []string) ([]string, error) {
-	userGroupIDs := make([]string, 0)
+func (s *Client) userGroupNamesToIDs(userGroupNames []string) (map[string]string, error) {
+	userGroupIDs := make(map[string]string)
 	userGroups, err := s.Client.GetUserGroups()
 	if err != nil {
 		return userGroupIDs, err
@@ -150,7 +188,7 @@ func (s *Client) userGroupNamesToIDs(userGroupNames []string) ([]string, error)
 
 	for _, group := range userGroups {
 		if util.StringSliceContains(userGroupNames, group.Name) {
-			userGroupIDs = append(userGroupIDs, group.ID)
+			userGroupIDs[group.Name] = group.ID
 		}
 	}
 	return userGroupIDs, nil
@@ -158,9 +196,26 @@ func (s *Client) userGroupNamesToIDs(userGroupNames []string) ([]string, error)
 
 // IsUserAuthorized checks whether a user is authorized.
 func (s *Client) IsUserAuthorized(userID string) bool {
+	s.authorizedUsersLock.RLock()
+	defer s.authorizedUsersLock.RUnlock()
 	return util.StringSliceContains(s.authorizedUserIDs, userID)
 }
 
+// GetUserGroupNames returns the names of the authorized slack user groups the user is a member of.
+func (s *Client) GetUserGroupNames(userID string) []string {
+	s.authorizedUsersLock.RLock()
+	defer s.authorizedUsersLock.RUnlock()
+
+	groupNames := make([]string, 0)
+	for groupName, members := range s.userGroupMembers {
+		if util.StringSliceContains(members, userID) {
+			groupNames = append(groupNames, groupName)
+		}
+	}
+	sort.Strings(groupNames)
+	return groupNames
+}
+
 // GetUserNameByID converts the userID to a human readable name in the format 'userRealName (userName)'.
 func (s *Client) GetUserNameByID(userID string) (string, error) {
 	user, err := s.Client.GetUserInfo(userID)
@@ -182,6 +237,18 @@ func (s *Client) GetUserNameByID(userID string) (string, error) {
 	return name, nil
 }
 
+// GetUserLocation returns the time zone configured by the user.
+func (s *Client) GetUserLocation(userID string) (*time.Location, error) {
+	user, err := s.Client.GetUserInfo(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get slack info for user with id '%s'", userID)
+	}
+	if user.TZ == "" {
+		return nil, fmt.Errorf("user '%s' didn't configure a time zone", user.Name)
+	}
+	return time.LoadLocation(user.TZ)
+}
+
 // GetUserEmailByID returns the users email address.
 func (s *Client) GetUserEmailByID(userID string) (string, error) {
 	userProfile, err := s.Client.GetUserProfile(userID, false)
@@ -191,11 +258,39 @@ func (s *Client) GetUserEmailByID(userID string) (string, error) {
 
 	email := userProfile.Email
 	if email == "" {
-		fmt.Errorf("user '%s' didn't maintain an email address", userProfile.RealName)
+		return "", fmt.Errorf("user '%s' didn't maintain an email address", userProfile.RealName)
 	}
 	return email, nil
 }
 
+// GetUserProfileField returns the value of a custom field in the users profile.
+func (s *Client) GetUserProfileField(userID, fieldID string) (string, error) {
+	userProfile, err := s.Client.GetUserProfile(userID, false)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get slack profile for user with id '%s'", userID)
+	}
+
+	field, ok := userProfile.Fields.ToMap()[fieldID]
+	if !ok || field.Value == "" {
+		return "", fmt.Errorf("user '%s' didn't maintain the profile field '%s'", userProfile.RealName, fieldID)
+	}
+	return field.Value, nil
+}
+
+// PostEphemeral posts a message to a channel only visible to the given user.
+// The message is sent as a direct message if no channel is given.
+func (s *Client) PostEphemeral(channel, userID, message string) {
+	if channel == "" {
+		s.PostMessage(userID, message, "")
+		return
+	}
+
+	_, err := s.Client.PostEphemeral(channel, userID, slack.MsgOptionText(message, false))
+	if err != nil {
+		s.logger.LogError("error posting ephemeral message to slack", err, "channel", channel, "userID", userID)
+	}
+}
+
 // PostMessage post a message to a channel.
 // If 'timestamp' is given, the message is posted to a thread.
 func (s *Client) PostMessage(channel, message, timestamp string) {
@@ -242,6 +337,21 @@ func (s *Client) MessageActionFromPayload(payload string) (slackevents.MessageAc
 	return slackMessageAction, err
 }
 
+// MessageActionFromSocketModePayload parses a message action received via the authenticated Socket Mode websocket.
+func (s *Client) MessageActionFromSocketModePayload(payload string) (slackevents.MessageAction, error) {
+	return slackevents.ParseActionEvent(
+		payload,
+		slackevents.OptionVerifyToken(socketModeVerifier{}),
+	)
+}
+
+// socketModeVerifier accepts every token as Socket Mode is authenticated via the app-level token.
+type socketModeVerifier struct{}
+
+func (socketModeVerifier) Verify(string) bool {
+	return true
+}
+
 // ActionFromSlackMessage retrieves the action from a slack message.
 func (s *Client) ActionFromSlackMessage(messageAction slackevents.MessageAction) ([]string, error) {
 	reactions := make([]string, 0)
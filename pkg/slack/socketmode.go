@@ -0,0 +1,264 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nlopes/slack"
+	"github.com/pkg/errors"
+	"github.com/sapcc/stargate/pkg/log"
+)
+
+const (
+	// SocketModeConnectionsOpenURL is used to obtain the URL of the Socket Mode websocket.
+	SocketModeConnectionsOpenURL = "https://slack.com/api/apps.connections.open"
+
+	// socketModeMinBackoff is the initial time waited before reconnecting.
+	socketModeMinBackoff = 1 * time.Second
+
+	// socketModeMaxBackoff is the maximal time waited before reconnecting.
+	socketModeMaxBackoff = 1 * time.Minute
+)
+
+// SocketModeEnvelopeType lists the types of messages sent via Socket Mode.
+var SocketModeEnvelopeType = struct {
+	Hello,
+	Disconnect,
+	EventsAPI,
+	Interactive,
+	SlashCommands string
+}{
+	"hello",
+	"disconnect",
+	"events_api",
+	"interactive",
+	"slash_commands",
+}
+
+// SocketModeHandlers are called for the payloads received via Socket Mode.
+// These are the same handlers used for the HTTP endpoints.
+type SocketModeHandlers struct {
+	// Interactive handles interactive payloads like message actions.
+	Interactive func(payload string)
+
+	// SlashCommand handles slash commands.
+	SlashCommand func(slashCommand slack.SlashCommand)
+
+	// Event handles events of the Events API.
+	Event func(event *EventsAPIEvent)
+}
+
+// socketModeEnvelope wraps every message sent via Socket Mode.
+type socketModeEnvelope struct {
+	Type       string          `json:"type"`
+	EnvelopeID string          `json:"envelope_id"`
+	Payload    json.RawMessage `json:"payload"`
+	Reason     string          `json:"reason"`
+}
+
+type socketModeAck struct {
+	EnvelopeID string `json:"envelope_id"`
+}
+
+type connectionsOpenResponse struct {
+	OK    bool   `json:"ok"`
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// SocketModeClient receives interactive payloads, slash commands and events via a websocket.
+// Useful if Slack cannot reach the stargate.
+type SocketModeClient struct {
+	logger   log.Logger
+	appToken string
+	handlers SocketModeHandlers
+	command  string
+
+	// ConnectionsOpenURL can be overwritten for testing.
+	ConnectionsOpenURL string
+	httpClient         *http.Client
+	dialer             *websocket.Dialer
+}
+
+// NewSocketModeClient returns a new SocketModeClient using the app-level token.
+func NewSocketModeClient(appToken, command string, handlers SocketModeHandlers, logger log.Logger) *SocketModeClient {
+	return &SocketModeClient{
+		logger:             log.NewLoggerWith(logger, "component", "socketmode"),
+		appToken:           appToken,
+		handlers:           handlers,
+		command:            command,
+		ConnectionsOpenURL: SocketModeConnectionsOpenURL,
+		httpClient:         &http.Client{Timeout: 30 * time.Second},
+		dialer:             websocket.DefaultDialer,
+	}
+}
+
+// Run connects to slack and reconnects until the stop channel is closed.
+func (c *SocketModeClient) Run(stopCh <-chan struct{}) {
+	c.logger.LogInfo("starting slack socket mode")
+	backoff := socketModeMinBackoff
+	for {
+		connectedAt, err := c.connectAndServe(stopCh)
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+
+		if err != nil {
+			var connectedFor time.Duration
+			if !connectedAt.IsZero() {
+				connectedFor = time.Since(connectedAt)
+			}
+			backoff = socketModeBackoff(backoff, connectedFor)
+			c.logger.LogError("socket mode connection failed. reconnecting", err, "backoff", backoff.String())
+			select {
+			case <-time.After(backoff):
+			case <-stopCh:
+				return
+			}
+			if backoff *= 2; backoff > socketModeMaxBackoff {
+				backoff = socketModeMaxBackoff
+			}
+			continue
+		}
+		backoff = socketModeMinBackoff
+	}
+}
+
+// socketModeBackoff returns the time to wait before reconnecting after a connection failed.
+// A connection which was up longer than the backoff is not a repeated failure, so the backoff is reset.
+func socketModeBackoff(backoff, connectedFor time.Duration) time.Duration {
+	if connectedFor > backoff {
+		return socketModeMinBackoff
+	}
+	return backoff
+}
+
+// connectAndServe handles messages until slack asks to reconnect or the connection fails.
+// Returns the time the connection was established, which is zero if connecting failed.
+func (c *SocketModeClient) connectAndServe(stopCh <-chan struct{}) (time.Time, error) {
+	wsURL, err := c.openConnection()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	conn, _, err := c.dialer.Dial(wsURL, nil)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to connect to websocket")
+	}
+	defer conn.Close()
+	connectedAt := time.Now()
+
+	// Close the connection to interrupt reading if stopped.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stopCh:
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var envelope socketModeEnvelope
+		if err := conn.ReadJSON(&envelope); err != nil {
+			return connectedAt, errors.Wrap(err, "failed to read from websocket")
+		}
+
+		// Acknowledge immediately as slack expects it within 3 seconds.
+		if envelope.EnvelopeID != "" {
+			if err := conn.WriteJSON(socketModeAck{EnvelopeID: envelope.EnvelopeID}); err != nil {
+				return connectedAt, errors.Wrap(err, "failed to acknowledge envelope")
+			}
+		}
+
+		switch envelope.Type {
+		case SocketModeEnvelopeType.Hello:
+			c.logger.LogInfo("connected to slack socket mode")
+		case SocketModeEnvelopeType.Disconnect:
+			c.logger.LogInfo("slack requested to reconnect", "reason", envelope.Reason)
+			return connectedAt, nil
+		default:
+			go c.dispatch(envelope)
+		}
+	}
+}
+
+func (c *SocketModeClient) dispatch(envelope socketModeEnvelope) {
+	switch envelope.Type {
+	case SocketModeEnvelopeType.EventsAPI:
+		var event EventsAPIEvent
+		if err := json.Unmarshal(envelope.Payload, &event); err != nil {
+			c.logger.LogError("failed to parse event", err, "envelopeID", envelope.EnvelopeID)
+			return
+		}
+		c.handlers.Event(&event)
+
+	case SocketModeEnvelopeType.Interactive:
+		c.handlers.Interactive(string(envelope.Payload))
+
+	case SocketModeEnvelopeType.SlashCommands:
+		var slashCommand slack.SlashCommand
+		if err := json.Unmarshal(envelope.Payload, &slashCommand); err != nil {
+			c.logger.LogError("failed to parse slash command", err, "envelopeID", envelope.EnvelopeID)
+			return
+		}
+		if slashCommand.Command != c.command {
+			c.logger.LogInfo("ignoring unknown slash command", "command", slashCommand.Command)
+			return
+		}
+		c.handlers.SlashCommand(slashCommand)
+
+	default:
+		c.logger.LogDebug("ignoring socket mode message", "type", envelope.Type)
+	}
+}
+
+// openConnection returns the URL of the websocket.
+func (c *SocketModeClient) openConnection() (string, error) {
+	req, err := http.NewRequest(http.MethodPost, c.ConnectionsOpenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.appToken))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open socket mode connection")
+	}
+	defer res.Body.Close()
+
+	var connectionsOpen connectionsOpenResponse
+	if err := json.NewDecoder(res.Body).Decode(&connectionsOpen); err != nil {
+		return "", errors.Wrap(err, "failed to parse response of apps.connections.open")
+	}
+	if !connectionsOpen.OK {
+		return "", fmt.Errorf("failed to open socket mode connection: %s. check the `slack.app_level_token`", connectionsOpen.Error)
+	}
+	return connectionsOpen.URL, nil
+}
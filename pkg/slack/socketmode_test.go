@@ -0,0 +1,146 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nlopes/slack"
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	socketModeHello        = `{"type":"hello","num_connections":1}`
+	socketModeEvent        = `{"envelope_id":"env-event","type":"events_api","accepts_response_payload":false,"payload":` + eventAppMention + `}`
+	socketModeSlashCommand = `{"envelope_id":"env-command","type":"slash_commands","accepts_response_payload":true,"payload":{"command":"/stargate","text":"whoami","user_id":"U0123","channel_id":"C0123"}}`
+	socketModeInteractive  = `{"envelope_id":"env-interactive","type":"interactive","accepts_response_payload":true,"payload":{"type":"interactive_message","callback_id":"stargate"}}`
+)
+
+// newFakeSocketModeServer returns a server implementing apps.connections.open and a websocket sending the given messages.
+func newFakeSocketModeServer(t *testing.T, messages []string, acks chan<- string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	mux.HandleFunc("/apps.connections.open", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xapp-token", r.Header.Get("Authorization"), "the app-level token should be used")
+		json.NewEncoder(w).Encode(connectionsOpenResponse{OK: true, URL: "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"})
+	})
+
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if !assert.NoError(t, err, "upgrading the connection should not raise an error") {
+			return
+		}
+		defer conn.Close()
+
+		for _, msg := range messages {
+			assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(msg)), "writing should not raise an error")
+		}
+
+		for {
+			var ack socketModeAck
+			if err := conn.ReadJSON(&ack); err != nil {
+				return
+			}
+			acks <- ack.EnvelopeID
+		}
+	})
+
+	return server
+}
+
+func TestSocketModeClient(t *testing.T) {
+	acks := make(chan string, 10)
+	server := newFakeSocketModeServer(t, []string{socketModeHello, socketModeEvent, socketModeSlashCommand, socketModeInteractive}, acks)
+	defer server.Close()
+
+	events := make(chan *EventsAPIEvent, 1)
+	slashCommands := make(chan slack.SlashCommand, 1)
+	interactivePayloads := make(chan string, 1)
+
+	c := NewSocketModeClient("xapp-token", "/stargate", SocketModeHandlers{
+		Event:        func(event *EventsAPIEvent) { events <- event },
+		SlashCommand: func(slashCommand slack.SlashCommand) { slashCommands <- slashCommand },
+		Interactive:  func(payload string) { interactivePayloads <- payload },
+	}, log.NewLogger(false))
+	c.ConnectionsOpenURL = server.URL + "/apps.connections.open"
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go c.Run(stopCh)
+
+	timeout := time.After(5 * time.Second)
+	receivedAcks := make([]string, 0)
+	for len(receivedAcks) < 3 {
+		select {
+		case ack := <-acks:
+			receivedAcks = append(receivedAcks, ack)
+		case <-timeout:
+			t.Fatal("timed out waiting for acknowledgements")
+		}
+	}
+	assert.ElementsMatch(t, []string{"env-event", "env-command", "env-interactive"}, receivedAcks, "every envelope should be acknowledged")
+
+	select {
+	case event := <-events:
+		assert.Equal(t, EventTypeCallback, event.Type, "the event type should be equal")
+		assert.Equal(t, "Ev0123", event.EventID, "the event id should be equal")
+	case <-timeout:
+		t.Fatal("timed out waiting for event")
+	}
+
+	select {
+	case slashCommand := <-slashCommands:
+		assert.Equal(t, "whoami", slashCommand.Text, "the text of the slash command should be equal")
+		assert.Equal(t, "U0123", slashCommand.UserID, "the user of the slash command should be equal")
+	case <-timeout:
+		t.Fatal("timed out waiting for slash command")
+	}
+
+	select {
+	case payload := <-interactivePayloads:
+		assert.Contains(t, payload, "interactive_message", "the interactive payload should be passed as is")
+	case <-timeout:
+		t.Fatal("timed out waiting for interactive payload")
+	}
+}
+
+func TestSocketModeBackoff(t *testing.T) {
+	tests := []struct {
+		backoff, connectedFor, expected time.Duration
+	}{
+		{backoff: 8 * time.Second, connectedFor: 0, expected: 8 * time.Second},
+		{backoff: 8 * time.Second, connectedFor: 2 * time.Second, expected: 8 * time.Second},
+		{backoff: 8 * time.Second, connectedFor: 3 * time.Hour, expected: socketModeMinBackoff},
+		{backoff: socketModeMaxBackoff, connectedFor: 2 * time.Minute, expected: socketModeMinBackoff},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, socketModeBackoff(tc.backoff, tc.connectedFor), "the backoff after a connection of %s should be equal", tc.connectedFor)
+	}
+}
@@ -0,0 +1,160 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/sapcc/stargate/pkg/metrics"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// subteamMembersChangedEvent is sent if members were added to or removed from a user group.
+type subteamMembersChangedEvent struct {
+	SubteamID    string   `json:"subteam_id"`
+	AddedUsers   []string `json:"added_users"`
+	RemovedUsers []string `json:"removed_users"`
+}
+
+// subteamUpdatedEvent is sent if a user group was updated, renamed or disabled.
+type subteamUpdatedEvent struct {
+	Subteam struct {
+		ID         string   `json:"id"`
+		Name       string   `json:"name"`
+		DateDelete int64    `json:"date_delete"`
+		Users      []string `json:"users"`
+	} `json:"subteam"`
+}
+
+// handleSubteamMembersChanged immediately applies membership changes of an authorized user group.
+func (s *Client) handleSubteamMembersChanged(event json.RawMessage) {
+	var e subteamMembersChangedEvent
+	if err := json.Unmarshal(event, &e); err != nil {
+		s.logger.LogError("failed to parse subteam_members_changed event", err)
+		return
+	}
+
+	s.authorizedUsersLock.Lock()
+	defer s.authorizedUsersLock.Unlock()
+
+	groupName, ok := s.userGroupNameByID(e.SubteamID)
+	if !ok {
+		s.logger.LogDebug("ignoring membership change of unauthorized user group", "groupID", e.SubteamID)
+		return
+	}
+
+	members := make([]string, 0, len(s.userGroupMembers[groupName])+len(e.AddedUsers))
+	for _, userID := range s.userGroupMembers[groupName] {
+		if !util.StringSliceContains(e.RemovedUsers, userID) {
+			members = append(members, userID)
+		}
+	}
+	for _, userID := range e.AddedUsers {
+		if !util.StringSliceContains(members, userID) {
+			members = append(members, userID)
+		}
+	}
+
+	s.logger.LogInfo("updating members of authorized slack user group",
+		"group", groupName,
+		"addedUsers", len(e.AddedUsers),
+		"removedUsers", len(e.RemovedUsers),
+	)
+	s.setUserGroupMembers(withUserGroupMembers(s.userGroupMembers, groupName, members))
+}
+
+// handleSubteamUpdated immediately applies the members of an authorized user group.
+// Members of groups which were renamed or disabled are no longer authorized.
+func (s *Client) handleSubteamUpdated(event json.RawMessage) {
+	var e subteamUpdatedEvent
+	if err := json.Unmarshal(event, &e); err != nil {
+		s.logger.LogError("failed to parse subteam_updated event", err)
+		return
+	}
+	group := e.Subteam
+	isAuthorizedGroup := util.StringSliceContains(s.authorizedGroupNames(), group.Name) && group.DateDelete == 0
+
+	s.authorizedUsersLock.Lock()
+	defer s.authorizedUsersLock.Unlock()
+
+	if previousName, ok := s.userGroupNameByID(group.ID); ok && (previousName != group.Name || !isAuthorizedGroup) {
+		s.logger.LogInfo("revoking authorization of slack user group", "group", previousName, "groupID", group.ID)
+		delete(s.userGroupIDs, previousName)
+		s.setUserGroupMembers(withUserGroupMembers(s.userGroupMembers, previousName, nil))
+	}
+
+	if !isAuthorizedGroup {
+		return
+	}
+
+	s.logger.LogInfo("updating members of authorized slack user group", "group", group.Name, "members", len(group.Users))
+	if s.userGroupIDs == nil {
+		s.userGroupIDs = make(map[string]string)
+	}
+	s.userGroupIDs[group.Name] = group.ID
+	s.setUserGroupMembers(withUserGroupMembers(s.userGroupMembers, group.Name, group.Users))
+}
+
+// userGroupNameByID returns the name of an authorized user group. The authorizedUsersLock must be held.
+func (s *Client) userGroupNameByID(groupID string) (string, bool) {
+	for name, id := range s.userGroupIDs {
+		if id == groupID {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// setUserGroupMembers sets the members of the authorized user groups. The authorizedUsersLock must be held.
+func (s *Client) setUserGroupMembers(userGroupMembers map[string][]string) {
+	s.userGroupMembers = userGroupMembers
+	s.authorizedUserIDs = authorizedUserIDsFromGroups(userGroupMembers)
+	metrics.AuthorizedUsers.Set(float64(len(s.authorizedUserIDs)))
+}
+
+// withUserGroupMembers returns a copy of the user group members with the members of the given group replaced.
+// The group is removed if it has no members.
+func withUserGroupMembers(userGroupMembers map[string][]string, groupName string, members []string) map[string][]string {
+	result := make(map[string][]string, len(userGroupMembers)+1)
+	for name, m := range userGroupMembers {
+		result[name] = m
+	}
+	if len(members) == 0 {
+		delete(result, groupName)
+	} else {
+		result[groupName] = members
+	}
+	return result
+}
+
+// authorizedUserIDsFromGroups returns the sorted ids of all members of the user groups without duplicates.
+func authorizedUserIDsFromGroups(userGroupMembers map[string][]string) []string {
+	userIDs := make([]string, 0)
+	for _, members := range userGroupMembers {
+		for _, userID := range members {
+			if !util.StringSliceContains(userIDs, userID) {
+				userIDs = append(userIDs, userID)
+			}
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs
+}
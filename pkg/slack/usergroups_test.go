@@ -0,0 +1,71 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClientWithUserGroups() *Client {
+	s := newTestClient()
+	s.config.Slack.AuthorizedGroups = []string{"admin", "oncall"}
+	s.SetEventHandler(InnerEventType.SubteamMembersChanged, s.handleSubteamMembersChanged)
+	s.SetEventHandler(InnerEventType.SubteamUpdated, s.handleSubteamUpdated)
+	s.userGroupIDs = map[string]string{"admin": "S0001", "oncall": "S0002"}
+	s.setUserGroupMembers(map[string][]string{
+		"admin":  {"U0001", "U0002"},
+		"oncall": {"U0002", "U0003"},
+	})
+	return s
+}
+
+func TestHandleSubteamMembersChanged(t *testing.T) {
+	s := newTestClientWithUserGroups()
+	assert.Equal(t, []string{"U0001", "U0002", "U0003"}, s.authorizedUserIDs, "the authorized users should not contain duplicates")
+
+	s.dispatchEvent(InnerEventType.SubteamMembersChanged, json.RawMessage(`{"type":"subteam_members_changed","subteam_id":"S0002","added_users":["U0004"],"removed_users":["U0003"]}`))
+	assert.True(t, s.IsUserAuthorized("U0004"), "the added user should be authorized")
+	assert.False(t, s.IsUserAuthorized("U0003"), "the removed user should no longer be authorized")
+	assert.Equal(t, []string{"admin", "oncall"}, s.GetUserGroupNames("U0002"), "the groups of the user should be equal")
+
+	s.dispatchEvent(InnerEventType.SubteamMembersChanged, json.RawMessage(`{"type":"subteam_members_changed","subteam_id":"S0099","added_users":["U0005"]}`))
+	assert.False(t, s.IsUserAuthorized("U0005"), "members of unauthorized groups should not be authorized")
+}
+
+func TestHandleSubteamUpdated(t *testing.T) {
+	s := newTestClientWithUserGroups()
+
+	s.dispatchEvent(InnerEventType.SubteamUpdated, json.RawMessage(`{"type":"subteam_updated","subteam":{"id":"S0002","name":"oncall","date_delete":0,"users":["U0005"]}}`))
+	assert.True(t, s.IsUserAuthorized("U0005"), "the new member should be authorized")
+	assert.False(t, s.IsUserAuthorized("U0003"), "the former member should no longer be authorized")
+
+	s.dispatchEvent(InnerEventType.SubteamUpdated, json.RawMessage(`{"type":"subteam_updated","subteam":{"id":"S0002","name":"former-oncall","date_delete":0,"users":["U0005"]}}`))
+	assert.False(t, s.IsUserAuthorized("U0005"), "members of a renamed group should no longer be authorized")
+
+	s.dispatchEvent(InnerEventType.SubteamUpdated, json.RawMessage(`{"type":"subteam_updated","subteam":{"id":"S0002","name":"oncall","date_delete":0,"users":["U0005"]}}`))
+	assert.True(t, s.IsUserAuthorized("U0005"), "members of a group renamed to an authorized group should be authorized")
+
+	s.dispatchEvent(InnerEventType.SubteamUpdated, json.RawMessage(`{"type":"subteam_updated","subteam":{"id":"S0001","name":"admin","date_delete":1550000000,"users":["U0001"]}}`))
+	assert.False(t, s.IsUserAuthorized("U0001"), "members of a disabled group should no longer be authorized")
+	assert.True(t, s.IsUserAuthorized("U0005"), "members of other groups should still be authorized")
+}
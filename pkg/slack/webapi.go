@@ -0,0 +1,84 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/nlopes/slack"
+	"github.com/pkg/errors"
+)
+
+// webAPIResponse is the common part of every Slack Web API response.
+type webAPIResponse struct {
+	OK               bool   `json:"ok"`
+	Error            string `json:"error"`
+	ResponseMetadata struct {
+		Messages []string `json:"messages"`
+	} `json:"response_metadata"`
+}
+
+// callWebAPI calls a method of the Slack Web API not supported by the slack library using a JSON body.
+// The response is decoded into the result if given.
+func (s *Client) callWebAPI(method string, body, result interface{}) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal body for %s", method)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, slack.SLACK_API+method, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.Slack.AccessToken))
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to call %s", method)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to call %s: unexpected status code %d", method, res.StatusCode)
+	}
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read response of %s", method)
+	}
+
+	var apiResponse webAPIResponse
+	if err := json.Unmarshal(resBody, &apiResponse); err != nil {
+		return errors.Wrapf(err, "failed to parse response of %s", method)
+	}
+	if !apiResponse.OK {
+		return fmt.Errorf("failed to call %s: %s %v", method, apiResponse.Error, apiResponse.ResponseMetadata.Messages)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resBody, result)
+}
@@ -0,0 +1,85 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/sapcc/stargate/pkg/alert"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/metrics"
+	"github.com/sapcc/stargate/pkg/slack"
+)
+
+// acknowledgeAlert acknowledges the alerts matching the labels of the given alert in the Alertmanager
+// and the corresponding incident in the pager on behalf of the responding user.
+// Returns false if acknowledging failed.
+func (s *Stargate) acknowledgeAlert(ackAlert *client.ExtendedAlert, alertname, userName string, responder *slack.Responder) bool {
+	// List all alerts that match the given alert.
+	filter := alertmanager.NewDefaultFilter()
+	filter.WithAlertLabelsFilter(ackAlert.Labels)
+	alertList, err := s.alertmanagerClient.ListAlerts(filter)
+	if err != nil {
+		responder.Fail("failed to list alerts from the alertmanager", err)
+		return false
+	}
+
+	// Acknowledge the alerts matching the labels.
+	acknowledgeErr := s.alertStore.AcknowledgeAndSetMultipleByUser(alertList, userName, responder.UserID)
+	if acknowledgeErr != nil {
+		// Don't return here on failure. We might be able to acknowledge in the pager.
+		responder.Fail("failed to acknowledge alert in the alertmanager", acknowledgeErr, "component", "alertmanager", "labels", alert.ClientLabelSetToString(ackAlert.Labels))
+		metrics.FailedOperationsTotal.WithLabelValues("acknowledge", metrics.BackendAlertmanager).Inc()
+	} else {
+		for _, a := range alertList {
+			responder.Logger.LogInfo("acknowledged alert", "component", "alertmanager", "labels", alert.ClientLabelSetToString(a.Labels))
+		}
+		metrics.SuccessfulOperationsTotal.WithLabelValues("acknowledge", metrics.BackendAlertmanager).Inc()
+	}
+
+	// The pager integration is optional.
+	if s.pager == nil {
+		if acknowledgeErr != nil {
+			return false
+		}
+		responder.Respondf("Acknowledged alert %s.", alertname)
+		return true
+	}
+
+	// The pager might fall back to its default user.
+	pagerUser, _, err := s.pagerUserBySlackUserID(responder.UserID)
+	if err != nil {
+		responder.Logger.LogWarn("acknowledging incident without pager user", "err", err.Error())
+	}
+	if err := s.pager.AcknowledgeIncident(ackAlert, pagerUser); err != nil {
+		responder.Fail(fmt.Sprintf("failed to acknowledge incident in %s", s.pager.Name()), err, "component", s.pager.Name())
+		metrics.FailedOperationsTotal.WithLabelValues("acknowledge", s.pager.Name()).Inc()
+		return false
+	}
+	responder.Logger.LogInfo("acknowledged alert", "component", s.pager.Name(), "labels", alert.ClientLabelSetToString(ackAlert.Labels))
+	metrics.SuccessfulOperationsTotal.WithLabelValues("acknowledge", s.pager.Name()).Inc()
+	if acknowledgeErr != nil {
+		responder.Respondf("Acknowledged only the incident of alert %s in %s. The alert could not be acknowledged in the Alertmanager.", alertname, s.pager.Name())
+		return true
+	}
+	responder.Respondf("Acknowledged alert %s and the incident in %s.", alertname, s.pager.Name())
+	return true
+}
@@ -0,0 +1,161 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/sapcc/stargate/pkg/alert"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// expiringSilencesWindow is the time in which silences are considered to expire soon.
+const expiringSilencesWindow = 24 * time.Hour
+
+// handleAppHomeOpened publishes the App Home of the user opening it.
+func (s *Stargate) handleAppHomeOpened(event json.RawMessage) {
+	e, err := slack.ParseAppHomeOpenedEvent(event)
+	if err != nil {
+		s.logger.LogError("failed to parse app_home_opened event", err)
+		return
+	}
+	if e.Tab != slack.AppHomeTab {
+		return
+	}
+
+	if err := s.publishAppHome(e.User); err != nil {
+		s.logger.LogError("failed to publish app home", err, "userID", e.User)
+	}
+}
+
+// publishAppHome publishes the alert situation in the regions of the user,
+// the user's acknowledgements and silences as well as silences expiring soon.
+func (s *Stargate) publishAppHome(userID string) error {
+	regions := s.preferences.Get(userID).Regions
+
+	alertList, err := s.alertmanagerClient.ListAlerts(alertmanager.NewDefaultFilter())
+	if err != nil {
+		return err
+	}
+
+	silenceList, err := s.alertmanagerClient.ListSilences(alertmanager.NewDefaultFilter())
+	if err != nil {
+		return err
+	}
+
+	home := slack.AppHome{
+		UserID:        userID,
+		Regions:       regions,
+		Command:       s.Config.Slack.Command,
+		LinkToSilence: s.alertmanagerClient.LinkToSilence,
+	}
+
+	for _, a := range s.withAcknowledgements(alertList) {
+		severity := string(a.Labels[alertmanager.SeverityLabel])
+		if isInRegions(string(a.Labels[alertmanager.RegionLabel]), regions) && (severity == alert.AlertSeverity.Critical || severity == alert.AlertSeverity.Warning) {
+			home.Alerts = append(home.Alerts, a)
+		}
+	}
+
+	for _, a := range s.alertStore.List() {
+		if alert.IsAcknowledgedByUser(a, userID) {
+			home.Acknowledged = append(home.Acknowledged, a)
+		}
+	}
+
+	for _, sil := range silenceList {
+		if sil.Status.State != types.SilenceStateActive {
+			continue
+		}
+		if isSilencedBy(sil, userID) {
+			home.Silences = append(home.Silences, sil)
+		}
+		if time.Until(sil.EndsAt) < expiringSilencesWindow && isInRegions(silenceRegion(sil), regions) {
+			home.ExpiringSilences = append(home.ExpiringSilences, sil)
+		}
+	}
+
+	return s.slack.PublishAppHome(home)
+}
+
+// withAcknowledgements adds the acknowledgements found in the alert store to the alerts.
+func (s *Stargate) withAcknowledgements(alertList []*client.ExtendedAlert) []*client.ExtendedAlert {
+	result := make([]*client.ExtendedAlert, 0, len(alertList))
+	for _, a := range alertList {
+		fp, err := model.FingerprintFromString(a.Fingerprint)
+		if err != nil {
+			result = append(result, a)
+			continue
+		}
+
+		storedAlert, err := s.alertStore.Get(fp)
+		if err != nil {
+			result = append(result, a)
+			continue
+		}
+
+		withAck := *a
+		withAck.Annotations = copyLabelSet(a.Annotations)
+		for _, k := range []client.LabelName{alertmanager.AcknowledgedByLabel, alertmanager.AcknowledgedByIDLabel, alertmanager.AcknowledgedAtLabel} {
+			if v, ok := storedAlert.Annotations[k]; ok {
+				withAck.Annotations[k] = v
+			}
+		}
+		result = append(result, &withAck)
+	}
+	return result
+}
+
+// isSilencedBy checks whether the silence was requested by the user according to the origin marker in its comment.
+// The name in createdBy is not verified and can be chosen freely in the Alertmanager UI.
+func isSilencedBy(sil *types.Silence, userID string) bool {
+	origin, ok := silence.ParseOrigin(sil.Comment)
+	return ok && origin.UserID == userID
+}
+
+// isInRegions checks whether the region is one of the regions. Every region matches if no regions are given.
+func isInRegions(region string, regions []string) bool {
+	return len(regions) == 0 || util.StringSliceContains(regions, region)
+}
+
+// silenceRegion returns the region of the silence or an empty string.
+func silenceRegion(sil *types.Silence) string {
+	for _, m := range sil.Matchers {
+		if m.Name == alertmanager.RegionLabel && !m.IsRegex {
+			return m.Value
+		}
+	}
+	return ""
+}
+
+func copyLabelSet(labelSet client.LabelSet) client.LabelSet {
+	c := make(client.LabelSet, len(labelSet))
+	for k, v := range labelSet {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,37 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSilencedBy(t *testing.T) {
+	silencedViaSlack := &types.Silence{CreatedBy: "Jane Doe (JDOE)", Comment: "maintenance\n" + silence.Origin{UserID: "U0001"}.Marker()}
+	assert.True(t, isSilencedBy(silencedViaSlack, "U0001"), "the silence should belong to the user of the origin marker")
+	assert.False(t, isSilencedBy(silencedViaSlack, "U0002"), "the silence should not belong to other users")
+
+	silencedViaUI := &types.Silence{CreatedBy: "Jane Doe (JDOE)", Comment: "maintenance"}
+	assert.False(t, isSilencedBy(silencedViaUI, "U0001"), "silences without origin marker should not be matched by the name of their creator")
+}
@@ -0,0 +1,123 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"github.com/pkg/errors"
+	"github.com/prometheus/alertmanager/client"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/slack"
+)
+
+// authorize checks whether the acting user is allowed to perform the request.
+// A denial is explained to the user.
+func (s *Stargate) authorize(responder *slack.Responder, req policy.Request) bool {
+	err := s.authorizer.Authorize(s.slack.GetUserGroupNames(responder.UserID), req)
+	if err == nil {
+		return true
+	}
+
+	responder.Deny(err.Error())
+	return false
+}
+
+// authorizeLabelSets checks whether the acting user is allowed to perform the request on each of the alerts given by their labels.
+// A denial is explained to the user.
+func (s *Stargate) authorizeLabelSets(responder *slack.Responder, req policy.Request, labelSets []map[string]string) bool {
+	err := authorizeAll(s.authorizer, s.slack.GetUserGroupNames(responder.UserID), req, labelSets)
+	if err == nil {
+		return true
+	}
+
+	responder.Deny(err.Error())
+	return false
+}
+
+// authorizeAll returns nil if the request is allowed for each of the label sets.
+// There must be at least one label set.
+func authorizeAll(authorizer *policy.Authorizer, userGroups []string, req policy.Request, labelSets []map[string]string) error {
+	if len(labelSets) == 0 {
+		return errors.New("no alerts to authorize")
+	}
+	for _, labels := range labelSets {
+		req.Labels = labels
+		if err := authorizer.Authorize(userGroups, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchingAlertLabels returns the labels of every alert matching the labels of the slack alert.
+// The slack message only contains some labels, but policies might be scoped by others.
+// Fails if the alerts cannot be listed, so requests are not authorized on incomplete labels.
+func (s *Stargate) matchingAlertLabels(slackAlert *client.ExtendedAlert) ([]map[string]string, error) {
+	filter := alertmanager.NewDefaultFilter()
+	filter.WithAlertLabelsFilter(slackAlert.Labels)
+	alertList, err := s.alertmanagerClient.ListAlerts(filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list alerts from the alertmanager")
+	}
+	return alertLabelSets(slackAlert, alertList), nil
+}
+
+// alertLabelSets returns the labels of each alert merged with the labels of the slack alert.
+// Only the labels of the slack alert are returned if no alert matches, e.g. as it already resolved.
+func alertLabelSets(slackAlert *client.ExtendedAlert, alertList []*client.ExtendedAlert) []map[string]string {
+	if len(alertList) == 0 {
+		alertList = []*client.ExtendedAlert{slackAlert}
+	}
+
+	labelSets := make([]map[string]string, 0, len(alertList))
+	for _, a := range alertList {
+		labels := make(map[string]string, len(a.Labels)+len(slackAlert.Labels))
+		for k, v := range a.Labels {
+			labels[string(k)] = string(v)
+		}
+		for k, v := range slackAlert.Labels {
+			labels[string(k)] = string(v)
+		}
+		labelSets = append(labelSets, labels)
+	}
+	return labelSets
+}
+
+// commonLabels returns the labels with the same value in all label sets.
+func commonLabels(labelSets []map[string]string) map[string]string {
+	common := make(map[string]string)
+	if len(labelSets) == 0 {
+		return common
+	}
+
+	for k, v := range labelSets[0] {
+		isCommon := true
+		for _, labels := range labelSets[1:] {
+			if labels[k] != v {
+				isCommon = false
+				break
+			}
+		}
+		if isCommon {
+			common[k] = v
+		}
+	}
+	return common
+}
@@ -0,0 +1,56 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizeAllMatchingAlerts(t *testing.T) {
+	authorizer := policy.NewAuthorizer([]policy.Policy{
+		{
+			Name:    "compute",
+			Groups:  []string{"compute"},
+			Actions: []string{policy.Action.Acknowledge},
+			Scopes:  map[string][]string{"service": {"compute"}},
+		},
+	}, nil)
+
+	slackAlert := &client.ExtendedAlert{Alert: client.Alert{Labels: client.LabelSet{"alertname": "HighLoad", "region": "eu-de-1"}}}
+	inScope := &client.ExtendedAlert{Alert: client.Alert{Labels: client.LabelSet{"alertname": "HighLoad", "region": "eu-de-1", "service": "compute"}}}
+	outOfScope := &client.ExtendedAlert{Alert: client.Alert{Labels: client.LabelSet{"alertname": "HighLoad", "region": "eu-de-1", "service": "network"}}}
+	req := policy.Request{Action: policy.Action.Acknowledge}
+
+	labelSets := alertLabelSets(slackAlert, []*client.ExtendedAlert{inScope})
+	assert.NoError(t, authorizeAll(authorizer, []string{"compute"}, req, labelSets), "a single alert in scope should be allowed")
+
+	labelSets = alertLabelSets(slackAlert, []*client.ExtendedAlert{inScope, outOfScope})
+	assert.Len(t, labelSets, 2)
+	assert.Error(t, authorizeAll(authorizer, []string{"compute"}, req, labelSets), "any matched alert out of scope should deny the request")
+	assert.Equal(t, map[string]string{"alertname": "HighLoad", "region": "eu-de-1"}, commonLabels(labelSets))
+
+	labelSets = alertLabelSets(slackAlert, nil)
+	assert.Error(t, authorizeAll(authorizer, []string{"compute"}, req, labelSets), "labels missing from the slack message should deny the request")
+	assert.Error(t, authorizeAll(authorizer, []string{"compute"}, req, nil), "no alerts should deny the request")
+}
@@ -0,0 +1,105 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sapcc/stargate/pkg/slack"
+)
+
+// handleSlackBlockActions handles buttons clicked in Block Kit messages posted by the stargate.
+func (s *Stargate) handleSlackBlockActions(blockActions slack.BlockActions) {
+	// The outcome of every action is reported to the acting user.
+	responder := s.slack.NewResponder(blockActions.Channel.Id, blockActions.User.Id, blockActions.ResponseURL)
+
+	for _, action := range blockActions.Actions {
+		switch {
+
+		// Acknowledge or silence an alert of the alert list using the same actions as for messages posted by the Alertmanager.
+		case strings.HasPrefix(action.ActionID, slack.BlockActionID.AlertReaction):
+			reaction, actionAlert, err := slack.AlertFromBlockAction(action)
+			if err != nil {
+				responder.Fail("failed to parse alert from button", err)
+				return
+			}
+
+			s.handleAlertActions(alertActionContext{
+				channel:          blockActions.Channel.Id,
+				messageTimestamp: blockActions.Message.Timestamp,
+				userID:           blockActions.User.Id,
+			}, responder, actionAlert, []string{reaction})
+
+		// Show another page of the alert list.
+		case strings.HasPrefix(action.ActionID, slack.BlockActionID.AlertsPage):
+			s.showAlertsPage(responder, action)
+
+		// Confirm or cancel a silence matching many alerts.
+		case strings.HasPrefix(action.ActionID, slack.BlockActionID.SilencePreview):
+			s.handleSilencePreview(responder, action)
+
+		// Approve or reject a long or critical silence requested by another user.
+		case strings.HasPrefix(action.ActionID, slack.BlockActionID.SilenceApproval):
+			s.handleSilenceApproval(responder, action)
+
+		// Extend an expiring silence or let it expire.
+		case strings.HasPrefix(action.ActionID, slack.BlockActionID.SilenceReminder):
+			s.handleSilenceReminder(responder, action, blockActions.Message.ThreadTimestamp)
+
+		// Expire or keep a stale silence reported by the janitor.
+		case strings.HasPrefix(action.ActionID, slack.BlockActionID.StaleSilence):
+			s.handleStaleSilence(responder, action)
+
+		default:
+			responder.Logger.LogDebug("not responding to action", "actionID", action.ActionID)
+			responder.Respondf("Sorry, the action '%s' is unknown. (correlation ID: %s)", action.ActionID, responder.CorrelationID)
+		}
+	}
+}
+
+// showAlertsPage replaces the alert list with the requested page.
+func (s *Stargate) showAlertsPage(responder *slack.Responder, action slack.BlockAction) {
+	pageValue, err := slack.AlertsPageFromBlockAction(action)
+	if err != nil {
+		responder.Fail("failed to parse page from button", err)
+		return
+	}
+
+	cmd, err := slack.ParseCommand(pageValue.Command)
+	if err != nil {
+		responder.Fail("failed to parse command from button", err, "command", pageValue.Command)
+		return
+	}
+	if cmd.Name != slack.CommandName.Alerts {
+		responder.Fail("failed to parse command from button", fmt.Errorf("unexpected command '%s'", cmd.Name), "command", pageValue.Command)
+		return
+	}
+
+	text, blocks, err := s.alertListMessage(responder.UserID, cmd, pageValue.Page)
+	if err != nil {
+		responder.Fail("failed to list alerts", err)
+		return
+	}
+
+	if err := responder.ReplaceOriginal(text, blocks); err != nil {
+		responder.Fail("failed to show page", err)
+	}
+}
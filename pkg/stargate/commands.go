@@ -0,0 +1,412 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	slackapi "github.com/nlopes/slack"
+	"github.com/pkg/errors"
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/alert"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/metrics"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// commandContext is the context in which a command was issued via a mention, direct message or slash command.
+type commandContext struct {
+	// channel and threadTimestamp in which results are posted.
+	channel,
+	threadTimestamp string
+
+	// channelName is only known for slash commands. Defaults to the channel ID.
+	channelName string
+
+	userID,
+	userName string
+
+	// prefix used to invoke the stargate, e.g. '/stargate' or '@Stargate'.
+	prefix string
+
+	responder *slack.Responder
+}
+
+// commandContextFromSlashCommand returns the context of a slash command.
+func (s *Stargate) commandContextFromSlashCommand(slashCommand slackapi.SlashCommand) commandContext {
+	return commandContext{
+		channel:     slashCommand.ChannelID,
+		channelName: slashCommand.ChannelName,
+		userID:      slashCommand.UserID,
+		userName:    slashCommand.UserName,
+		prefix:      slashCommand.Command,
+		responder:   s.slack.NewResponder(slashCommand.ChannelID, slashCommand.UserID, slashCommand.ResponseURL),
+	}
+}
+
+// commandContextFromMessage returns the context of a message mentioning the stargate.
+// Results are posted in the thread if the message was a thread reply.
+func (s *Stargate) commandContextFromMessage(msg slack.Message) commandContext {
+	ctx := commandContext{
+		channel:     msg.Channel,
+		channelName: msg.Channel,
+		userID:      msg.UserID,
+		prefix:      fmt.Sprintf("@%s", s.Config.Slack.UserName),
+		responder:   s.slack.NewResponder(msg.Channel, msg.UserID, ""),
+	}
+	if msg.IsThreadReply() {
+		ctx.threadTimestamp = msg.ThreadTimestamp
+	}
+	return ctx
+}
+
+// handleSlackMessage handles messages mentioning the stargate and direct messages.
+// Replies in the thread of an alert are added as notes to the incident if synchronizing notes is enabled.
+func (s *Stargate) handleSlackMessage(msg slack.Message) {
+	text := msg.TextWithoutMentions()
+	if s.noteSync != nil && msg.IsThreadReply() && !slack.IsCommand(text) {
+		s.addNoteFromThreadReply(msg)
+		return
+	}
+	s.handleCommand(s.commandContextFromMessage(msg), text)
+}
+
+// handleCommand parses and executes a command. Usage errors are returned to the user.
+func (s *Stargate) handleCommand(ctx commandContext, text string) {
+	cmd, err := slack.ParseCommand(text)
+	if err != nil {
+		if usageErr, ok := err.(*slack.UsageError); ok {
+			ctx.responder.Respond(usageErr.Message(ctx.prefix))
+			return
+		}
+		ctx.responder.Fail("failed to parse command", err)
+		return
+	}
+	ctx.responder.Logger.LogDebug("handling command", "command", cmd.Name, "user", ctx.userID, "channel", ctx.channel)
+
+	switch cmd.Name {
+	case slack.CommandName.Alerts:
+		s.listAlertsCommand(ctx, cmd)
+	case slack.CommandName.Silences:
+		s.listSilencesCommand(ctx, cmd)
+	case slack.CommandName.Silence:
+		s.silenceCommand(ctx, cmd)
+	case slack.CommandName.Ack:
+		s.acknowledgeCommand(ctx, cmd)
+	case slack.CommandName.Expire:
+		s.expireSilenceCommand(ctx, cmd)
+	case slack.CommandName.Page:
+		s.pageOnCallFromCommand(ctx, cmd)
+	case slack.CommandName.Note:
+		s.addNoteFromCommand(ctx, cmd)
+	case slack.CommandName.WhoAmI:
+		s.whoAmI(ctx)
+	case slack.CommandName.Regions:
+		s.regionsCommand(ctx, cmd)
+	case slack.CommandName.Help:
+		ctx.responder.Respond("Available commands:\n" + slack.HelpText(ctx.prefix))
+	}
+}
+
+// listAlertsCommand posts the firing alerts as requested via 'alerts [region] [severity=<severity>] [<label>=<value>]'.
+func (s *Stargate) listAlertsCommand(ctx commandContext, cmd *slack.Command) {
+	text, blocks, err := s.alertListMessage(ctx.userID, cmd, 1)
+	if err != nil {
+		ctx.responder.Fail("failed to list alerts", err)
+		return
+	}
+
+	if blocks == nil {
+		s.slack.PostMessage(ctx.channel, text, ctx.threadTimestamp)
+		return
+	}
+
+	if _, err := s.slack.PostBlocks(ctx.channel, text, blocks, ctx.threadTimestamp); err != nil {
+		ctx.responder.Fail("failed to post alerts", err)
+	}
+}
+
+// alertListMessage returns the text and the blocks listing a page of the alerts matching the command.
+// No blocks are returned if there are no alerts.
+func (s *Stargate) alertListMessage(userID string, cmd *slack.Command, page int) (string, []slack.Block, error) {
+	labels := make(map[string]string, len(cmd.Labels)+2)
+	for k, v := range cmd.Labels {
+		labels[k] = v
+	}
+	if cmd.Region != "" {
+		labels[alertmanager.RegionLabel] = cmd.Region
+	}
+	if cmd.Severity != "" {
+		labels[alertmanager.SeverityLabel] = cmd.Severity
+	}
+
+	filter := alertmanager.NewDefaultFilter()
+	if len(labels) > 0 {
+		filter.WithAdditionalFilter(labels)
+	}
+
+	alertList, err := s.alertmanagerClient.ListAlerts(filter)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to list alerts from the alertmanager")
+	}
+
+	alertsBySeverity, err := alert.MapExtendedAlertsBySeverity(alertList)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to map alerts by severity")
+	}
+
+	switch {
+	case len(alertList) == 0 && cmd.Severity != "":
+		return fmt.Sprintf("Hey <@%s>, Relax! :green_heart:\nThere are no %s alerts %s.", userID, cmd.Severity, commandScope(cmd)), nil, nil
+	case len(alertList) == 0 || cmd.Severity == "" && alert.IsNoCriticalOrWarningAlerts(alertsBySeverity):
+		return fmt.Sprintf("Hey <@%s>, Relax! :green_heart:\nThere are no critical or warning alerts %s.", userID, commandScope(cmd)), nil, nil
+	}
+
+	text := fmt.Sprintf("Hey <@%s>, %d alert(s) %s:", userID, len(alertList), commandScope(cmd))
+	return text, slack.AlertListBlocks(text, alertsCommandText(cmd), s.withAcknowledgements(alertList), s.Config.SilencePresets, page), nil
+}
+
+// listSilencesCommand posts the active silences as requested via 'silences [region]'.
+func (s *Stargate) listSilencesCommand(ctx commandContext, cmd *slack.Command) {
+	filter := alertmanager.NewDefaultFilter()
+	if cmd.Region != "" {
+		filter.WithAdditionalFilter(map[string]string{alertmanager.RegionLabel: cmd.Region})
+	}
+
+	silenceList, err := s.alertmanagerClient.ListSilences(filter)
+	if err != nil {
+		ctx.responder.Fail("failed to list silences from the alertmanager", err)
+		return
+	}
+
+	activeSilences := make([]*types.Silence, 0, len(silenceList))
+	for _, sil := range silenceList {
+		if sil.Status.State == types.SilenceStateActive {
+			activeSilences = append(activeSilences, sil)
+		}
+	}
+	sort.Slice(activeSilences, func(i, j int) bool {
+		return activeSilences[i].EndsAt.Before(activeSilences[j].EndsAt)
+	})
+
+	if len(activeSilences) == 0 {
+		s.slack.PostMessage(ctx.channel, fmt.Sprintf("Hey <@%s>, there are no active silences %s.", ctx.userID, commandScope(cmd)), ctx.threadTimestamp)
+		return
+	}
+
+	msg := fmt.Sprintf("Hey <@%s>, %d active silence(s) %s:\n", ctx.userID, len(activeSilences), commandScope(cmd))
+	for _, sil := range activeSilences {
+		msg += fmt.Sprintf(
+			"• <%s|%s> `%s` ends in %s. Created by %s: %s\n",
+			s.alertmanagerClient.LinkToSilence(sil.ID), sil.ID, sil.Matchers.String(),
+			util.HumanizedDurationString(time.Until(sil.EndsAt)), sil.CreatedBy, sil.Comment,
+		)
+	}
+
+	s.slack.PostMessage(ctx.channel, msg, ctx.threadTimestamp)
+}
+
+// silenceCommand creates a silence as requested via 'silence <matchers> for <duration> because <reason>'.
+func (s *Stargate) silenceCommand(ctx commandContext, cmd *slack.Command) {
+	if !s.authorize(ctx.responder, policy.Request{Action: policy.Action.Silence, Labels: equalityMatcherLabels(cmd.Matchers), SilenceDuration: cmd.Duration}) {
+		return
+	}
+
+	matchers := make(types.Matchers, 0, len(cmd.Matchers))
+	for _, m := range cmd.Matchers {
+		matchers = append(matchers, &types.Matcher{Name: m.Name, Value: m.Value, IsRegex: m.IsRegex})
+	}
+
+	userName := s.commandUserName(ctx)
+	s.requestSilence(&silenceRequest{
+		matchers:        matchers,
+		endsAt:          time.Now().Add(cmd.Duration),
+		author:          userName,
+		comment:         cmd.Reason,
+		subject:         fmt.Sprintf("`%s`", matchers.String()),
+		reason:          cmd.Reason,
+		userID:          ctx.userID,
+		channel:         ctx.channel,
+		threadTimestamp: ctx.threadTimestamp,
+		location:        s.userLocation(ctx.userID),
+	}, ctx.responder)
+}
+
+// acknowledgeCommand acknowledges an alert as requested via 'ack <alertname> <region>'.
+func (s *Stargate) acknowledgeCommand(ctx commandContext, cmd *slack.Command) {
+	ackAlert := alertFromAlertnameAndRegion(cmd.Alertname, cmd.Region)
+	alertLabelSets, err := s.matchingAlertLabels(ackAlert)
+	if err != nil {
+		ctx.responder.Fail("failed to authorize the action", err)
+		return
+	}
+	if !s.authorizeLabelSets(ctx.responder, policy.Request{Action: policy.Action.Acknowledge}, alertLabelSets) {
+		return
+	}
+
+	if !s.acknowledgeAlert(ackAlert, cmd.Alertname, s.commandUserName(ctx), ctx.responder) {
+		return
+	}
+
+	s.slack.PostMessage(
+		ctx.channel,
+		fmt.Sprintf("<@%s> acknowledged alert %s in %s.", ctx.userID, cmd.Alertname, cmd.Region),
+		ctx.threadTimestamp,
+	)
+}
+
+// expireSilenceCommand expires a silence as requested via 'expire <silenceID>'.
+func (s *Stargate) expireSilenceCommand(ctx commandContext, cmd *slack.Command) {
+	sil, err := s.alertmanagerClient.GetSilenceByID(cmd.SilenceID)
+	if err != nil {
+		ctx.responder.Fail(fmt.Sprintf("failed to get silence %s", cmd.SilenceID), err, "component", "alertmanager")
+		return
+	}
+
+	if !s.authorize(ctx.responder, policy.Request{Action: policy.Action.ExpireSilence, Labels: matcherLabels(sil.Matchers)}) {
+		return
+	}
+
+	if err := s.alertmanagerClient.ExpireSilence(sil.ID); err != nil {
+		ctx.responder.Fail(fmt.Sprintf("failed to expire silence %s", sil.ID), err, "component", "alertmanager")
+		metrics.FailedOperationsTotal.WithLabelValues("expire_silence", metrics.BackendAlertmanager).Inc()
+		return
+	}
+	metrics.SuccessfulOperationsTotal.WithLabelValues("expire_silence", metrics.BackendAlertmanager).Inc()
+
+	s.slack.PostMessage(
+		ctx.channel,
+		fmt.Sprintf("<@%s> expired silence <%s|%s> `%s`.", ctx.userID, s.alertmanagerClient.LinkToSilence(sil.ID), sil.ID, sil.Matchers.String()),
+		ctx.threadTimestamp,
+	)
+	ctx.responder.Respondf("Expired silence %s.", sil.ID)
+}
+
+// regionsCommand shows or sets the regions shown in the App Home as requested via 'regions [<region>...|all]'.
+func (s *Stargate) regionsCommand(ctx commandContext, cmd *slack.Command) {
+	if len(cmd.Regions) == 0 {
+		regions := s.preferences.Get(ctx.userID).Regions
+		if len(regions) == 0 {
+			ctx.responder.Respond("Your App Home shows all regions.")
+			return
+		}
+		ctx.responder.Respondf("Your App Home shows the regions %s.", strings.Join(regions, ", "))
+		return
+	}
+
+	regions := cmd.Regions
+	if len(regions) == 1 && regions[0] == "all" {
+		regions = nil
+	}
+
+	if err := s.preferences.SetRegions(ctx.userID, regions); err != nil {
+		ctx.responder.Fail("failed to save your regions", err)
+		return
+	}
+
+	if err := s.publishAppHome(ctx.userID); err != nil {
+		ctx.responder.Logger.LogError("failed to publish app home", err, "userID", ctx.userID)
+	}
+
+	if len(regions) == 0 {
+		ctx.responder.Respond("Your App Home now shows all regions.")
+		return
+	}
+	ctx.responder.Respondf("Your App Home now shows the regions %s.", strings.Join(regions, ", "))
+}
+
+// commandUserName returns the name of the Slack user issuing the command.
+func (s *Stargate) commandUserName(ctx commandContext) string {
+	userName, err := s.slack.GetUserNameByID(ctx.userID)
+	if err == nil && userName != "" {
+		return userName
+	}
+	ctx.responder.Logger.LogDebug("user not found by id", "userID", ctx.userID)
+	if ctx.userName != "" {
+		return ctx.userName
+	}
+	return ctx.userID
+}
+
+// commandScope describes the region and filters of a command, e.g. 'in eu-de-1 matching service=nova'.
+func commandScope(cmd *slack.Command) string {
+	scope := "in all regions"
+	if cmd.Region != "" {
+		scope = fmt.Sprintf("in %s", cmd.Region)
+	}
+
+	filters := make([]string, 0, len(cmd.Labels))
+	for k, v := range cmd.Labels {
+		filters = append(filters, fmt.Sprintf("%s=%s", k, v))
+	}
+	if len(filters) == 0 {
+		return scope
+	}
+	sort.Strings(filters)
+	return fmt.Sprintf("%s matching %s", scope, strings.Join(filters, ", "))
+}
+
+// alertsCommandText returns the alerts command with the region and filters of the given command.
+func alertsCommandText(cmd *slack.Command) string {
+	fields := []string{slack.CommandName.Alerts}
+	if cmd.Region != "" {
+		fields = append(fields, cmd.Region)
+	}
+	if cmd.Severity != "" {
+		fields = append(fields, fmt.Sprintf("severity=%s", cmd.Severity))
+	}
+
+	filters := make([]string, 0, len(cmd.Labels))
+	for k, v := range cmd.Labels {
+		filters = append(filters, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(filters)
+	return strings.Join(append(fields, filters...), " ")
+}
+
+// equalityMatcherLabels returns the labels of the equality matchers.
+// Regex matchers are ignored, so scoped policies only permit silences with exact label values.
+func equalityMatcherLabels(matchers []slack.Matcher) map[string]string {
+	labels := make(map[string]string, len(matchers))
+	for _, m := range matchers {
+		if !m.IsRegex {
+			labels[m.Name] = m.Value
+		}
+	}
+	return labels
+}
+
+// alertFromAlertnameAndRegion returns an alert with the given alertname and region labels.
+func alertFromAlertnameAndRegion(alertname, region string) *client.ExtendedAlert {
+	return &client.ExtendedAlert{
+		Alert: client.Alert{
+			Labels: client.LabelSet{
+				"alertname": client.LabelValue(alertname),
+				client.LabelName(alertmanager.RegionLabel): client.LabelValue(region),
+			},
+		},
+	}
+}
@@ -0,0 +1,167 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/metrics"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// silenceEndFormat is used to show the end of a silence in the time zone of the user.
+const silenceEndFormat = "Mon, 02 Jan 15:04 MST"
+
+// silenceRequest is a silence requested via button, reaction, dialog or command.
+type silenceRequest struct {
+	matchers types.Matchers
+	endsAt   time.Time
+	author,
+	comment string
+
+	// subject describes what is silenced, e.g. 'alert NodeDown' or '`{region="eu-de-1"}`'.
+	subject string
+	// reason is shown next to the subject if given.
+	reason string
+
+	// The outcome is posted to the thread of the channel.
+	userID,
+	channel,
+	threadTimestamp string
+	// addReaction marks the message of the thread as silenced.
+	addReaction bool
+	location    *time.Location
+
+	// severities of the firing alerts matched by the silence.
+	severities []string
+	// approvalTimestamp of the message requesting approval of the silence.
+	approvalTimestamp string
+}
+
+// requestSilence creates the silence or asks the user for confirmation first if it matches too many alerts.
+// Long or critical silences need to be approved by another user.
+// Returns false if the silence was neither created, previewed nor requested.
+func (s *Stargate) requestSilence(req *silenceRequest, responder *slack.Responder) bool {
+	if !s.Config.SilenceGuardrail.Disabled || len(s.Config.SilenceApproval.Severities) > 0 {
+		blastRadius, err := s.alertmanagerClient.GetBlastRadius(req.matchers)
+		if err != nil {
+			responder.Fail("failed to evaluate the silence", err, "component", "alertmanager")
+			return false
+		}
+		req.severities = blastRadius.Severities
+
+		if !s.Config.SilenceGuardrail.Disabled && blastRadius.IsExceeding(s.Config.SilenceGuardrail.MaxAlerts) {
+			id := util.NewCorrelationID()
+			s.pendingSilences.add(id, req)
+			header := fmt.Sprintf(
+				"Silencing %s `%s` until %s: %s. Please confirm.",
+				req.subject, req.matchers.String(), req.endsAt.In(req.location).Format(silenceEndFormat), blastRadius.Summary(),
+			)
+			responder.RespondBlocks(header, slack.SilencePreviewBlocks(header, blastRadius, id))
+			return true
+		}
+	}
+
+	message, ok := s.submitSilence(req, responder)
+	if ok {
+		responder.Respond(message)
+	}
+	return ok
+}
+
+// submitSilence creates the silence or requests approval by another user if required.
+// Returns the message for the acting user and false if the silence was neither created nor requested.
+func (s *Stargate) submitSilence(req *silenceRequest, responder *slack.Responder) (string, bool) {
+	if s.requiresApproval(req) {
+		return s.requestApproval(req, responder)
+	}
+	return s.createSilence(req, responder)
+}
+
+// createSilence creates the silence and posts the outcome to the thread.
+// Returns the message for the acting user and false if the silence was not created.
+func (s *Stargate) createSilence(req *silenceRequest, responder *slack.Responder) (string, bool) {
+	// The origin is used to remind the user before the silence expires.
+	origin := silence.Origin{UserID: req.userID, Channel: req.channel, ThreadTimestamp: req.threadTimestamp}
+	comment := fmt.Sprintf("%s\n%s", req.comment, origin.Marker())
+
+	result, err := s.alertmanagerClient.CreateSilenceWithMatchers(req.matchers, req.author, comment, time.Until(req.endsAt))
+	if err != nil {
+		responder.Fail("failed to create silence", err, "component", "alertmanager")
+		metrics.FailedOperationsTotal.WithLabelValues("silence", metrics.BackendAlertmanager).Inc()
+		return "", false
+	}
+	metrics.SuccessfulOperationsTotal.WithLabelValues("silence", metrics.BackendAlertmanager).Inc()
+
+	// An existing silence might last longer than requested.
+	until := fmt.Sprintf("for %s until %s", util.HumanizedDurationString(time.Until(result.EndsAt)), result.EndsAt.In(req.location).Format(silenceEndFormat))
+	if req.reason != "" {
+		until += ": " + req.reason
+	}
+	note := s.silenceResultNote(result, req.location)
+	link := fmt.Sprintf("<%s|See Silence>", s.alertmanagerClient.LinkToSilence(result.ID))
+
+	s.slack.PostMessage(
+		req.channel,
+		fmt.Sprintf("<@%s> silenced %s %s.%s %s", req.userID, req.subject, until, note, link),
+		req.threadTimestamp,
+	)
+	if req.addReaction {
+		s.slack.AddReactionToMessage(req.channel, req.threadTimestamp, slack.SilenceSuccessReactionEmoji)
+	}
+
+	return fmt.Sprintf("Silenced %s %s.%s %s", req.subject, until, note, link), true
+}
+
+// replaceOrRespond replaces the message the user interacted with or responds if that is not possible.
+func (s *Stargate) replaceOrRespond(responder *slack.Responder, message string) {
+	if err := responder.ReplaceOriginal(message, nil); err != nil {
+		responder.Logger.LogDebug("failed to replace original message. responding instead", "err", err)
+		responder.Respond(message)
+	}
+}
+
+// silenceResultNote explains whether an existing silence was extended or kept and lists overlapping silences.
+func (s *Stargate) silenceResultNote(result *alertmanager.SilenceResult, location *time.Location) string {
+	var note string
+	switch result.Status {
+	case alertmanager.SilenceStatus.Extended:
+		note += " Extended the existing silence."
+	case alertmanager.SilenceStatus.Unchanged:
+		note += " An existing silence already lasts longer."
+	}
+
+	for _, o := range result.Overlaps {
+		scope := "fewer"
+		if o.IsBroader {
+			scope = "more"
+		}
+		note += fmt.Sprintf(
+			" Overlaps with silence <%s|%s> `%s` matching %s alerts until %s.",
+			s.alertmanagerClient.LinkToSilence(o.Silence.ID), o.Silence.ID, o.Silence.Matchers.String(), scope, o.Silence.EndsAt.In(location).Format(silenceEndFormat),
+		)
+	}
+	return note
+}
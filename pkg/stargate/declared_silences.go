@@ -0,0 +1,266 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/metrics"
+	"github.com/sapcc/stargate/pkg/silence"
+)
+
+// driftKind describes how the silences in the Alertmanager differ from the declared ones.
+var driftKind = struct {
+	Missing,
+	Changed,
+	Duplicate,
+	Ended,
+	Removed string
+}{
+	"missing",
+	"changed",
+	"duplicate",
+	"ended",
+	"removed",
+}
+
+// silenceDrift is a difference between a declared silence and the Alertmanager found during a reconciliation.
+type silenceDrift struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	SilenceID string `json:"silenceID,omitempty"`
+	// Error if the drift could not be fixed.
+	Error string `json:"error,omitempty"`
+}
+
+// declaredSilencesStatus is the result of the last reconciliation of the declared silences.
+type declaredSilencesStatus struct {
+	ReconciledAt time.Time      `json:"reconciledAt"`
+	Declared     int            `json:"declared"`
+	Drift        []silenceDrift `json:"drift"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// declaredSilences reconciles the silences declared in the configured directory.
+type declaredSilences struct {
+	mtx    sync.RWMutex
+	status declaredSilencesStatus
+}
+
+func (d *declaredSilences) setStatus(status declaredSilencesStatus) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.status = status
+}
+
+func (d *declaredSilences) getStatus() declaredSilencesStatus {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return d.status
+}
+
+// runDeclaredSilencesReconciliation periodically reconciles the declared silences.
+// The directory is polled in the configured interval, so changed files are picked up with the next reconciliation.
+func (s *Stargate) runDeclaredSilencesReconciliation(stopCh <-chan struct{}) {
+	s.logger.LogInfo("reconciling declared silences", "directory", s.Config.DeclaredSilences.Directory, "interval", s.Config.DeclaredSilences.Interval.String())
+	s.reconcileDeclaredSilences()
+
+	ticker := time.NewTicker(s.Config.DeclaredSilences.Interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.reconcileDeclaredSilences()
+		case <-stopCh:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// reconcileDeclaredSilences creates missing declared silences, updates changed ones and expires the ones whose file was removed.
+// Only silences tagged with the marker of a declared silence are owned by the reconciler.
+// Nothing is changed if any declared silence is invalid, so a broken file does not expire the silences.
+func (s *Stargate) reconcileDeclaredSilences() {
+	status := declaredSilencesStatus{ReconciledAt: time.Now(), Drift: make([]silenceDrift, 0)}
+	defer func() {
+		s.declaredSilences.setStatus(status)
+		driftCount := map[string]float64{
+			driftKind.Missing:   0,
+			driftKind.Changed:   0,
+			driftKind.Duplicate: 0,
+			driftKind.Ended:     0,
+			driftKind.Removed:   0,
+		}
+		for _, drift := range status.Drift {
+			driftCount[drift.Kind]++
+		}
+		for kind, count := range driftCount {
+			metrics.DeclaredSilencesDrift.WithLabelValues(kind).Set(count)
+		}
+		if status.Error != "" {
+			metrics.DeclaredSilencesReconcileErrorsTotal.Inc()
+		}
+	}()
+
+	declared, err := silence.LoadDeclaredSilences(s.Config.DeclaredSilences.Directory)
+	if err != nil {
+		s.logger.LogError("failed to load declared silences", err, "directory", s.Config.DeclaredSilences.Directory)
+		status.Error = err.Error()
+		return
+	}
+	status.Declared = len(declared)
+	metrics.DeclaredSilences.Set(float64(len(declared)))
+
+	silences, err := s.alertmanagerClient.ListSilences(alertmanager.NewDefaultFilter())
+	if err != nil {
+		s.logger.LogError("failed to list silences", err)
+		status.Error = err.Error()
+		return
+	}
+
+	// owned maps the name of a declared silence to its active or pending silences.
+	owned := make(map[string][]*types.Silence)
+	for _, sil := range silences {
+		if sil.Status.State == types.SilenceStateExpired {
+			continue
+		}
+		if name, ok := silence.ParseDeclaredMarker(sil.Comment); ok {
+			owned[name] = append(owned[name], sil)
+		}
+	}
+
+	now := time.Now()
+	for _, d := range declared {
+		existing := owned[d.Name]
+		delete(owned, d.Name)
+
+		// Silences of declarations which already ended are expired.
+		if !d.EndsAt.After(now) {
+			for _, sil := range existing {
+				status.Drift = append(status.Drift, s.expireDeclaredSilence(d.Name, driftKind.Ended, sil))
+			}
+			continue
+		}
+
+		if len(existing) == 0 {
+			status.Drift = append(status.Drift, s.createDeclaredSilence(d))
+			continue
+		}
+
+		// Duplicates, e.g. created by several instances of the stargate, are expired.
+		// The oldest silence is kept, so all instances agree on it.
+		sortSilencesByStart(existing)
+		for _, sil := range existing[1:] {
+			status.Drift = append(status.Drift, s.expireDeclaredSilence(d.Name, driftKind.Duplicate, sil))
+		}
+		if !isDeclaredSilence(existing[0], d) {
+			status.Drift = append(status.Drift, s.updateDeclaredSilence(d, existing[0]))
+		}
+	}
+
+	// The files of the remaining silences were removed.
+	for name, sils := range owned {
+		for _, sil := range sils {
+			status.Drift = append(status.Drift, s.expireDeclaredSilence(name, driftKind.Removed, sil))
+		}
+	}
+
+	for _, drift := range status.Drift {
+		if drift.Error != "" {
+			status.Error = "failed to fix drift of declared silences"
+			break
+		}
+	}
+}
+
+// sortSilencesByStart sorts the silences by their start and ID.
+func sortSilencesByStart(silences []*types.Silence) {
+	sort.Slice(silences, func(i, j int) bool {
+		if !silences[i].StartsAt.Equal(silences[j].StartsAt) {
+			return silences[i].StartsAt.Before(silences[j].StartsAt)
+		}
+		return silences[i].ID < silences[j].ID
+	})
+}
+
+// isDeclaredSilence checks whether the silence matches its declaration.
+func isDeclaredSilence(sil *types.Silence, d silence.DeclaredSilence) bool {
+	return types.NewMatchers(sil.Matchers...).String() == d.SilenceMatchers().String() &&
+		sil.EndsAt.Unix() == d.EndsAt.Unix() &&
+		sil.CreatedBy == d.Owner &&
+		sil.Comment == d.RenderComment()
+}
+
+func (s *Stargate) createDeclaredSilence(d silence.DeclaredSilence) silenceDrift {
+	drift := silenceDrift{Name: d.Name, Kind: driftKind.Missing}
+	silenceID, err := s.alertmanagerClient.CreateScheduledSilence(types.Silence{
+		Matchers:  d.SilenceMatchers(),
+		StartsAt:  time.Now().UTC(),
+		EndsAt:    d.EndsAt,
+		CreatedBy: d.Owner,
+		Comment:   d.RenderComment(),
+	})
+	if err != nil {
+		s.logger.LogError("failed to create declared silence", err, "name", d.Name)
+		metrics.FailedOperationsTotal.WithLabelValues("create_silence", metrics.BackendAlertmanager).Inc()
+		drift.Error = err.Error()
+		return drift
+	}
+	metrics.SuccessfulOperationsTotal.WithLabelValues("create_silence", metrics.BackendAlertmanager).Inc()
+	drift.SilenceID = silenceID
+	return drift
+}
+
+func (s *Stargate) updateDeclaredSilence(d silence.DeclaredSilence, sil *types.Silence) silenceDrift {
+	drift := silenceDrift{Name: d.Name, Kind: driftKind.Changed, SilenceID: sil.ID}
+	silenceID, err := s.alertmanagerClient.UpdateSilence(types.Silence{
+		ID:        sil.ID,
+		Matchers:  d.SilenceMatchers(),
+		StartsAt:  sil.StartsAt,
+		EndsAt:    d.EndsAt,
+		CreatedBy: d.Owner,
+		Comment:   d.RenderComment(),
+	})
+	if err != nil {
+		s.logger.LogError("failed to update declared silence", err, "name", d.Name, "silenceID", sil.ID)
+		metrics.FailedOperationsTotal.WithLabelValues("update_silence", metrics.BackendAlertmanager).Inc()
+		drift.Error = err.Error()
+		return drift
+	}
+	metrics.SuccessfulOperationsTotal.WithLabelValues("update_silence", metrics.BackendAlertmanager).Inc()
+	drift.SilenceID = silenceID
+	return drift
+}
+
+func (s *Stargate) expireDeclaredSilence(name, kind string, sil *types.Silence) silenceDrift {
+	drift := silenceDrift{Name: name, Kind: kind, SilenceID: sil.ID}
+	if err := s.alertmanagerClient.ExpireSilence(sil.ID); err != nil {
+		s.logger.LogError("failed to expire declared silence", err, "name", name, "silenceID", sil.ID)
+		metrics.FailedOperationsTotal.WithLabelValues("expire_silence", metrics.BackendAlertmanager).Inc()
+		drift.Error = err.Error()
+		return drift
+	}
+	metrics.SuccessfulOperationsTotal.WithLabelValues("expire_silence", metrics.BackendAlertmanager).Inc()
+	return drift
+}
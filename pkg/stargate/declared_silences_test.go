@@ -0,0 +1,89 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileDuplicateAndEndedDeclaredSilences(t *testing.T) {
+	dir, err := ioutil.TempDir("", "declared")
+	require.NoError(t, err, "creating a temporary directory must not raise an error")
+	defer os.RemoveAll(dir)
+
+	endsAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	files := map[string]string{
+		"legacy.yaml": "matchers: [{name: service, value: legacy}]\nends_at: " + endsAt.Format(time.RFC3339) + "\nreason: decommissioned\nowner: team-a\n",
+		"ended.yaml":  "matchers: [{name: service, value: ended}]\nends_at: 2019-01-01T00:00:00Z\nreason: done\nowner: team-a\n",
+	}
+	for fileName, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, fileName), []byte(content), 0644), "writing the file must not raise an error")
+	}
+
+	s := newTestStargate(func(cfg *config.Config) {
+		cfg.DeclaredSilences.Enabled = true
+		cfg.DeclaredSilences.Directory = dir
+	})
+	defer s.close()
+	s.declaredSilences = &declaredSilences{}
+
+	startsAt := time.Now().Add(-time.Hour).UTC()
+	newSilence := func(id, name string, startsAt time.Time, endsAt time.Time, reason string) *types.Silence {
+		sil := &types.Silence{
+			ID:        id,
+			Matchers:  types.Matchers{{Name: "service", Value: name}},
+			StartsAt:  startsAt,
+			EndsAt:    endsAt,
+			CreatedBy: "team-a",
+			Comment:   reason + "\n[stargate declared: name=" + name + "]",
+		}
+		sil.Status.State = types.SilenceStateActive
+		return sil
+	}
+	// The oldest silence is kept regardless of the order returned by the Alertmanager.
+	s.alertmanagerAPI.addSilence(newSilence("silence-b", "legacy", startsAt, endsAt, "decommissioned"))
+	s.alertmanagerAPI.addSilence(newSilence("silence-a", "legacy", startsAt, endsAt, "decommissioned"))
+	s.alertmanagerAPI.addSilence(newSilence("silence-c", "legacy", startsAt.Add(-time.Hour), endsAt, "decommissioned"))
+	s.alertmanagerAPI.addSilence(newSilence("silence-d", "ended", startsAt, time.Now().Add(time.Hour), "done"))
+
+	s.reconcileDeclaredSilences()
+
+	status := s.declaredSilences.getStatus()
+	assert.Empty(t, status.Error, "the drift should be fixed")
+	assert.ElementsMatch(t, []silenceDrift{
+		{Name: "legacy", Kind: driftKind.Duplicate, SilenceID: "silence-a"},
+		{Name: "legacy", Kind: driftKind.Duplicate, SilenceID: "silence-b"},
+		{Name: "ended", Kind: driftKind.Ended, SilenceID: "silence-d"},
+	}, status.Drift, "the duplicates and the silence of the ended declaration should be reported")
+
+	assert.Equal(t, types.SilenceStateActive, s.alertmanagerAPI.silence("silence-c").Status.State, "the oldest silence should be kept")
+	for _, id := range []string{"silence-a", "silence-b", "silence-d"} {
+		assert.Equal(t, types.SilenceStateExpired, s.alertmanagerAPI.silence(id).Status.State, "the silence %s should be expired", id)
+	}
+}
@@ -17,27 +17,14 @@
 *
 *******************************************************************************/
 
-package slack
+package stargate
 
-import "strings"
+import (
+	"net/http"
+)
 
-// Action struct for available actions that can be triggered
-var Action = struct {
-	ShowAlerts string
-}{
-	"showAlerts",
-}
-
-// commandActions mapping of action to keywords (commands)
-var commandActions = map[string][]string{
-	Action.ShowAlerts: {"show", "alerts"},
-}
-
-func textContainsAllKeyWords(text string, keywords []string) bool {
-	for _, k := range keywords {
-		if !strings.Contains(text, k) {
-			return false
-		}
-	}
-	return true
+// HandleGetDeclaredSilences handles getting the result of the last reconciliation of the declared silences.
+func (s *Stargate) HandleGetDeclaredSilences(w http.ResponseWriter, r *http.Request) {
+	s.respondWithJSON(w, s.declaredSilences.getStatus())
+	s.logger.LogDebug("responding to request", "handler", "getDeclaredSilences")
 }
@@ -26,16 +26,22 @@ import (
 	"github.com/sapcc/stargate/pkg/api"
 )
 
-// HandleInternalListPagerdutyIncident handles listing the pagerduty incidents.
-func (s *Stargate) HandleInternalListPagerdutyIncident(w http.ResponseWriter, r *http.Request) {
-	incidentList, err := s.pagerdutyClient.ListParsedIncidents()
+// HandleInternalListPagerIncidents handles listing the incidents of the configured pager.
+func (s *Stargate) HandleInternalListPagerIncidents(w http.ResponseWriter, r *http.Request) {
+	if s.pager == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(api.Error{Code: http.StatusNotFound, Message: "pager integration disabled"})
+		return
+	}
+
+	incidentList, err := s.pager.ListIncidents()
 	if err != nil {
-		s.logger.LogError("error listing incidents from pagerduty", err)
+		s.logger.LogError("error listing incidents from pager", err, "pager", s.Config.Pager)
 		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(api.Error{Code: http.StatusInternalServerError, Message: "error listing incidents from pagerduty"})
+		json.NewEncoder(w).Encode(api.Error{Code: http.StatusInternalServerError, Message: "error listing incidents from " + s.Config.Pager})
 		return
 	}
 
 	s.respondWithJSON(w, incidentList)
-	s.logger.LogDebug("responding to request", "handler", "internalListAlertsFromAlertmanager")
+	s.logger.LogDebug("responding to request", "handler", "internalListPagerIncidents")
 }
@@ -0,0 +1,51 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sapcc/stargate/pkg/silence"
+)
+
+// maintenanceWindowStatus is a maintenance window and its current or upcoming occurrence.
+type maintenanceWindowStatus struct {
+	silence.MaintenanceWindow
+	NextStartsAt *time.Time `json:"nextStartsAt,omitempty"`
+	NextEndsAt   *time.Time `json:"nextEndsAt,omitempty"`
+}
+
+// HandleListMaintenanceWindows handles listing the maintenance windows.
+func (s *Stargate) HandleListMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	windows := s.maintenance.list()
+	statusList := make([]maintenanceWindowStatus, 0, len(windows))
+	for _, mw := range windows {
+		status := maintenanceWindowStatus{MaintenanceWindow: mw}
+		if start, end, ok := mw.Next(now); ok {
+			status.NextStartsAt, status.NextEndsAt = &start, &end
+		}
+		statusList = append(statusList, status)
+	}
+
+	s.respondWithJSON(w, statusList)
+	s.logger.LogDebug("responding to request", "handler", "listMaintenanceWindows")
+}
@@ -0,0 +1,193 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/metrics"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/sapcc/stargate/pkg/store"
+)
+
+// maintenanceWindows are the windows silences are created for.
+// Windows added via the API are persisted and replace configured windows of the same name, also after a restart.
+type maintenanceWindows struct {
+	store *store.MaintenanceStore
+
+	mtx     sync.RWMutex
+	windows map[string]silence.MaintenanceWindow
+}
+
+func newMaintenanceWindows(windows []silence.MaintenanceWindow, maintenanceStore *store.MaintenanceStore) *maintenanceWindows {
+	m := &maintenanceWindows{
+		store:   maintenanceStore,
+		windows: make(map[string]silence.MaintenanceWindow, len(windows)),
+	}
+	for _, w := range windows {
+		m.windows[w.Name] = w
+	}
+	for _, w := range maintenanceStore.List() {
+		m.windows[w.Name] = w
+	}
+	return m
+}
+
+// newMaintenanceWindows returns the configured windows and the windows persisted in the `--maintenance-file`.
+func (s *Stargate) newMaintenanceWindows() *maintenanceWindows {
+	maintenanceStore, err := store.NewMaintenanceStore(s.opts.MaintenanceFilePath, s.logger)
+	if err != nil {
+		s.logger.LogFatal("failed to load maintenance windows", "err", err)
+	}
+	return newMaintenanceWindows(s.Config.Maintenance.Windows, maintenanceStore)
+}
+
+// set persists and adds or replaces the validated window.
+func (m *maintenanceWindows) set(w silence.MaintenanceWindow) error {
+	if err := m.store.Set(w); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.windows[w.Name] = w
+	return nil
+}
+
+// list returns the windows sorted by name.
+func (m *maintenanceWindows) list() []silence.MaintenanceWindow {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	windows := make([]silence.MaintenanceWindow, 0, len(m.windows))
+	for _, w := range m.windows {
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Name < windows[j].Name
+	})
+	return windows
+}
+
+// runMaintenance periodically creates the silences of upcoming maintenance windows.
+func (s *Stargate) runMaintenance(stopCh <-chan struct{}) {
+	s.logger.LogInfo("scheduling silences of maintenance windows", "interval", s.Config.Maintenance.Interval.String())
+	ticker := time.NewTicker(s.Config.Maintenance.Interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.scheduleMaintenanceSilences()
+		case <-stopCh:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// scheduleMaintenanceSilences creates a silence for the current or upcoming occurrence of each window once it starts within the lead time.
+// Silences of a window left over from previous occurrences or whose window changed are expired.
+// Silences of windows which are no longer known are left alone.
+func (s *Stargate) scheduleMaintenanceSilences() {
+	silences, err := s.alertmanagerClient.ListSilences(alertmanager.NewDefaultFilter())
+	if err != nil {
+		s.logger.LogError("failed to list silences", err)
+		return
+	}
+
+	// scheduled maps the name of a window to its active or pending silences.
+	scheduled := make(map[string][]*types.Silence)
+	for _, sil := range silences {
+		if sil.Status.State == types.SilenceStateExpired {
+			continue
+		}
+		if name, _, ok := silence.ParseMaintenanceMarker(sil.Comment); ok {
+			scheduled[name] = append(scheduled[name], sil)
+		}
+	}
+
+	now := time.Now()
+	for _, w := range s.maintenance.list() {
+		start, end, ok := w.Next(now)
+
+		isScheduled := false
+		for _, sil := range scheduled[w.Name] {
+			if ok && isMaintenanceSilence(sil, w, start, end) {
+				isScheduled = true
+				continue
+			}
+			s.expireMaintenanceSilence(sil, w)
+		}
+
+		if !ok || isScheduled || start.After(now.Add(s.Config.Maintenance.LeadTime)) {
+			continue
+		}
+		s.createMaintenanceSilence(w, start, end)
+	}
+}
+
+// isMaintenanceSilence checks whether the silence was created for the occurrence of the window with its current matchers.
+func isMaintenanceSilence(sil *types.Silence, w silence.MaintenanceWindow, start, end time.Time) bool {
+	_, silenceStart, _ := silence.ParseMaintenanceMarker(sil.Comment)
+	return silenceStart.Unix() == start.Unix() &&
+		sil.EndsAt.Unix() == end.Unix() &&
+		types.NewMatchers(sil.Matchers...).String() == w.SilenceMatchers().String()
+}
+
+func (s *Stargate) createMaintenanceSilence(w silence.MaintenanceWindow, start, end time.Time) {
+	silenceID, err := s.alertmanagerClient.CreateScheduledSilence(types.Silence{
+		Matchers:  w.SilenceMatchers(),
+		StartsAt:  start,
+		EndsAt:    end,
+		CreatedBy: s.Config.Slack.UserName,
+		Comment:   w.RenderComment(start),
+	})
+	if err != nil {
+		s.logger.LogError("failed to create silence of maintenance window", err, "window", w.Name)
+		metrics.FailedOperationsTotal.WithLabelValues("create_silence", metrics.BackendAlertmanager).Inc()
+		return
+	}
+	metrics.SuccessfulOperationsTotal.WithLabelValues("create_silence", metrics.BackendAlertmanager).Inc()
+
+	s.slack.PostMessage(s.Config.Maintenance.Channel, fmt.Sprintf(
+		"Silenced `%s` for maintenance window *%s* from %s until %s: <%s|%s>",
+		w.SilenceMatchers().String(), w.Name,
+		start.In(s.calendar.Location).Format(silenceEndFormat), end.In(s.calendar.Location).Format(silenceEndFormat),
+		s.alertmanagerClient.LinkToSilence(silenceID), silenceID,
+	), "")
+}
+
+func (s *Stargate) expireMaintenanceSilence(sil *types.Silence, w silence.MaintenanceWindow) {
+	if err := s.alertmanagerClient.ExpireSilence(sil.ID); err != nil {
+		s.logger.LogError("failed to expire silence of maintenance window", err, "window", w.Name, "silenceID", sil.ID)
+		metrics.FailedOperationsTotal.WithLabelValues("expire_silence", metrics.BackendAlertmanager).Inc()
+		return
+	}
+	metrics.SuccessfulOperationsTotal.WithLabelValues("expire_silence", metrics.BackendAlertmanager).Inc()
+
+	s.slack.PostMessage(s.Config.Maintenance.Channel, fmt.Sprintf(
+		"Expired silence <%s|%s> `%s` left over from a previous occurrence or definition of maintenance window *%s*.",
+		s.alertmanagerClient.LinkToSilence(sil.ID), sil.ID, sil.Matchers.String(), w.Name,
+	), "")
+}
@@ -0,0 +1,311 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/alertmanager/client"
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/metrics"
+	"github.com/sapcc/stargate/pkg/pager"
+	"github.com/sapcc/stargate/pkg/slack"
+)
+
+const (
+	// slackNotePrefix marks notes added from Slack, so they are not mirrored back to the thread.
+	slackNotePrefix = "[Slack] "
+
+	// noteThreadTTL is the time after which a thread is no longer synchronized.
+	noteThreadTTL = 24 * time.Hour
+)
+
+// noteThread is a Slack thread synchronized with the notes of an incident.
+type noteThread struct {
+	channel,
+	threadTimestamp string
+	seenNoteIDs map[string]bool
+	expiresAt   time.Time
+}
+
+// noteSync mirrors the notes of incidents to the Slack thread of the corresponding alert.
+type noteSync struct {
+	logger     log.Logger
+	noteSyncer pager.NoteSyncer
+	slack      *slack.Client
+	interval   time.Duration
+
+	threadsMutex sync.Mutex
+	// threads maps the ID of an incident to the synchronized thread.
+	threads map[string]*noteThread
+}
+
+// newNoteSync returns a noteSync if enabled and supported by the pager or nil.
+func newNoteSync(s *Stargate) *noteSync {
+	if s.pager == nil || !s.Config.Pagerduty.SyncNotes {
+		return nil
+	}
+
+	noteSyncer, ok := s.pager.(pager.NoteSyncer)
+	if !ok {
+		s.logger.LogWarn("synchronizing notes is not supported by the pager", "pager", s.pager.Name())
+		return nil
+	}
+
+	return &noteSync{
+		logger:     log.NewLoggerWith(s.logger, "component", "notesync"),
+		noteSyncer: noteSyncer,
+		slack:      s.slack,
+		interval:   s.Config.Pagerduty.SyncNotesInterval,
+		threads:    make(map[string]*noteThread),
+	}
+}
+
+// Run periodically mirrors new notes to the synchronized threads.
+func (n *noteSync) Run(stopCh <-chan struct{}) {
+	n.logger.LogInfo("synchronizing incident notes", "interval", n.interval.String())
+	ticker := time.NewTicker(n.interval)
+	for {
+		select {
+		case <-ticker.C:
+			n.mirrorNotes()
+		case <-stopCh:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// track starts or extends the synchronization of the incident notes with the thread.
+// The lock is not held while listing the notes, so a slow pager does not block other handlers.
+func (n *noteSync) track(incidentID, channel, threadTimestamp string) {
+	if n.extend(incidentID) {
+		return
+	}
+
+	// Only notes added from now on are mirrored to the thread.
+	seenNoteIDs := make(map[string]bool)
+	notes, err := n.noteSyncer.ListIncidentNotes(incidentID)
+	if err != nil {
+		n.logger.LogError("failed to list incident notes", err, "incidentID", incidentID)
+	}
+	for _, note := range notes {
+		seenNoteIDs[note.ID] = true
+	}
+
+	n.threadsMutex.Lock()
+	defer n.threadsMutex.Unlock()
+
+	// The incident might have been tracked in the meantime.
+	if thread, ok := n.threads[incidentID]; ok {
+		thread.expiresAt = time.Now().Add(noteThreadTTL)
+		return
+	}
+	n.threads[incidentID] = &noteThread{
+		channel:         channel,
+		threadTimestamp: threadTimestamp,
+		seenNoteIDs:     seenNoteIDs,
+		expiresAt:       time.Now().Add(noteThreadTTL),
+	}
+	n.logger.LogDebug("synchronizing incident notes with thread", "incidentID", incidentID, "channel", channel, "thread", threadTimestamp)
+}
+
+// extend extends the synchronization of the thread of the incident if it is already tracked.
+func (n *noteSync) extend(incidentID string) bool {
+	n.threadsMutex.Lock()
+	defer n.threadsMutex.Unlock()
+
+	thread, ok := n.threads[incidentID]
+	if ok {
+		thread.expiresAt = time.Now().Add(noteThreadTTL)
+	}
+	return ok
+}
+
+// mirrorNotes posts new notes to the threads.
+// The threads are copied under lock, the pager and Slack are called without it and the mirrored notes are recorded afterwards.
+func (n *noteSync) mirrorNotes() {
+	for incidentID, thread := range n.activeThreads() {
+		notes, err := n.noteSyncer.ListIncidentNotes(incidentID)
+		if err != nil {
+			n.logger.LogError("failed to list incident notes", err, "incidentID", incidentID)
+			continue
+		}
+
+		newNoteIDs := make([]string, 0)
+		for _, note := range notes {
+			if thread.seenNoteIDs[note.ID] {
+				continue
+			}
+			newNoteIDs = append(newNoteIDs, note.ID)
+
+			// Notes added from Slack are already in the thread.
+			if strings.HasPrefix(note.Content, slackNotePrefix) {
+				continue
+			}
+
+			n.slack.PostMessage(
+				thread.channel,
+				fmt.Sprintf("Incident note by %s: %s", note.Author, note.Content),
+				thread.threadTimestamp,
+			)
+		}
+		n.markSeen(incidentID, newNoteIDs)
+	}
+}
+
+// activeThreads removes expired threads and returns copies of the remaining ones.
+func (n *noteSync) activeThreads() map[string]noteThread {
+	n.threadsMutex.Lock()
+	defer n.threadsMutex.Unlock()
+
+	threads := make(map[string]noteThread, len(n.threads))
+	for incidentID, thread := range n.threads {
+		if time.Now().After(thread.expiresAt) {
+			delete(n.threads, incidentID)
+			continue
+		}
+
+		seenNoteIDs := make(map[string]bool, len(thread.seenNoteIDs))
+		for id := range thread.seenNoteIDs {
+			seenNoteIDs[id] = true
+		}
+		threads[incidentID] = noteThread{
+			channel:         thread.channel,
+			threadTimestamp: thread.threadTimestamp,
+			seenNoteIDs:     seenNoteIDs,
+			expiresAt:       thread.expiresAt,
+		}
+	}
+	return threads
+}
+
+// markSeen records the notes mirrored to the thread of the incident.
+func (n *noteSync) markSeen(incidentID string, noteIDs []string) {
+	n.threadsMutex.Lock()
+	defer n.threadsMutex.Unlock()
+
+	thread, ok := n.threads[incidentID]
+	if !ok {
+		return
+	}
+	for _, id := range noteIDs {
+		thread.seenNoteIDs[id] = true
+	}
+}
+
+// addNoteFromThreadReply adds a reply mentioning the stargate in the thread of an alert as note to the incident.
+func (s *Stargate) addNoteFromThreadReply(msg slack.Message) {
+	responder := s.slack.NewResponder(msg.Channel, msg.UserID, "")
+	if !s.slack.IsUserAuthorized(msg.UserID) {
+		responder.Deny("you are not a member of a user group authorized to add notes to incidents")
+		return
+	}
+
+	parentMessage, err := s.slack.GetThreadParentMessage(msg.Channel, msg.ThreadTimestamp)
+	if err != nil {
+		responder.Fail("failed to get the thread", err, "channel", msg.Channel)
+		return
+	}
+
+	slackAlert, err := s.slack.AlertFromSlackMessage(parentMessage)
+	if err != nil {
+		responder.Logger.LogDebug("not adding note as thread does not belong to an alert", "channel", msg.Channel, "thread", msg.ThreadTimestamp)
+		return
+	}
+
+	incidentID, err := s.addNoteToIncident(slackAlert, responder, msg.TextWithoutMentions())
+	if err != nil {
+		responder.Fail("failed to add note to incident", err)
+		return
+	}
+
+	s.noteSync.track(incidentID, msg.Channel, msg.ThreadTimestamp)
+	s.slack.AddReactionToMessage(msg.Channel, msg.Timestamp, slack.NoteReactionEmoji)
+}
+
+// addNoteFromCommand adds a note to an incident as requested via 'note <alertname> <region> <note>'.
+func (s *Stargate) addNoteFromCommand(ctx commandContext, cmd *slack.Command) {
+	if s.noteSync == nil {
+		ctx.responder.Respond("Adding notes is disabled as synchronizing incident notes is not enabled.")
+		return
+	}
+
+	if !s.slack.IsUserAuthorized(ctx.userID) {
+		ctx.responder.Deny("you are not a member of a user group authorized to add notes to incidents")
+		return
+	}
+
+	noteAlert := alertFromAlertnameAndRegion(cmd.Alertname, cmd.Region)
+	if _, err := s.addNoteToIncident(noteAlert, ctx.responder, cmd.Text); err != nil {
+		ctx.responder.Fail("failed to add note to incident", err)
+		return
+	}
+
+	ctx.responder.Respondf("Added note to the incident of alert %s in %s.", cmd.Alertname, cmd.Region)
+}
+
+// addNoteToIncident adds a note on behalf of the Slack user to the incident corresponding to the alert.
+// Returns the ID of the incident.
+func (s *Stargate) addNoteToIncident(noteAlert *client.ExtendedAlert, responder *slack.Responder, content string) (string, error) {
+	pagerUser, _, err := s.pagerUserBySlackUserID(responder.UserID)
+	if err != nil {
+		metrics.FailedOperationsTotal.WithLabelValues("note", s.pager.Name()).Inc()
+		return "", err
+	}
+	userName := pagerUser.Name
+	if userName == "" {
+		userName = responder.UserID
+	}
+
+	incident, err := s.pager.FindIncidentByAlert(noteAlert)
+	if err != nil {
+		metrics.FailedOperationsTotal.WithLabelValues("note", s.pager.Name()).Inc()
+		return "", err
+	}
+
+	err = s.noteSync.noteSyncer.AddIncidentNote(incident.ID, pagerUser, fmt.Sprintf("%s%s: %s", slackNotePrefix, userName, content))
+	if err != nil {
+		metrics.FailedOperationsTotal.WithLabelValues("note", s.pager.Name()).Inc()
+		return "", errors.Wrapf(err, "incident '%s'", incident.ID)
+	}
+
+	responder.Logger.LogInfo("added note to incident", "component", s.pager.Name(), "incidentID", incident.ID, "userName", userName)
+	metrics.SuccessfulOperationsTotal.WithLabelValues("note", s.pager.Name()).Inc()
+	return incident.ID, nil
+}
+
+// trackIncidentNotes synchronizes the notes of the incident corresponding to the alert with the thread.
+func (s *Stargate) trackIncidentNotes(slackAlert *client.ExtendedAlert, channel, threadTimestamp string) {
+	if s.noteSync == nil {
+		return
+	}
+
+	incident, err := s.pager.FindIncidentByAlert(slackAlert)
+	if err != nil {
+		s.logger.LogDebug("not synchronizing notes as no incident was found", "err", err.Error())
+		return
+	}
+	s.noteSync.track(incident.ID, channel, threadTimestamp)
+}
@@ -0,0 +1,99 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/pager"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingNoteSyncer blocks listing notes until released.
+type blockingNoteSyncer struct {
+	mtx     sync.Mutex
+	notes   []*pager.Note
+	listing chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingNoteSyncer) AddIncidentNote(incidentID string, user pager.User, content string) error {
+	return nil
+}
+
+func (b *blockingNoteSyncer) ListIncidentNotes(incidentID string) ([]*pager.Note, error) {
+	b.listing <- struct{}{}
+	<-b.release
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.notes, nil
+}
+
+func TestNoteSyncDoesNotBlockWhileListingNotes(t *testing.T) {
+	syncer := &blockingNoteSyncer{
+		notes:   []*pager.Note{{ID: "N1", Content: slackNotePrefix + "already in the thread"}},
+		listing: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	n := &noteSync{logger: log.NewLogger(false), noteSyncer: syncer, threads: make(map[string]*noteThread)}
+
+	started := make(chan struct{})
+	go func() {
+		n.track("P1", "C0123", "1550000000.000100")
+		close(started)
+	}()
+	<-syncer.listing
+	close(syncer.release)
+	<-started
+	assert.True(t, n.extend("P1"), "the incident should be tracked")
+
+	syncer.release = make(chan struct{})
+	syncer.mtx.Lock()
+	syncer.notes = append(syncer.notes, &pager.Note{ID: "N2", Content: slackNotePrefix + "added from slack"})
+	syncer.mtx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		n.mirrorNotes()
+		close(done)
+	}()
+	<-syncer.listing
+
+	// Tracking must not wait for the pager while notes are mirrored.
+	tracked := make(chan struct{})
+	go func() {
+		n.track("P1", "C0123", "1550000000.000100")
+		close(tracked)
+	}()
+	select {
+	case <-tracked:
+	case <-time.After(time.Second):
+		t.Fatal("tracking was blocked while listing incident notes")
+	}
+
+	close(syncer.release)
+	<-done
+	threads := n.activeThreads()
+	assert.True(t, threads["P1"].seenNoteIDs["N1"], "the notes present when tracking started should be seen")
+	assert.True(t, threads["P1"].seenNoteIDs["N2"], "the mirrored notes should be recorded")
+}
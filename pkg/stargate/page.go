@@ -0,0 +1,145 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/prometheus/common/model"
+	"github.com/sapcc/stargate/pkg/alert"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/metrics"
+	"github.com/sapcc/stargate/pkg/pager"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+const (
+	// pageSeverity is used for all events as paging someone manually is always urgent.
+	pageSeverity = "critical"
+
+	// defaultServiceName is shown if the on-call of the default service was paged.
+	defaultServiceName = "the default service"
+)
+
+// pageOnCallFromCommand pages the on-call of a service as requested via 'page <service> <message>'.
+func (s *Stargate) pageOnCallFromCommand(ctx commandContext, cmd *slack.Command) {
+	if s.pager == nil {
+		ctx.responder.Respond("Paging is disabled as no pager is configured.")
+		return
+	}
+
+	if cmd.Service == "" {
+		ctx.responder.Respondf("missing service. known services: %s", strings.Join(s.pager.Services(), ", "))
+		return
+	}
+
+	if !s.authorize(ctx.responder, policy.Request{Action: policy.Action.Page, Labels: map[string]string{"service": cmd.Service}}) {
+		return
+	}
+
+	err := s.pager.TriggerIncident(&pager.Event{
+		Service:  cmd.Service,
+		Summary:  cmd.Text,
+		Severity: pageSeverity,
+		Details: map[string]string{
+			"paged_by": s.commandUserName(ctx),
+			"channel":  ctx.channelName,
+		},
+	})
+	if err != nil {
+		metrics.FailedOperationsTotal.WithLabelValues("page", s.pager.Name()).Inc()
+		ctx.responder.Fail(fmt.Sprintf("failed to page the on-call of %s", cmd.Service), err, "component", s.pager.Name())
+		return
+	}
+	metrics.SuccessfulOperationsTotal.WithLabelValues("page", s.pager.Name()).Inc()
+
+	s.slack.PostMessage(
+		ctx.channel,
+		fmt.Sprintf("<@%s> paged the on-call of %s: %s", ctx.userID, cmd.Service, cmd.Text),
+		ctx.threadTimestamp,
+	)
+}
+
+// pageOnCallForAlert pages the on-call of the service the alert belongs to.
+// Returns the name of the paged service.
+func (s *Stargate) pageOnCallForAlert(slackAlert *client.ExtendedAlert, userName string) (string, error) {
+	alertname, err := alert.GetAlertnameFromExtendedAlert(slackAlert)
+	if err != nil {
+		return "", err
+	}
+	region, err := alert.GetRegionFromExtendedAlert(slackAlert)
+	if err != nil {
+		return "", err
+	}
+
+	// The slack message only contains some labels. Find the alert in the Alertmanager for the remaining ones.
+	labels := slackAlert.Labels
+	fingerprint := clientLabelSetFingerprint(labels)
+
+	filter := alertmanager.NewDefaultFilter()
+	filter.WithAlertLabelsFilter(slackAlert.Labels)
+	alertList, err := s.alertmanagerClient.ListAlerts(filter)
+	if err != nil {
+		s.logger.LogError("failed to list alerts from alertmanager. paging with labels from slack message", err)
+	} else if len(alertList) > 0 {
+		labels = alertList[0].Labels
+		fingerprint = alertList[0].Fingerprint
+	}
+
+	details := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		details[string(k)] = string(v)
+	}
+	details["paged_by"] = userName
+
+	// Page the default service if the service of the alert is unknown.
+	service := string(labels["service"])
+	if !util.StringSliceContains(s.pager.Services(), service) {
+		service = ""
+	}
+
+	err = s.pager.TriggerIncident(&pager.Event{
+		Service:  service,
+		Summary:  fmt.Sprintf("[%s] %s - paged via Slack by %s", strings.ToUpper(region), alertname, userName),
+		Severity: pageSeverity,
+		DedupKey: fmt.Sprintf("stargate-%s", fingerprint),
+		Details:  details,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if service == "" {
+		return defaultServiceName, nil
+	}
+	return service, nil
+}
+
+func clientLabelSetFingerprint(labelSet client.LabelSet) string {
+	modelLabelSet := make(model.LabelSet, len(labelSet))
+	for k, v := range labelSet {
+		modelLabelSet[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return modelLabelSet.Fingerprint().String()
+}
@@ -0,0 +1,74 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"sync"
+	"time"
+)
+
+// pendingSilences holds silences awaiting confirmation or approval until they time out.
+type pendingSilences struct {
+	mtx      sync.Mutex
+	silences map[string]*silenceRequest
+	timeout  time.Duration
+	// onTimeout is called with silences which were neither confirmed nor canceled in time.
+	onTimeout func(req *silenceRequest)
+}
+
+func newPendingSilences(timeout time.Duration, onTimeout func(req *silenceRequest)) *pendingSilences {
+	return &pendingSilences{
+		silences:  make(map[string]*silenceRequest),
+		timeout:   timeout,
+		onTimeout: onTimeout,
+	}
+}
+
+// add stores the silence until it is removed or times out.
+func (p *pendingSilences) add(id string, req *silenceRequest) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.silences[id] = req
+	time.AfterFunc(p.timeout, func() {
+		if req, ok := p.remove(id); ok && p.onTimeout != nil {
+			p.onTimeout(req)
+		}
+	})
+}
+
+// get returns the silence if it is still pending.
+func (p *pendingSilences) get(id string) (*silenceRequest, bool) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	req, ok := p.silences[id]
+	return req, ok
+}
+
+// remove deletes the silence. Returns false if it was already removed.
+func (p *pendingSilences) remove(id string) (*silenceRequest, bool) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	req, ok := p.silences[id]
+	delete(p.silences, id)
+	return req, ok
+}
@@ -0,0 +1,62 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sapcc/stargate/pkg/api"
+	"github.com/sapcc/stargate/pkg/silence"
+)
+
+// HandlePostMaintenanceWindow handles adding or replacing a maintenance window.
+// Its silences are created with the next check of the maintenance windows.
+func (s *Stargate) HandlePostMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	var d struct {
+		Data silence.MaintenanceWindow `json:"data"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		s.logger.LogError("error decoding data", err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(api.Error{Code: http.StatusBadRequest, Message: "error decoding data"})
+		return
+	}
+
+	window := d.Data
+	if err := window.Validate(); err != nil {
+		s.logger.LogError("invalid maintenance window", err, "window", window.Name)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(api.Error{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	if err := s.maintenance.set(window); err != nil {
+		s.logger.LogError("failed to persist maintenance window", err, "window", window.Name)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(api.Error{Code: http.StatusInternalServerError, Message: "failed to persist maintenance window"})
+		return
+	}
+	s.logger.LogInfo("set maintenance window", "window", window.Name)
+
+	s.respondWithJSON(w, nil)
+	s.logger.LogDebug("responding to request", "handler", "postMaintenanceWindow")
+}
@@ -19,14 +19,28 @@
 
 package stargate
 
-import "net/http"
+import (
+	"net/http"
+
+	slackapi "github.com/nlopes/slack"
+)
 
 // HandleSlackCommand handles slack commands.
-// The slack client does the work here.
 func (s *Stargate) HandleSlackCommand(w http.ResponseWriter, r *http.Request) {
 	s.logger.LogDebug("received slack command")
 	w.WriteHeader(http.StatusNoContent)
 	r.ParseForm()
 
-	go s.slack.HandleSlackCommand(r)
+	slashCommand, err := s.slack.SlashCommandFromRequest(r)
+	if err != nil {
+		s.logger.LogError("failed to handle slash command", err)
+		return
+	}
+
+	go s.handleSlackCommand(slashCommand)
+}
+
+func (s *Stargate) handleSlackCommand(slashCommand slackapi.SlashCommand) {
+	// The outcome of every command is reported to the acting user.
+	s.handleCommand(s.commandContextFromSlashCommand(slashCommand), slashCommand.Text)
 }
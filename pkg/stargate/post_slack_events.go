@@ -0,0 +1,56 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"net/http"
+
+	"github.com/sapcc/stargate/pkg/slack"
+)
+
+// HandleSlackEvents handles events sent via the slack Events API.
+func (s *Stargate) HandleSlackEvents(w http.ResponseWriter, r *http.Request) {
+	s.logger.LogDebug("received slack event")
+
+	event, err := s.slack.EventsAPIEventFromRequest(r)
+	if err != nil {
+		s.logger.LogError("failed to handle slack event", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// Slack verifies the endpoint by sending a challenge.
+	if event.Type == slack.EventTypeURLVerification {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(event.Challenge))
+		return
+	}
+
+	// Slack retries if the event was not acknowledged within 3 seconds. The first attempt is already handled.
+	if r.Header.Get("X-Slack-Retry-Num") != "" {
+		s.logger.LogDebug("ignoring retried slack event", "eventID", event.EventID, "reason", r.Header.Get("X-Slack-Retry-Reason"))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	go s.slack.HandleEventsAPIEvent(event)
+}
@@ -22,13 +22,14 @@ package stargate
 import (
 	"fmt"
 	"net/http"
-	"time"
 
+	"github.com/nlopes/slack/slackevents"
+	"github.com/prometheus/alertmanager/client"
 	"github.com/sapcc/stargate/pkg/alert"
-	"github.com/sapcc/stargate/pkg/alertmanager"
 	"github.com/sapcc/stargate/pkg/metrics"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/silence"
 	"github.com/sapcc/stargate/pkg/slack"
-	"github.com/sapcc/stargate/pkg/util"
 )
 
 // HandleSlackMessageActionEvent handles slack message action events
@@ -47,150 +48,178 @@ func (s *Stargate) HandleSlackMessageActionEvent(w http.ResponseWriter, r *http.
 		}
 	}
 
-	go func() {
-		slackMessageAction, err := s.slack.MessageActionFromPayload(payloadString)
-		if err != nil {
-			s.logger.LogError("failed to parse slack message", err)
-		}
+	go s.handleSlackInteraction(payloadString, false)
+}
 
-		var userName string
-		userName, err = s.slack.GetUserNameByID(slackMessageAction.User.Id)
-		if err != nil {
-			s.logger.LogError("user not found by id", err, "userID", slackMessageAction.User.Id, "userName", userName)
+// handleSlackInteraction handles an interactive payload received via HTTP or the authenticated Socket Mode websocket.
+func (s *Stargate) handleSlackInteraction(payload string, isSocketMode bool) {
+	switch slack.InteractionTypeFromPayload(payload) {
+	case slack.InteractionType.BlockActions:
+		parse := s.slack.BlockActionsFromPayload
+		if isSocketMode {
+			parse = s.slack.BlockActionsFromSocketModePayload
 		}
-
-		// check whether user is authorized
-		if !s.slack.IsUserAuthorized(slackMessageAction.User.Id) {
-			s.logger.LogInfo("user is not authorized to respond to a message",
-				"userID", slackMessageAction.User.Id,
-				"userName", userName,
-			)
+		blockActions, err := parse(payload)
+		if err != nil {
+			s.logger.LogError("failed to parse slack block actions", err)
 			return
 		}
+		s.handleSlackBlockActions(blockActions)
 
-		slackAlert, err := s.slack.AlertFromSlackMessage(slackMessageAction.OriginalMessage)
-		if err != nil {
-			s.logger.LogError("failed to parse alert from slack message", err)
+	case slack.InteractionType.DialogSubmission:
+		parse := s.slack.DialogSubmissionFromPayload
+		if isSocketMode {
+			parse = s.slack.DialogSubmissionFromSocketModePayload
 		}
-
-		alertname, err := alert.GetAlertnameFromExtendedAlert(slackAlert)
+		submission, err := parse(payload)
 		if err != nil {
-			s.logger.LogError("failed to get alertname", err)
+			s.logger.LogError("failed to parse slack dialog submission", err)
 			return
 		}
+		s.handleAlertDialogSubmission(submission)
 
-		actionList, err := s.slack.ActionFromSlackMessage(slackMessageAction)
-		if err != nil {
-			s.logger.LogError("failed to parse actions from slack message", err)
+	default:
+		parse := s.slack.MessageActionFromPayload
+		if isSocketMode {
+			parse = s.slack.MessageActionFromSocketModePayload
 		}
-
-		userEmail, err := s.slack.GetUserEmailByID(slackMessageAction.User.Id)
+		slackMessageAction, err := parse(payload)
 		if err != nil {
-			s.logger.LogError("failed to get email of user", err, "userID", slackMessageAction.User.Id, "userName", userName)
+			s.logger.LogError("failed to parse slack message", err)
+			return
+		}
+		if slackMessageAction.Type == slack.InteractionType.MessageAction {
+			s.handleMessageShortcut(slackMessageAction)
+			return
 		}
+		s.handleSlackMessageAction(slackMessageAction)
+	}
+}
 
-		for _, action := range actionList {
-			switch action {
-
-			// Acknowledge an alert.
-			case slack.Reaction.Acknowledge:
-				// At least post the message to slack.
-				s.slack.PostMessage(
-					slackMessageAction.Channel.Id,
-					fmt.Sprintf("Acknowledged by <@%s>", slackMessageAction.User.Id),
-					slackMessageAction.OriginalMessage.Timestamp,
-				)
-				s.slack.AddReactionToMessage(slackMessageAction.Channel.Id, slackMessageAction.OriginalMessage.Timestamp, slack.AcknowledgeReactionEmoji)
-
-				// List all alerts that match the slack alert.
-				filter := alertmanager.NewDefaultFilter()
-				filter.WithAlertLabelsFilter(slackAlert.Labels)
-				alertList, err := s.alertmanagerClient.ListAlerts(filter)
-				if err != nil {
-					s.logger.LogError("failed to get list alerts from alertmanager", err)
-					return
-				}
+// alertActionContext identifies the message and the user an alert action was triggered by.
+type alertActionContext struct {
+	channel,
+	messageTimestamp,
+	userID string
+}
 
-				// Acknowledge the alerts matching the labels found in the slack message.
-				err = s.alertStore.AcknowledgeAndSetMultiple(alertList, userName)
-				if err != nil {
-					s.logger.LogError("failed to acknowledge alert", err, "component", "alertmanager", "labels", alert.ClientLabelSetToString(slackAlert.Labels))
-					metrics.FailedOperationsTotal.WithLabelValues("acknowledge").Inc()
-				} else {
-					for _, a := range alertList {
-						// Don't return here on failure. We might be able to acknowledge in Pagerduty.
-						s.logger.LogInfo("acknowledged alert", "component", "alertmanager", "labels", alert.ClientLabelSetToString(a.Labels))
-					}
-				}
+// handleSlackMessageAction handles a button clicked in a message posted by the Alertmanager.
+func (s *Stargate) handleSlackMessageAction(slackMessageAction slackevents.MessageAction) {
+	// The outcome of every action is reported to the acting user.
+	responder := s.slack.NewResponder(slackMessageAction.Channel.Id, slackMessageAction.User.Id, slackMessageAction.ResponseUrl)
 
-				if err := s.pagerdutyClient.AcknowledgeIncident(slackAlert, userEmail); err != nil {
-					s.logger.LogError("failed to acknowledge incident", err, "component", "pagerduty")
-					metrics.FailedOperationsTotal.WithLabelValues("acknowledge").Inc()
-					return
-				}
-				s.logger.LogInfo("acknowledged alert", "component", "pagerduty", "labels", alert.ClientLabelSetToString(slackAlert.Labels))
-				metrics.SuccessfulOperationsTotal.WithLabelValues("acknowledge").Inc()
-
-				// Create a silence until next monday
-			case slack.Reaction.SilenceUntilMonday:
-				durationDays := util.TimeUntilNextMonday(time.Now().UTC())
-				silenceID, err := s.alertmanagerClient.CreateSilence(slackAlert, userName, slack.SilenceDefaultComment, util.DaysToHours(durationDays))
-				if err != nil {
-					s.logger.LogError("error creating silence", err, "component", "alertmanager")
-					metrics.FailedOperationsTotal.WithLabelValues("silence").Inc()
-					return
-				}
+	slackAlert, err := s.slack.AlertFromSlackMessage(slackMessageAction.OriginalMessage)
+	if err != nil {
+		responder.Fail("failed to parse alert from slack message", err)
+		return
+	}
 
-				s.slack.PostMessage(
-					slackMessageAction.Channel.Id,
-					fmt.Sprintf("<@%s> silenced alert %s for %v day(s). <%s|See Silence>", slackMessageAction.User.Id, alertname, durationDays, s.alertmanagerClient.LinkToSilence(silenceID)),
-					slackMessageAction.OriginalMessage.Timestamp,
-				)
-				s.slack.AddReactionToMessage(slackMessageAction.Channel.Id, slackMessageAction.OriginalMessage.Timestamp, slack.SilenceSuccessReactionEmoji)
-
-				metrics.SuccessfulOperationsTotal.WithLabelValues("silence").Inc()
-
-				// Create a silence for 1 day
-			case slack.Reaction.Silence1Day:
-				durationHours := util.DaysToHours(1)
-				silenceID, err := s.alertmanagerClient.CreateSilence(slackAlert, userName, slack.SilenceDefaultComment, durationHours)
-				if err != nil {
-					s.logger.LogError("error creating silence", err, "component", "alertmanager")
-					metrics.FailedOperationsTotal.WithLabelValues("silence").Inc()
-					return
-				}
+	actionList, err := s.slack.ActionFromSlackMessage(slackMessageAction)
+	if err != nil {
+		responder.Fail("failed to parse actions from slack message", err)
+		return
+	}
 
-				s.slack.PostMessage(
-					slackMessageAction.Channel.Id,
-					fmt.Sprintf("<@%s> silenced alert %s for %s. <%s|See Silence>", slackMessageAction.User.Id, alertname, util.HumanizedDurationString(durationHours), s.alertmanagerClient.LinkToSilence(silenceID)),
-					slackMessageAction.OriginalMessage.Timestamp,
-				)
-				s.slack.AddReactionToMessage(slackMessageAction.Channel.Id, slackMessageAction.OriginalMessage.Timestamp, slack.SilenceSuccessReactionEmoji)
-
-				metrics.SuccessfulOperationsTotal.WithLabelValues("silence").Inc()
-
-				// Create a silence for 1 month
-			case slack.Reaction.Silence1Month:
-				durationHours := util.DaysToHours(31)
-				silenceID, err := s.alertmanagerClient.CreateSilence(slackAlert, userName, slack.SilenceDefaultComment, durationHours)
-				if err != nil {
-					s.logger.LogError("error creating silence", err, "component", "alertmanager")
-					metrics.FailedOperationsTotal.WithLabelValues("silence").Inc()
-					return
-				}
+	s.handleAlertActions(alertActionContext{
+		channel:          slackMessageAction.Channel.Id,
+		messageTimestamp: slackMessageAction.OriginalMessage.Timestamp,
+		userID:           slackMessageAction.User.Id,
+	}, responder, slackAlert, actionList)
+}
 
-				s.slack.PostMessage(
-					slackMessageAction.Channel.Id,
-					fmt.Sprintf("<@%s> silenced alert %s for %s. <%s|See Silence>", slackMessageAction.User.Id, alertname, util.HumanizedDurationString(durationHours), s.alertmanagerClient.LinkToSilence(silenceID)),
-					slackMessageAction.OriginalMessage.Timestamp,
-				)
-				s.slack.AddReactionToMessage(slackMessageAction.Channel.Id, slackMessageAction.OriginalMessage.Timestamp, slack.SilenceSuccessReactionEmoji)
+// handleAlertActions performs the actions for the alert.
+// Used by the buttons of messages posted by the Alertmanager and by the buttons of the alert list.
+func (s *Stargate) handleAlertActions(actionCtx alertActionContext, responder *slack.Responder, slackAlert *client.ExtendedAlert, actionList []string) {
+	logger := responder.Logger
 
-				metrics.SuccessfulOperationsTotal.WithLabelValues("silence").Inc()
+	userName, err := s.slack.GetUserNameByID(actionCtx.userID)
+	if err != nil {
+		logger.LogError("user not found by id", err, "userID", actionCtx.userID, "userName", userName)
+	}
+
+	// check whether user is authorized
+	if !s.slack.IsUserAuthorized(actionCtx.userID) {
+		responder.Deny("you are not a member of a user group authorized to interact with the stargate")
+		return
+	}
+
+	alertname, err := alert.GetAlertnameFromExtendedAlert(slackAlert)
+	if err != nil {
+		responder.Fail("failed to get alertname", err)
+		return
+	}
 
-			default:
-				s.logger.LogDebug("not responding to action", "actionValue", action)
+	// Policies might be scoped by labels not contained in the slack message.
+	// Acknowledging or silencing acts on every alert matching the labels of the slack message.
+	alertLabelSets, err := s.matchingAlertLabels(slackAlert)
+	if err != nil {
+		responder.Fail("failed to authorize the action", err)
+		return
+	}
+
+	for _, action := range actionList {
+		switch action {
+
+		// Acknowledge an alert.
+		case slack.Reaction.Acknowledge:
+			if !s.authorizeLabelSets(responder, policy.Request{Action: policy.Action.Acknowledge}, alertLabelSets) {
+				return
 			}
+
+			// At least post the message to slack.
+			s.slack.PostMessage(
+				actionCtx.channel,
+				fmt.Sprintf("Acknowledged by <@%s>", actionCtx.userID),
+				actionCtx.messageTimestamp,
+			)
+			s.slack.AddReactionToMessage(actionCtx.channel, actionCtx.messageTimestamp, slack.AcknowledgeReactionEmoji)
+
+			// Find the incident before acknowledging it as only triggered incidents can be found.
+			s.trackIncidentNotes(slackAlert, actionCtx.channel, actionCtx.messageTimestamp)
+
+			if !s.acknowledgeAlert(slackAlert, alertname, userName, responder) {
+				return
+			}
+
+			// Page the on-call of the service.
+		case slack.Reaction.Page:
+			if s.pager == nil {
+				responder.Respond("Paging is disabled as no pager is configured.")
+				continue
+			}
+
+			if !s.authorizeLabelSets(responder, policy.Request{Action: policy.Action.Page}, alertLabelSets) {
+				return
+			}
+
+			service, err := s.pageOnCallForAlert(slackAlert, userName)
+			if err != nil {
+				responder.Fail("failed to page the on-call", err, "component", s.pager.Name())
+				metrics.FailedOperationsTotal.WithLabelValues("page", s.pager.Name()).Inc()
+				return
+			}
+
+			s.slack.PostMessage(
+				actionCtx.channel,
+				fmt.Sprintf("<@%s> paged the on-call of %s for alert %s.", actionCtx.userID, service, alertname),
+				actionCtx.messageTimestamp,
+			)
+			s.slack.AddReactionToMessage(actionCtx.channel, actionCtx.messageTimestamp, slack.PageReactionEmoji)
+
+			metrics.SuccessfulOperationsTotal.WithLabelValues("page", s.pager.Name()).Inc()
+			responder.Respondf("Paged the on-call of %s.", service)
+
+		default:
+			// Create a silence using the preset.
+			if preset, ok := silence.FindPreset(s.Config.SilencePresets, action); ok {
+				if !s.silenceWithPreset(actionCtx, responder, slackAlert, alertname, userName, alertLabelSets, preset) {
+					return
+				}
+				continue
+			}
+
+			logger.LogDebug("not responding to action", "actionValue", action)
+			responder.Respondf("Sorry, the action '%s' is unknown. (correlation ID: %s)", action, responder.CorrelationID)
 		}
-	}()
+	}
 }
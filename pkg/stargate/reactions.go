@@ -0,0 +1,96 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"encoding/json"
+	"strings"
+
+	slackapi "github.com/nlopes/slack"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// stargateReactionEmojis are added by the stargate itself and cannot be mapped to actions.
+var stargateReactionEmojis = []string{
+	slack.AcknowledgeReactionEmoji,
+	slack.SilenceSuccessReactionEmoji,
+	slack.PageReactionEmoji,
+	slack.NoteReactionEmoji,
+}
+
+// newReactionActions returns the valid mappings of emoji names to actions configured via `slack.reaction_actions`.
+func (s *Stargate) newReactionActions() map[string]string {
+	knownActions := slack.Reactions()
+	for _, p := range s.Config.SilencePresets {
+		knownActions = append(knownActions, p.Action)
+	}
+
+	reactionActions := make(map[string]string, len(s.Config.Slack.ReactionActions))
+	for emoji, action := range s.Config.Slack.ReactionActions {
+		emoji = strings.Trim(emoji, ":")
+		if util.StringSliceContains(stargateReactionEmojis, emoji) {
+			s.logger.LogWarn("ignoring reaction action as the emoji is used by the stargate", "emoji", emoji)
+			continue
+		}
+		if !util.StringSliceContains(knownActions, action) {
+			s.logger.LogWarn("ignoring reaction action as the action is unknown", "emoji", emoji, "action", action, "knownActions", strings.Join(knownActions, ", "))
+			continue
+		}
+		reactionActions[emoji] = action
+	}
+	return reactionActions
+}
+
+// handleReactionAdded performs the action mapped to the emoji a user reacted with to an alert message.
+// The action is authorized and handled like a click on the corresponding button.
+func (s *Stargate) handleReactionAdded(event json.RawMessage) {
+	var e slackapi.ReactionAddedEvent
+	if err := json.Unmarshal(event, &e); err != nil {
+		s.logger.LogError("failed to parse reaction_added event", err)
+		return
+	}
+
+	action, ok := s.reactionActions[e.Reaction]
+	if !ok || e.Item.Type != "message" {
+		return
+	}
+
+	responder := s.slack.NewResponder(e.Item.Channel, e.User, "")
+	responder.Logger.LogDebug("handling reaction", "emoji", e.Reaction, "action", action, "userID", e.User)
+
+	message, err := s.slack.GetMessage(e.Item.Channel, e.Item.Timestamp)
+	if err != nil {
+		responder.Fail("failed to get the message you reacted to", err)
+		return
+	}
+
+	slackAlert, err := s.slack.AlertFromSlackMessage(message)
+	if err != nil {
+		responder.Logger.LogDebug("ignoring reaction as the message does not contain an alert", "channel", e.Item.Channel, "timestamp", e.Item.Timestamp)
+		return
+	}
+
+	s.handleAlertActions(alertActionContext{
+		channel:          e.Item.Channel,
+		messageTimestamp: e.Item.Timestamp,
+		userID:           e.User,
+	}, responder, slackAlert, []string{action})
+}
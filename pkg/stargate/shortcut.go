@@ -0,0 +1,73 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"github.com/nlopes/slack/slackevents"
+	"github.com/sapcc/stargate/pkg/slack"
+)
+
+// handleMessageShortcut opens the dialog to acknowledge or silence the alert of the message the shortcut was used on.
+// Useful for alerts forwarded or reposted to other channels which lost their buttons.
+func (s *Stargate) handleMessageShortcut(shortcut slackevents.MessageAction) {
+	if shortcut.CallbackId != s.Config.Slack.ShortcutCallbackID {
+		s.logger.LogDebug("ignoring unknown message shortcut", "callbackID", shortcut.CallbackId)
+		return
+	}
+
+	responder := s.slack.NewResponder(shortcut.Channel.Id, shortcut.User.Id, shortcut.ResponseUrl)
+
+	if !s.slack.IsUserAuthorized(shortcut.User.Id) {
+		responder.Deny("you are not a member of a user group authorized to interact with the stargate")
+		return
+	}
+
+	slackAlert, err := s.slack.AlertFromSlackMessage(shortcut.Message)
+	if err != nil {
+		responder.Fail("the selected message does not contain an alert", err)
+		return
+	}
+
+	if err := s.slack.OpenAlertDialog(shortcut.TriggerId, shortcut.CallbackId, shortcut.Message.Timestamp, slackAlert); err != nil {
+		responder.Fail("failed to open the dialog", err)
+	}
+}
+
+// handleAlertDialogSubmission performs the action selected in the dialog opened via the message shortcut.
+func (s *Stargate) handleAlertDialogSubmission(submission slack.DialogSubmission) {
+	if submission.CallbackID != s.Config.Slack.ShortcutCallbackID {
+		s.logger.LogDebug("ignoring unknown dialog submission", "callbackID", submission.CallbackID)
+		return
+	}
+
+	responder := s.slack.NewResponder(submission.Channel.Id, submission.User.Id, submission.ResponseURL)
+
+	action, messageTimestamp, slackAlert, err := slack.AlertFromDialogSubmission(submission)
+	if err != nil {
+		responder.Fail("failed to parse the alert from the dialog", err)
+		return
+	}
+
+	s.handleAlertActions(alertActionContext{
+		channel:          submission.Channel.Id,
+		messageTimestamp: messageTimestamp,
+		userID:           submission.User.Id,
+	}, responder, slackAlert, []string{action})
+}
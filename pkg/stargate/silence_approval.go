@@ -0,0 +1,157 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// requiresApproval checks whether the silence lasts too long or matches alerts of a severity requiring approval.
+func (s *Stargate) requiresApproval(req *silenceRequest) bool {
+	cfg := s.Config.SilenceApproval
+	if cfg.MaxDuration > 0 && time.Until(req.endsAt) > cfg.MaxDuration {
+		return true
+	}
+
+	severities := append([]string{}, req.severities...)
+	if severity, ok := matcherLabels(req.matchers)[alertmanager.SeverityLabel]; ok {
+		severities = append(severities, severity)
+	}
+	for _, severity := range severities {
+		if util.StringSliceContains(cfg.Severities, severity) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestApproval posts the approval request to the thread of the silence.
+// Approval is requested in the configured channel if the silence was requested in a direct message.
+func (s *Stargate) requestApproval(req *silenceRequest, responder *slack.Responder) (string, bool) {
+	channel, threadTimestamp := req.channel, req.threadTimestamp
+	if channel == "" || slack.IsDirectMessageChannel(channel) {
+		channel, threadTimestamp = s.Config.SilenceApproval.Channel, ""
+	}
+	if channel == "" {
+		responder.Fail("failed to request approval", errors.New("no channel configured to request approval of silences requested in direct messages"))
+		return "", false
+	}
+
+	id := util.NewCorrelationID()
+	text := fmt.Sprintf(
+		"<@%s> requests approval to silence %s `%s` until %s. Another authorized user needs to approve within %s.",
+		req.userID, req.subject, req.matchers.String(), req.endsAt.In(req.location).Format(silenceEndFormat),
+		util.HumanizedDurationString(s.Config.SilenceApproval.Timeout),
+	)
+	if req.reason != "" {
+		text += fmt.Sprintf(" Reason: %s", req.reason)
+	}
+
+	timestamp, err := s.slack.PostBlocks(channel, text, slack.SilenceApprovalButtons.Blocks(text, id), threadTimestamp)
+	if err != nil {
+		responder.Fail("failed to request approval", err)
+		return "", false
+	}
+
+	// The approval request starts the thread if the silence was not requested in one.
+	if channel != req.channel || threadTimestamp == "" {
+		req.channel, req.threadTimestamp, req.addReaction = channel, timestamp, false
+	}
+	req.approvalTimestamp = timestamp
+	s.pendingApprovals.add(id, req)
+
+	return fmt.Sprintf("The silence of %s needs to be approved by another authorized user. Requested approval in <#%s>.", req.subject, channel), true
+}
+
+// handleSilenceApproval creates or discards a silence as approved or rejected by another user.
+func (s *Stargate) handleSilenceApproval(responder *slack.Responder, action slack.BlockAction) {
+	approvalAction, id, err := slack.SilenceApprovalButtons.FromBlockAction(action)
+	if err != nil {
+		responder.Fail("failed to parse silence from button", err)
+		return
+	}
+
+	req, ok := s.pendingApprovals.get(id)
+	if !ok {
+		responder.Respond("Sorry, the silence is no longer pending approval.")
+		return
+	}
+
+	if !s.slack.IsUserAuthorized(responder.UserID) {
+		responder.Deny("you are not a member of a user group authorized to interact with the stargate")
+		return
+	}
+	if approvalAction == slack.SilenceApprovalAction.Approve {
+		if responder.UserID == req.userID {
+			responder.Deny("silences need to be approved by another user")
+			return
+		}
+		if !s.authorize(responder, policy.Request{Action: policy.Action.Silence, Labels: matcherLabels(req.matchers), SilenceDuration: time.Until(req.endsAt)}) {
+			return
+		}
+	}
+
+	// Buttons might be clicked twice.
+	if _, ok := s.pendingApprovals.remove(id); !ok {
+		return
+	}
+
+	verb := "rejected"
+	if approvalAction == slack.SilenceApprovalAction.Approve {
+		verb = "approved"
+	}
+	outcome := fmt.Sprintf("<@%s> %s the silence of %s requested by <@%s>.", responder.UserID, verb, req.subject, req.userID)
+	if err := responder.ReplaceOriginal(outcome, nil); err != nil {
+		responder.Logger.LogDebug("failed to replace approval request", "err", err)
+	}
+	s.slack.PostMessage(req.channel, outcome, req.threadTimestamp)
+
+	if approvalAction == slack.SilenceApprovalAction.Approve {
+		s.createSilence(req, responder)
+	}
+}
+
+// silenceApprovalTimedOut records in the thread that the silence was not approved in time.
+func (s *Stargate) silenceApprovalTimedOut(req *silenceRequest) {
+	outcome := fmt.Sprintf("The silence of %s requested by <@%s> was not approved within %s.", req.subject, req.userID, util.HumanizedDurationString(s.Config.SilenceApproval.Timeout))
+	if err := s.slack.UpdateBlocks(req.channel, req.approvalTimestamp, outcome, nil); err != nil {
+		s.logger.LogError("failed to update approval request", err, "channel", req.channel)
+	}
+	s.slack.PostMessage(req.channel, outcome, req.threadTimestamp)
+}
+
+// matcherLabels returns the labels of the equality matchers.
+func matcherLabels(matchers types.Matchers) map[string]string {
+	labels := make(map[string]string, len(matchers))
+	for _, m := range matchers {
+		if !m.IsRegex {
+			labels[m.Name] = m.Value
+		}
+	}
+	return labels
+}
@@ -0,0 +1,60 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilenceApprovalByRequesterIsDenied(t *testing.T) {
+	s := newTestStargate(nil)
+	defer s.close()
+
+	req := &silenceRequest{
+		matchers:        types.Matchers{{Name: "alertname", Value: "NodeDown"}, {Name: "region", Value: "eu-de-1"}},
+		endsAt:          time.Now().Add(48 * time.Hour),
+		author:          "Jane Doe (JDOE)",
+		comment:         "maintenance",
+		subject:         "alert NodeDown",
+		userID:          "U0001",
+		channel:         "C0001",
+		threadTimestamp: "1550000000.000100",
+		location:        time.UTC,
+	}
+	s.pendingApprovals.add("approval-id", req)
+	approve := slack.BlockAction{ActionID: slack.BlockActionID.SilenceApproval + slack.SilenceApprovalAction.Approve, Value: "approval-id"}
+
+	s.handleSilenceApproval(s.newResponder("U0001"), approve)
+	assert.Contains(t, s.slackAPI.lastResponse(), "approved by another user", "the requester should be told why the approval was denied")
+	assert.Equal(t, 0, s.alertmanagerAPI.silenceCount(), "no silence should be created")
+	_, ok := s.pendingApprovals.get("approval-id")
+	assert.True(t, ok, "the silence should still be pending approval")
+
+	s.handleSilenceApproval(s.newResponder("U0002"), approve)
+	require.Equal(t, 1, s.alertmanagerAPI.silenceCount(), "the silence should be created once approved by another user")
+	_, ok = s.pendingApprovals.get("approval-id")
+	assert.False(t, ok, "the silence should no longer be pending approval")
+}
@@ -0,0 +1,227 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/metrics"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/sapcc/stargate/pkg/store"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// silenceJanitor tracks when active silences last matched an alert.
+// The times are persisted, so silences are considered stale the earliest after the configured time since the first start.
+type silenceJanitor struct {
+	mtx sync.Mutex
+	// store persists the last matches and the reported stale silences.
+	// Reported stale silences are not reported again unless they match an alert in the meantime.
+	store *store.StaleSilenceStore
+}
+
+// newSilenceJanitor returns the janitor if enabled or nil.
+func (s *Stargate) newSilenceJanitor() *silenceJanitor {
+	if !s.Config.SilenceJanitor.Enabled {
+		return nil
+	}
+
+	staleSilences, err := store.NewStaleSilenceStore(s.opts.StaleSilencesFilePath, s.logger)
+	if err != nil {
+		s.logger.LogFatal("failed to load stale silences", "err", err)
+	}
+	return &silenceJanitor{store: staleSilences}
+}
+
+// update records which of the active silences matched an alert and persists it.
+// Returns the number of stale silences and the ones which were not reported yet.
+func (j *silenceJanitor) update(activeSilences []*types.Silence, matching map[string]bool, staleAfter time.Duration) (int, []*types.Silence, error) {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	now := time.Now()
+	lastMatchedAt := make(map[string]time.Time, len(activeSilences))
+	reported := make(map[string]bool)
+	staleCount := 0
+	unreported := make([]*types.Silence, 0)
+
+	for _, sil := range activeSilences {
+		if matching[sil.ID] {
+			lastMatchedAt[sil.ID] = now
+			continue
+		}
+
+		matchedAt, ok := j.store.LastMatchedAt(sil.ID)
+		if !ok {
+			matchedAt = j.store.TrackedSince()
+			if sil.StartsAt.After(matchedAt) {
+				matchedAt = sil.StartsAt
+			}
+		}
+		lastMatchedAt[sil.ID] = matchedAt
+
+		if now.Sub(matchedAt) < staleAfter {
+			continue
+		}
+		staleCount++
+		reported[sil.ID] = true
+		if !j.store.IsReported(sil.ID) {
+			unreported = append(unreported, sil)
+		}
+	}
+
+	// Silences which are no longer active or matched an alert are forgotten.
+	return staleCount, unreported, j.store.Set(lastMatchedAt, reported)
+}
+
+// sinceLastMatch returns the time since the silence last matched an alert.
+func (j *silenceJanitor) sinceLastMatch(silenceID string) time.Duration {
+	matchedAt, _ := j.store.LastMatchedAt(silenceID)
+	return time.Since(matchedAt)
+}
+
+// runSilenceJanitor periodically reports stale silences.
+func (s *Stargate) runSilenceJanitor(stopCh <-chan struct{}) {
+	s.logger.LogInfo("checking for stale silences", "interval", s.Config.SilenceJanitor.Interval.String())
+	ticker := time.NewTicker(s.Config.SilenceJanitor.Interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.reportStaleSilences()
+		case <-stopCh:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// reportStaleSilences reports silences which have not matched any alert, including silenced ones, for the configured time.
+func (s *Stargate) reportStaleSilences() {
+	silences, err := s.alertmanagerClient.ListSilences(alertmanager.NewDefaultFilter())
+	if err != nil {
+		s.logger.LogError("failed to list silences", err)
+		return
+	}
+
+	filter := alertmanager.NewDefaultFilter()
+	filter.IsSilenced = true
+	alerts, err := s.alertmanagerClient.ListAlerts(filter)
+	if err != nil {
+		s.logger.LogError("failed to list alerts", err)
+		return
+	}
+
+	activeSilences := make([]*types.Silence, 0, len(silences))
+	for _, sil := range silences {
+		if sil.Status.State == types.SilenceStateActive {
+			activeSilences = append(activeSilences, sil)
+		}
+	}
+
+	staleCount, unreported, err := s.janitor.update(activeSilences, alertmanager.SilencesMatchingAlerts(activeSilences, alerts), s.Config.SilenceJanitor.StaleAfter)
+	if err != nil {
+		// Report nonetheless. Stale silences might be reported again after a restart.
+		s.logger.LogError("failed to persist stale silences", err)
+	}
+	metrics.StaleSilences.Set(float64(staleCount))
+
+	for _, sil := range unreported {
+		if err := s.reportStaleSilence(sil); err != nil {
+			s.logger.LogError("failed to report stale silence", err, "silenceID", sil.ID)
+		}
+	}
+}
+
+// reportStaleSilence posts the stale silence to the configured channel.
+// The owner is asked to confirm expiring the silence if enabled.
+func (s *Stargate) reportStaleSilence(sil *types.Silence) error {
+	owner := sil.CreatedBy
+	if origin, ok := s.silenceOrigin(sil); ok {
+		owner = fmt.Sprintf("<@%s>", origin.UserID)
+	}
+
+	text := fmt.Sprintf(
+		"Silence <%s|%s> `%s` by %s has not matched any alert for %s. It ends at %s.",
+		s.alertmanagerClient.LinkToSilence(sil.ID), sil.ID, sil.Matchers.String(), owner,
+		util.HumanizedDurationString(s.janitor.sinceLastMatch(sil.ID)), sil.EndsAt.In(s.calendar.Location).Format(silenceEndFormat),
+	)
+
+	blocks := []slack.Block{slack.NewSectionBlock(text)}
+	if s.Config.SilenceJanitor.ExpireWithConfirmation {
+		text += fmt.Sprintf(" %s, please confirm expiring it.", owner)
+		blocks = slack.StaleSilenceButtons.Blocks(text, sil.ID)
+	}
+
+	_, err := s.slack.PostBlocks(s.Config.SilenceJanitor.Channel, text, blocks, "")
+	return err
+}
+
+// handleStaleSilence expires or keeps a stale silence as confirmed by its owner.
+// Silences without known owner can be confirmed by users allowed to expire them.
+func (s *Stargate) handleStaleSilence(responder *slack.Responder, action slack.BlockAction) {
+	staleAction, silenceID, err := slack.StaleSilenceButtons.FromBlockAction(action)
+	if err != nil {
+		responder.Fail("failed to parse silence from button", err)
+		return
+	}
+
+	sil, err := s.alertmanagerClient.GetSilenceByID(silenceID)
+	if err != nil {
+		responder.Fail(fmt.Sprintf("failed to get silence %s", silenceID), err, "component", "alertmanager")
+		return
+	}
+	if sil.Status.State == types.SilenceStateExpired {
+		s.replaceOrRespond(responder, fmt.Sprintf("Silence %s `%s` already expired.", sil.ID, sil.Matchers.String()))
+		return
+	}
+
+	if origin, ok := s.silenceOrigin(sil); ok && origin.UserID != responder.UserID {
+		responder.Deny(fmt.Sprintf("only the owner <@%s> can confirm", origin.UserID))
+		return
+	}
+	if !s.slack.IsUserAuthorized(responder.UserID) {
+		responder.Deny("you are not a member of a user group authorized to interact with the stargate")
+		return
+	}
+
+	if staleAction == slack.StaleSilenceAction.Keep {
+		s.replaceOrRespond(responder, fmt.Sprintf("<@%s> keeps silence <%s|%s> `%s`.", responder.UserID, s.alertmanagerClient.LinkToSilence(sil.ID), sil.ID, sil.Matchers.String()))
+		return
+	}
+
+	if !s.authorize(responder, policy.Request{Action: policy.Action.ExpireSilence, Labels: matcherLabels(sil.Matchers)}) {
+		return
+	}
+
+	if err := s.alertmanagerClient.ExpireSilence(sil.ID); err != nil {
+		responder.Fail(fmt.Sprintf("failed to expire silence %s", sil.ID), err, "component", "alertmanager")
+		metrics.FailedOperationsTotal.WithLabelValues("expire_silence", metrics.BackendAlertmanager).Inc()
+		return
+	}
+	metrics.SuccessfulOperationsTotal.WithLabelValues("expire_silence", metrics.BackendAlertmanager).Inc()
+	metrics.ExpiredStaleSilencesTotal.Inc()
+
+	s.replaceOrRespond(responder, fmt.Sprintf("<@%s> expired stale silence <%s|%s> `%s`.", responder.UserID, s.alertmanagerClient.LinkToSilence(sil.ID), sil.ID, sil.Matchers.String()))
+}
@@ -0,0 +1,116 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/config"
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/sapcc/stargate/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSilenceJanitor returns a janitor persisting to the file.
+func newTestSilenceJanitor(t *testing.T, filePath string) *silenceJanitor {
+	staleSilences, err := store.NewStaleSilenceStore(filePath, log.NewLogger(false))
+	require.NoError(t, err, "loading the stale silences must not raise an error")
+	return &silenceJanitor{store: staleSilences}
+}
+
+func newTestStaleSilence(id string) *types.Silence {
+	sil := &types.Silence{
+		ID:        id,
+		Matchers:  types.Matchers{{Name: "alertname", Value: "NodeDown"}, {Name: "region", Value: "eu-de-1"}},
+		StartsAt:  time.Now().Add(-48 * time.Hour),
+		EndsAt:    time.Now().Add(48 * time.Hour),
+		CreatedBy: "Jane Doe (JDOE)",
+		Comment:   "maintenance\n" + silence.Origin{UserID: "U0001"}.Marker(),
+	}
+	sil.Status.State = types.SilenceStateActive
+	return sil
+}
+
+func TestOnlyOwnerCanExpireStaleSilence(t *testing.T) {
+	s := newTestStargate(nil)
+	defer s.close()
+
+	sil := newTestStaleSilence("silence-1")
+	s.alertmanagerAPI.addSilence(sil)
+	expire := slack.BlockAction{ActionID: slack.BlockActionID.StaleSilence + slack.StaleSilenceAction.Expire, Value: sil.ID}
+
+	s.handleStaleSilence(s.newResponder("U0002"), expire)
+	assert.Contains(t, s.slackAPI.lastResponse(), "only the owner <@U0001> can confirm", "other users should be denied")
+	assert.Equal(t, types.SilenceStateActive, s.alertmanagerAPI.silence(sil.ID).Status.State, "the silence should not be expired by other users")
+
+	s.handleStaleSilence(s.newResponder("U0001"), expire)
+	assert.Equal(t, types.SilenceStateExpired, s.alertmanagerAPI.silence(sil.ID).Status.State, "the silence should be expired by its owner")
+}
+
+func TestReportStaleSilenceFails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stargate")
+	require.NoError(t, err, "creating a temporary directory must not raise an error")
+	defer os.RemoveAll(dir)
+
+	for _, withConfirmation := range []bool{false, true} {
+		s := newTestStargate(func(cfg *config.Config) {
+			cfg.SilenceJanitor.Channel = testMissingChannel
+			cfg.SilenceJanitor.ExpireWithConfirmation = withConfirmation
+		})
+		s.janitor = newTestSilenceJanitor(t, filepath.Join(dir, "stale-silences.yaml"))
+
+		err := s.reportStaleSilence(newTestStaleSilence("silence-1"))
+		assert.Error(t, err, "failing to post the report should raise an error with confirmation %t", withConfirmation)
+		s.close()
+	}
+}
+
+func TestSilenceJanitorRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stargate")
+	require.NoError(t, err, "creating a temporary directory must not raise an error")
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "stale-silences.yaml")
+
+	sil := newTestStaleSilence("silence-1")
+	janitor := newTestSilenceJanitor(t, filePath)
+	require.NoError(t, janitor.store.Set(map[string]time.Time{sil.ID: time.Now().Add(-2 * time.Hour)}, map[string]bool{}), "setting the silences must not raise an error")
+
+	// The silence is still stale after a restart.
+	janitor = newTestSilenceJanitor(t, filePath)
+	staleCount, unreported, err := janitor.update([]*types.Silence{sil}, map[string]bool{}, time.Hour)
+	require.NoError(t, err, "updating the silences must not raise an error")
+	assert.Equal(t, 1, staleCount, "the silence should be stale")
+	assert.Len(t, unreported, 1, "the stale silence should be reported")
+
+	// The report is not repeated after another restart.
+	janitor = newTestSilenceJanitor(t, filePath)
+	staleCount, unreported, err = janitor.update([]*types.Silence{sil}, map[string]bool{}, time.Hour)
+	require.NoError(t, err, "updating the silences must not raise an error")
+	assert.Equal(t, 1, staleCount, "the silence should still be stale")
+	assert.Empty(t, unreported, "the stale silence should not be reported again")
+}
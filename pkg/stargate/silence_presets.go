@@ -0,0 +1,115 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"time"
+
+	"github.com/prometheus/alertmanager/client"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/sapcc/stargate/pkg/slack"
+)
+
+// newBusinessCalendar returns the configured business calendar.
+func (s *Stargate) newBusinessCalendar() *silence.Calendar {
+	calendar, err := s.Config.BusinessCalendar.NewCalendar()
+	if err != nil {
+		s.logger.LogFatal("failed to load business calendar", "err", err)
+	}
+	return calendar
+}
+
+// userLocation returns the Slack time zone of the user or the time zone of the business calendar if unknown.
+func (s *Stargate) userLocation(userID string) *time.Location {
+	location, err := s.slack.GetUserLocation(userID)
+	if err != nil {
+		s.logger.LogDebug("using time zone of the business calendar", "userID", userID, "err", err)
+		return s.calendar.Location
+	}
+	return location
+}
+
+// silenceWithPreset creates a silence for the alert as defined by the preset.
+// Returns false if the silence was neither created, previewed nor requested.
+func (s *Stargate) silenceWithPreset(actionCtx alertActionContext, responder *slack.Responder, slackAlert *client.ExtendedAlert, alertname, userName string, alertLabelSets []map[string]string, preset silence.Preset) bool {
+	location := s.userLocation(actionCtx.userID)
+	now := time.Now().In(location)
+	endsAt, err := preset.EndsAt(now, s.calendar)
+	if err != nil {
+		responder.Fail("failed to determine the end of the silence", err, "preset", preset.Action)
+		return false
+	}
+	duration := endsAt.Sub(now)
+
+	// The silence might match more alerts if the preset only uses some labels.
+	// Authorize on the labels of its matchers, so scopes are checked against exactly what is silenced.
+	silencedAlert := &client.ExtendedAlert{
+		Alert: client.Alert{
+			Labels: presetLabelSet(preset, slackAlert.Labels),
+		},
+	}
+	matchers := alertmanager.MatchersFromAlert(silencedAlert)
+	if !s.authorize(responder, policy.Request{Action: policy.Action.Silence, Labels: matcherLabels(matchers), SilenceDuration: duration}) {
+		return false
+	}
+
+	// Only labels shared by all silenced alerts are available in the comment.
+	labels := commonLabels(alertLabelSets)
+
+	comment, err := preset.RenderComment(silence.CommentData{
+		Alertname: alertname,
+		UserName:  userName,
+		Labels:    labels,
+		EndsAt:    endsAt,
+	})
+	if err != nil {
+		responder.Fail("failed to create silence", err, "preset", preset.Action)
+		return false
+	}
+
+	return s.requestSilence(&silenceRequest{
+		matchers:        matchers,
+		endsAt:          endsAt,
+		author:          userName,
+		comment:         comment,
+		subject:         "alert " + alertname,
+		userID:          actionCtx.userID,
+		channel:         actionCtx.channel,
+		threadTimestamp: actionCtx.messageTimestamp,
+		addReaction:     true,
+		location:        location,
+	}, responder)
+}
+
+// presetLabelSet returns the labels of the alert matched by the silence of the preset.
+func presetLabelSet(preset silence.Preset, labelSet client.LabelSet) client.LabelSet {
+	labels := make(map[string]string, len(labelSet))
+	for k, v := range labelSet {
+		labels[string(k)] = string(v)
+	}
+
+	filtered := make(client.LabelSet, len(labels))
+	for k, v := range preset.FilterLabels(labels) {
+		filtered[client.LabelName(k)] = client.LabelValue(v)
+	}
+	return filtered
+}
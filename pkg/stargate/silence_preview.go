@@ -0,0 +1,64 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"time"
+
+	"github.com/sapcc/stargate/pkg/slack"
+)
+
+// silencePreviewTimeout after which a previewed silence can no longer be confirmed.
+const silencePreviewTimeout = 15 * time.Minute
+
+// handleSilencePreview creates or discards a previewed silence as confirmed or canceled by the requesting user.
+func (s *Stargate) handleSilencePreview(responder *slack.Responder, action slack.BlockAction) {
+	previewAction, id, err := slack.SilencePreviewButtons.FromBlockAction(action)
+	if err != nil {
+		responder.Fail("failed to parse silence from button", err)
+		return
+	}
+
+	req, ok := s.pendingSilences.get(id)
+	if !ok {
+		responder.Respond("Sorry, the silence is no longer pending. Please request it again.")
+		return
+	}
+	if req.userID != responder.UserID {
+		responder.Deny("only the requesting user can confirm the silence")
+		return
+	}
+
+	// Buttons might be clicked twice.
+	if _, ok := s.pendingSilences.remove(id); !ok {
+		return
+	}
+
+	if previewAction == slack.SilencePreviewAction.Cancel {
+		s.replaceOrRespond(responder, "Canceled the silence of "+req.subject+".")
+		return
+	}
+
+	message, ok := s.submitSilence(req, responder)
+	if !ok {
+		return
+	}
+	s.replaceOrRespond(responder, message)
+}
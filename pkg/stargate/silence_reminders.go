@@ -0,0 +1,192 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/sapcc/stargate/pkg/store"
+	"github.com/sapcc/stargate/pkg/util"
+)
+
+// newReminderStore returns the store of sent reminders if reminders are enabled or nil.
+func (s *Stargate) newReminderStore() *store.ReminderStore {
+	if !s.Config.SilenceReminders.Enabled {
+		return nil
+	}
+
+	reminders, err := store.NewReminderStore(s.opts.RemindersFilePath, s.logger)
+	if err != nil {
+		s.logger.LogFatal("failed to load reminders", "err", err)
+	}
+	return reminders
+}
+
+// runSilenceReminders periodically reminds the creators of silences before they expire.
+func (s *Stargate) runSilenceReminders(stopCh <-chan struct{}) {
+	s.logger.LogInfo("reminding creators of expiring silences", "interval", s.Config.SilenceReminders.Interval.String())
+	ticker := time.NewTicker(s.Config.SilenceReminders.Interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.remindSilenceCreators()
+		case <-stopCh:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// remindSilenceCreators reminds the creators of silences created via the stargate which expire soon.
+// Every silence is reminded once per end, so silences extended otherwise are reminded again.
+func (s *Stargate) remindSilenceCreators() {
+	silences, err := s.alertmanagerClient.ListSilences(alertmanager.NewDefaultFilter())
+	if err != nil {
+		s.logger.LogError("failed to list silences", err)
+		return
+	}
+
+	activeSilenceIDs := make(map[string]bool, len(silences))
+	for _, sil := range silences {
+		if sil.Status.State != types.SilenceStateActive {
+			continue
+		}
+		activeSilenceIDs[sil.ID] = true
+
+		if time.Until(sil.EndsAt) > s.Config.SilenceReminders.Before || s.reminders.IsReminded(sil.ID, sil.EndsAt) {
+			continue
+		}
+
+		origin, ok := s.silenceOrigin(sil)
+		if !ok {
+			continue
+		}
+
+		if err := s.remindSilenceCreator(sil, origin); err != nil {
+			s.logger.LogError("failed to remind creator of silence", err, "silenceID", sil.ID, "userID", origin.UserID)
+			continue
+		}
+		if err := s.reminders.SetReminded(sil.ID, sil.EndsAt); err != nil {
+			s.logger.LogError("failed to persist reminder", err, "silenceID", sil.ID)
+		}
+	}
+
+	if err := s.reminders.Prune(activeSilenceIDs); err != nil {
+		s.logger.LogError("failed to persist reminders", err)
+	}
+}
+
+// silenceOrigin returns the origin of a silence created via the stargate.
+// Silences without the marker of their origin are skipped, as names like the creator are neither unique nor verified.
+func (s *Stargate) silenceOrigin(sil *types.Silence) (silence.Origin, bool) {
+	origin, ok := silence.ParseOrigin(sil.Comment)
+	if !ok {
+		s.logger.LogDebug("unknown origin of silence", "silenceID", sil.ID, "createdBy", sil.CreatedBy)
+	}
+	return origin, ok
+}
+
+// remindSilenceCreator posts the reminder to the thread the silence was requested in or sends it as direct message.
+func (s *Stargate) remindSilenceCreator(sil *types.Silence, origin silence.Origin) error {
+	channel, threadTimestamp := origin.UserID, ""
+	if s.Config.SilenceReminders.InThread && origin.Channel != "" {
+		channel, threadTimestamp = origin.Channel, origin.ThreadTimestamp
+	}
+
+	text := fmt.Sprintf(
+		"<@%s> your silence <%s|%s> `%s` expires in %s at %s.",
+		origin.UserID, s.alertmanagerClient.LinkToSilence(sil.ID), sil.ID, sil.Matchers.String(),
+		util.HumanizedDurationString(time.Until(sil.EndsAt)), sil.EndsAt.In(s.userLocation(origin.UserID)).Format(silenceEndFormat),
+	)
+	extendBy := util.HumanizedDurationString(s.Config.SilenceReminders.ExtendBy)
+
+	_, err := s.slack.PostBlocks(channel, text, slack.SilenceReminderButtons.WithText(slack.SilenceReminderAction.Extend, "Extend by "+extendBy).Blocks(text, sil.ID), threadTimestamp)
+	return err
+}
+
+// handleSilenceReminder extends the silence or lets it expire as chosen by the user.
+func (s *Stargate) handleSilenceReminder(responder *slack.Responder, action slack.BlockAction, threadTimestamp string) {
+	reminderAction, silenceID, err := slack.SilenceReminderButtons.FromBlockAction(action)
+	if err != nil {
+		responder.Fail("failed to parse silence from button", err)
+		return
+	}
+
+	sil, err := s.alertmanagerClient.GetSilenceByID(silenceID)
+	if err != nil {
+		responder.Fail(fmt.Sprintf("failed to get silence %s", silenceID), err, "component", "alertmanager")
+		return
+	}
+	if sil.Status.State == types.SilenceStateExpired {
+		s.replaceOrRespond(responder, fmt.Sprintf("Silence %s `%s` already expired.", sil.ID, sil.Matchers.String()))
+		return
+	}
+
+	location := s.userLocation(responder.UserID)
+	if reminderAction == slack.SilenceReminderAction.LetExpire {
+		s.replaceOrRespond(responder, fmt.Sprintf(
+			"<@%s> lets silence <%s|%s> `%s` expire at %s.",
+			responder.UserID, s.alertmanagerClient.LinkToSilence(sil.ID), sil.ID, sil.Matchers.String(), sil.EndsAt.In(location).Format(silenceEndFormat),
+		))
+		return
+	}
+
+	if !s.slack.IsUserAuthorized(responder.UserID) {
+		responder.Deny("you are not a member of a user group authorized to interact with the stargate")
+		return
+	}
+
+	endsAt := sil.EndsAt.Add(s.Config.SilenceReminders.ExtendBy)
+	if !s.authorize(responder, policy.Request{Action: policy.Action.Silence, Labels: matcherLabels(sil.Matchers), SilenceDuration: time.Until(endsAt)}) {
+		return
+	}
+
+	userName, err := s.slack.GetUserNameByID(responder.UserID)
+	if err != nil {
+		responder.Fail("failed to get user name", err, "userID", responder.UserID)
+		return
+	}
+
+	extendBy := util.HumanizedDurationString(s.Config.SilenceReminders.ExtendBy)
+	requested := s.requestSilence(&silenceRequest{
+		matchers:        sil.Matchers,
+		endsAt:          endsAt,
+		author:          userName,
+		comment:         fmt.Sprintf("extended by %s via reminder", extendBy),
+		subject:         fmt.Sprintf("`%s`", sil.Matchers.String()),
+		userID:          responder.UserID,
+		channel:         responder.Channel,
+		threadTimestamp: threadTimestamp,
+		location:        location,
+	}, responder)
+	if !requested {
+		return
+	}
+
+	if err := responder.ReplaceOriginal(fmt.Sprintf("<@%s> requested to extend silence %s `%s` by %s.", responder.UserID, sil.ID, sil.Matchers.String(), extendBy), nil); err != nil {
+		responder.Logger.LogDebug("failed to replace reminder", "err", err)
+	}
+}
@@ -0,0 +1,76 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/config"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/sapcc/stargate/pkg/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReminderSilence(id, comment string) *types.Silence {
+	sil := &types.Silence{
+		ID:        id,
+		Matchers:  types.Matchers{{Name: "alertname", Value: "NodeDown"}, {Name: "region", Value: "eu-de-1"}},
+		StartsAt:  time.Now().Add(-time.Hour),
+		EndsAt:    time.Now().Add(30 * time.Minute),
+		CreatedBy: "Jane Doe (JDOE)",
+		Comment:   comment,
+	}
+	sil.Status.State = types.SilenceStateActive
+	return sil
+}
+
+func TestSilenceReminderExtensionRequiresApproval(t *testing.T) {
+	s := newTestStargate(func(cfg *config.Config) {
+		cfg.SilenceApproval.MaxDuration = 12 * time.Hour
+	})
+	defer s.close()
+
+	sil := newTestReminderSilence("silence-1", "maintenance\n"+silence.Origin{UserID: "U0001"}.Marker())
+	s.alertmanagerAPI.addSilence(sil)
+
+	extend := slack.BlockAction{ActionID: slack.BlockActionID.SilenceReminder + slack.SilenceReminderAction.Extend, Value: sil.ID}
+	s.handleSilenceReminder(s.newResponder("U0001"), extend, "")
+
+	assert.Equal(t, sil.EndsAt.Unix(), s.alertmanagerAPI.silence(sil.ID).EndsAt.Unix(), "the silence should not be extended without approval")
+	messages := s.slackAPI.postedMessages()
+	require.Len(t, messages, 1, "approval should be requested")
+	assert.Contains(t, messages[0], "requests approval", "approval should be requested")
+	assert.Len(t, s.pendingApprovals.silences, 1, "the extension should be pending approval")
+}
+
+func TestSilenceOriginWithoutMarker(t *testing.T) {
+	s := newTestStargate(nil)
+	defer s.close()
+
+	_, ok := s.silenceOrigin(newTestReminderSilence("silence-1", "created via the alertmanager"))
+	assert.False(t, ok, "silences without marker should not be attributed to a user named like the creator")
+
+	origin, ok := s.silenceOrigin(newTestReminderSilence("silence-2", silence.Origin{UserID: "U0002"}.Marker()))
+	assert.True(t, ok, "the origin should be found")
+	assert.Equal(t, "U0002", origin.UserID, "the user of the marker should be found")
+}
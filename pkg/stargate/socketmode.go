@@ -0,0 +1,40 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"github.com/sapcc/stargate/pkg/slack"
+)
+
+// newSocketModeClient returns a socket mode client feeding into the same handlers as the HTTP endpoints.
+func (s *Stargate) newSocketModeClient() *slack.SocketModeClient {
+	return slack.NewSocketModeClient(
+		s.Config.Slack.AppLevelToken,
+		s.Config.Slack.Command,
+		slack.SocketModeHandlers{
+			Interactive: func(payload string) {
+				s.handleSlackInteraction(payload, true)
+			},
+			SlashCommand: s.handleSlackCommand,
+			Event:        s.slack.HandleEventsAPIEvent,
+		},
+		s.logger,
+	)
+}
@@ -28,7 +28,11 @@ import (
 	"github.com/sapcc/stargate/pkg/api"
 	"github.com/sapcc/stargate/pkg/config"
 	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/opsgenie"
+	"github.com/sapcc/stargate/pkg/pager"
 	"github.com/sapcc/stargate/pkg/pagerduty"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/silence"
 	"github.com/sapcc/stargate/pkg/slack"
 	"github.com/sapcc/stargate/pkg/store"
 )
@@ -38,10 +42,30 @@ type Stargate struct {
 	v1API              *api.API
 	logger             log.Logger
 	alertmanagerClient *alertmanager.Client
-	pagerdutyClient    *pagerduty.Client
+	pager              pager.Pager
 	slack              *slack.Client
 	opts               config.Options
 	alertStore         *store.AlertStore
+	preferences        *store.PreferenceStore
+	noteSync           *noteSync
+	userMapping        *pager.UserMapping
+	authorizer         *policy.Authorizer
+	// reactionActions maps emoji names to actions.
+	reactionActions map[string]string
+	// calendar is used to compute the end of silences.
+	calendar *silence.Calendar
+	// pendingSilences await confirmation by the requesting user.
+	pendingSilences *pendingSilences
+	// pendingApprovals await approval by another user.
+	pendingApprovals *pendingSilences
+	// reminders persists the reminders of expiring silences if enabled.
+	reminders *store.ReminderStore
+	// janitor tracks stale silences if enabled.
+	janitor *silenceJanitor
+	// maintenance are the maintenance windows silences are created for if enabled.
+	maintenance *maintenanceWindows
+	// declaredSilences reconciles the silences declared in a directory if enabled.
+	declaredSilences *declaredSilences
 
 	Config config.Config
 }
@@ -58,21 +82,58 @@ func New(opts config.Options, logger log.Logger) *Stargate {
 		logger.LogFatal("failed to create persister", "err", err)
 	}
 
+	preferences, err := store.NewPreferenceStore(opts.PreferencesFilePath, logger)
+	if err != nil {
+		logger.LogFatal("failed to load preferences", "err", err)
+	}
+
 	sg := &Stargate{
 		Config:             cfg,
 		slack:              slack.NewClient(cfg, opts, logger),
 		opts:               opts,
 		alertmanagerClient: alertmanager.New(cfg, logger),
-		pagerdutyClient:    pagerduty.NewClient(cfg, logger),
+		pager:              newPager(cfg, logger),
 		alertStore:         store.NewAlertStore(cfg, opts.RecheckInterval, persister, logger),
+		preferences:        preferences,
+		authorizer:         policy.NewAuthorizer(cfg.Authorization.Policies, cfg.Slack.AuthorizedGroups),
+		pendingSilences:    newPendingSilences(silencePreviewTimeout, nil),
 		logger:             logger,
 	}
 
+	if sg.pager != nil {
+		if err := sg.pager.Check(); err != nil {
+			logger.LogError("pager check failed. acknowledging incidents might not work", err, "pager", sg.pager.Name())
+		}
+	}
+
+	sg.noteSync = newNoteSync(sg)
+	sg.userMapping = sg.newUserMapping()
+	sg.calendar = sg.newBusinessCalendar()
+	sg.pendingApprovals = newPendingSilences(cfg.SilenceApproval.Timeout, sg.silenceApprovalTimedOut)
+	sg.reminders = sg.newReminderStore()
+	sg.janitor = sg.newSilenceJanitor()
+	if cfg.Maintenance.Enabled {
+		sg.maintenance = sg.newMaintenanceWindows()
+	}
+	if cfg.DeclaredSilences.Enabled {
+		sg.declaredSilences = &declaredSilences{}
+	}
+	sg.slack.SetMessageHandler(sg.handleSlackMessage)
+	sg.slack.SetEventHandler(slack.InnerEventType.AppHomeOpened, sg.handleAppHomeOpened)
+
+	sg.reactionActions = sg.newReactionActions()
+	if len(sg.reactionActions) > 0 {
+		sg.slack.SetEventHandler(slack.InnerEventType.ReactionAdded, sg.handleReactionAdded)
+	}
+
 	v1API := api.NewAPI(cfg, logger)
 
 	// The v1 endpoint that accepts slack message action events.
 	v1API.AddRouteV1(http.MethodPost, "/slack/event", sg.HandleSlackMessageActionEvent)
 
+	// The v1 endpoint that accepts events via the slack Events API.
+	v1API.AddRouteV1(http.MethodPost, "/slack/events", sg.HandleSlackEvents)
+
 	// The v1 endpoint that accepts slack commands.
 	v1API.AddRouteV1(http.MethodPost, "/slack/command", sg.HandleSlackCommand)
 
@@ -88,16 +149,42 @@ func New(opts config.Options, logger log.Logger) *Stargate {
 	// The v1 endpoint that gets a silence by id.
 	v1API.AddRouteV1WithBasicAuth(http.MethodGet, "/silence/{silenceID}", sg.HandleGetSilenceByID)
 
+	// The v1 endpoints that list and add maintenance windows.
+	if sg.maintenance != nil {
+		v1API.AddRouteV1WithBasicAuth(http.MethodGet, "/maintenance", sg.HandleListMaintenanceWindows)
+		v1API.AddRouteV1WithBasicAuth(http.MethodPost, "/maintenance", sg.HandlePostMaintenanceWindow)
+	}
+
+	// The v1 endpoint that shows the drift of the declared silences found during the last reconciliation.
+	if sg.declaredSilences != nil {
+		v1API.AddRouteV1WithBasicAuth(http.MethodGet, "/declared-silences", sg.HandleGetDeclaredSilences)
+	}
+
 	// The internal v1 endpoint useful for debugging.
 	v1API.AddRouteV1WithBasicAuth(http.MethodGet, "/-/store/alerts", sg.HandleInternalListAlertsFromStore)
 	v1API.AddRouteV1WithBasicAuth(http.MethodPost, "/-/store/acknowledge", sg.HandleInternalAcknowledgeAlert)
 	v1API.AddRouteV1WithBasicAuth(http.MethodGet, "/-/alertmanager/alerts", sg.HandleInternalListAlertsFromAlertmanager)
-	v1API.AddRouteV1WithBasicAuth(http.MethodGet, "/-/pagerduty/incidents", sg.HandleInternalListPagerdutyIncident)
+	v1API.AddRouteV1WithBasicAuth(http.MethodGet, "/-/pager/incidents", sg.HandleInternalListPagerIncidents)
+	// Deprecated: Use /-/pager/incidents instead.
+	v1API.AddRouteV1WithBasicAuth(http.MethodGet, "/-/pagerduty/incidents", sg.HandleInternalListPagerIncidents)
 
 	sg.v1API = v1API
 	return sg
 }
 
+// newPager creates the pager backend configured via `pager`.
+// Returns nil if the pager integration is disabled.
+func newPager(cfg config.Config, logger log.Logger) pager.Pager {
+	switch cfg.Pager {
+	case pager.Backend.Pagerduty:
+		return pagerduty.NewClient(cfg, logger)
+	case pager.Backend.Opsgenie:
+		return opsgenie.NewClient(cfg, logger)
+	default:
+		return nil
+	}
+}
+
 // Run starts the stargate
 func (s *Stargate) Run(wg *sync.WaitGroup, stopCh <-chan struct{}) {
 	defer wg.Done()
@@ -112,6 +199,41 @@ func (s *Stargate) Run(wg *sync.WaitGroup, stopCh <-chan struct{}) {
 	// start alert store
 	go s.alertStore.Run(wg, stopCh)
 
+	// mirror incident notes to slack
+	if s.noteSync != nil {
+		go s.noteSync.Run(stopCh)
+	}
+
+	// reload the user mapping
+	if s.userMapping != nil {
+		go s.runUserMappingReload(stopCh)
+	}
+
+	// remind creators of expiring silences
+	if s.reminders != nil {
+		go s.runSilenceReminders(stopCh)
+	}
+
+	// report stale silences
+	if s.janitor != nil {
+		go s.runSilenceJanitor(stopCh)
+	}
+
+	// create silences of maintenance windows
+	if s.maintenance != nil {
+		go s.runMaintenance(stopCh)
+	}
+
+	// reconcile declared silences
+	if s.declaredSilences != nil {
+		go s.runDeclaredSilencesReconciliation(stopCh)
+	}
+
+	// receive slack payloads via socket mode
+	if s.Config.Slack.SocketMode {
+		go s.newSocketModeClient().Run(stopCh)
+	}
+
 	// start API
 	go func() {
 		if err := s.v1API.Serve(); err != nil {
@@ -0,0 +1,235 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	slackapi "github.com/nlopes/slack"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/sapcc/stargate/pkg/alertmanager"
+	"github.com/sapcc/stargate/pkg/config"
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/policy"
+	"github.com/sapcc/stargate/pkg/slack"
+)
+
+// testMissingChannel is a channel messages cannot be posted to.
+const testMissingChannel = "C0MISSING"
+
+// testSlackAPI fakes the Slack Web API and the response URLs of interactions.
+// The members of the user group 'operators' are authorized.
+type testSlackAPI struct {
+	mtx sync.Mutex
+	// messages posted to channels.
+	messages []string
+	// responses to the acting user.
+	responses []string
+}
+
+func (api *testSlackAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+
+	var body map[string]interface{}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = map[string]interface{}{"text": r.PostForm.Get("text")}
+	}
+
+	switch strings.TrimPrefix(r.URL.Path, "/") {
+	case "response":
+		api.responses = append(api.responses, fmt.Sprint(body["text"]))
+		fmt.Fprint(w, `ok`)
+	case "usergroups.list":
+		fmt.Fprint(w, `{"ok":true,"usergroups":[{"id":"S0001","name":"operators"}]}`)
+	case "usergroups.users.list":
+		fmt.Fprint(w, `{"ok":true,"users":["U0001","U0002"]}`)
+	case "users.info":
+		fmt.Fprint(w, `{"ok":true,"user":{"id":"U0001","name":"jdoe","real_name":"Jane Doe","tz":"UTC"}}`)
+	case "chat.postMessage":
+		if body["channel"] == testMissingChannel {
+			fmt.Fprint(w, `{"ok":false,"error":"channel_not_found"}`)
+			return
+		}
+		api.messages = append(api.messages, fmt.Sprint(body["text"]))
+		fmt.Fprint(w, `{"ok":true,"channel":"C0001","ts":"1550000000.000100"}`)
+	default:
+		fmt.Fprint(w, `{"ok":true}`)
+	}
+}
+
+func (api *testSlackAPI) lastResponse() string {
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+	if len(api.responses) == 0 {
+		return ""
+	}
+	return api.responses[len(api.responses)-1]
+}
+
+func (api *testSlackAPI) postedMessages() []string {
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+	return append([]string{}, api.messages...)
+}
+
+// testAlertmanagerAPI fakes the silences API of the Alertmanager. No alerts are firing.
+type testAlertmanagerAPI struct {
+	mtx      sync.Mutex
+	silences map[string]*types.Silence
+}
+
+func (api *testAlertmanagerAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+
+	var data interface{}
+	switch {
+	case r.URL.Path == "/api/v1/alerts":
+		data = []interface{}{}
+	case r.URL.Path == "/api/v1/silences" && r.Method == http.MethodGet:
+		silences := make([]*types.Silence, 0, len(api.silences))
+		for _, sil := range api.silences {
+			silences = append(silences, sil)
+		}
+		data = silences
+	case r.URL.Path == "/api/v1/silences" && r.Method == http.MethodPost:
+		var sil types.Silence
+		if err := json.NewDecoder(r.Body).Decode(&sil); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if sil.ID == "" {
+			sil.ID = fmt.Sprintf("silence-%d", len(api.silences)+1)
+		}
+		sil.Status.State = types.SilenceStateActive
+		api.silences[sil.ID] = &sil
+		data = map[string]string{"silenceId": sil.ID}
+	case strings.HasPrefix(r.URL.Path, "/api/v1/silence/"):
+		sil, ok := api.silences[strings.TrimPrefix(r.URL.Path, "/api/v1/silence/")]
+		if !ok {
+			http.Error(w, `{"status":"error","error":"silence not found"}`, http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			sil.Status.State = types.SilenceStateExpired
+			sil.EndsAt = time.Now()
+		}
+		data = sil
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "data": data})
+}
+
+func (api *testAlertmanagerAPI) addSilence(sil *types.Silence) {
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+	api.silences[sil.ID] = sil
+}
+
+func (api *testAlertmanagerAPI) silence(id string) types.Silence {
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+	return *api.silences[id]
+}
+
+func (api *testAlertmanagerAPI) silenceCount() int {
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+	return len(api.silences)
+}
+
+// testStargate is a stargate using the fake Slack and Alertmanager APIs.
+type testStargate struct {
+	*Stargate
+	slackAPI        *testSlackAPI
+	alertmanagerAPI *testAlertmanagerAPI
+	close           func()
+}
+
+// newTestStargate returns a stargate using the fake Slack and Alertmanager APIs.
+// The configuration can be adapted before the clients are created.
+func newTestStargate(configure func(cfg *config.Config)) *testStargate {
+	slackAPI := &testSlackAPI{}
+	slackServer := httptest.NewServer(slackAPI)
+	previousSlackAPI := slackapi.SLACK_API
+	slackapi.SLACK_API = slackServer.URL + "/"
+
+	alertmanagerAPI := &testAlertmanagerAPI{silences: make(map[string]*types.Silence)}
+	alertmanagerServer := httptest.NewServer(alertmanagerAPI)
+
+	cfg := config.Config{}
+	cfg.AlertManager.URL = alertmanagerServer.URL
+	cfg.Slack.AuthorizedGroups = []string{"operators"}
+	cfg.Slack.IsDisableRTM = true
+	cfg.SilenceGuardrail.Disabled = true
+	cfg.SilenceApproval.Channel = "C0APPROVAL"
+	cfg.SilenceApproval.Timeout = time.Hour
+	cfg.SilenceReminders.ExtendBy = 24 * time.Hour
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	logger := log.NewLogger(false)
+	s := &Stargate{
+		Config:             cfg,
+		logger:             logger,
+		slack:              slack.NewClient(cfg, config.Options{}, logger),
+		alertmanagerClient: alertmanager.New(cfg, logger),
+		authorizer:         policy.NewAuthorizer(cfg.Authorization.Policies, cfg.Slack.AuthorizedGroups),
+		pendingSilences:    newPendingSilences(silencePreviewTimeout, nil),
+		pendingApprovals:   newPendingSilences(cfg.SilenceApproval.Timeout, nil),
+	}
+	s.calendar = s.newBusinessCalendar()
+
+	return &testStargate{
+		Stargate:        s,
+		slackAPI:        slackAPI,
+		alertmanagerAPI: alertmanagerAPI,
+		close: func() {
+			slackapi.SLACK_API = previousSlackAPI
+			slackServer.Close()
+			alertmanagerServer.Close()
+		},
+	}
+}
+
+// newResponder returns a responder for the user in a direct message whose responses are recorded by the fake Slack API.
+func (s *testStargate) newResponder(userID string) *slack.Responder {
+	return s.slack.NewResponder("D0001", userID, slackapi.SLACK_API+"response")
+}
@@ -0,0 +1,152 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package stargate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sapcc/stargate/pkg/pager"
+)
+
+// userSource describes how the pager user was resolved.
+var userSource = struct {
+	MappingFile,
+	SlackProfileField,
+	SlackEmail string
+}{
+	"user mapping file",
+	"slack profile field",
+	"slack email address",
+}
+
+// newUserMapping loads the user mapping file if configured or returns nil.
+func (s *Stargate) newUserMapping() *pager.UserMapping {
+	if s.Config.UserMapping.File == "" {
+		return nil
+	}
+
+	userMapping, err := pager.NewUserMapping(s.Config.UserMapping.File, s.logger)
+	if err != nil {
+		s.logger.LogFatal("failed to load user mapping", "err", err)
+	}
+	return userMapping
+}
+
+// runUserMappingReload periodically reloads the user mapping file.
+func (s *Stargate) runUserMappingReload(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(s.Config.UserMapping.ReloadInterval)
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.userMapping.Reload(); err != nil {
+				s.logger.LogError("failed to reload user mapping", err, "file", s.Config.UserMapping.File)
+			}
+		case <-stopCh:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// pagerUserBySlackUserID returns the pager user acting on behalf of the Slack user and how it was resolved.
+// The user mapping file takes precedence over the Slack profile field, which takes precedence over the email address.
+// An error is returned if neither a pager user ID nor an email address was found.
+func (s *Stargate) pagerUserBySlackUserID(slackUserID string) (pager.User, string, error) {
+	user := pager.User{}
+	source := userSource.SlackEmail
+
+	userName, err := s.slack.GetUserNameByID(slackUserID)
+	if err != nil {
+		s.logger.LogError("user not found by id", err, "userID", slackUserID)
+	}
+	user.Name = userName
+
+	userEmail, emailErr := s.slack.GetUserEmailByID(slackUserID)
+	if emailErr != nil {
+		s.logger.LogError("failed to get email of user", emailErr, "userID", slackUserID, "userName", userName)
+	}
+	user.Email = userEmail
+
+	if s.userMapping != nil {
+		if pagerUserID, ok := s.userMapping.Get(slackUserID); ok {
+			user.ID = pagerUserID
+			return user, userSource.MappingFile, nil
+		}
+	}
+
+	if fieldID := s.Config.UserMapping.SlackProfileField; fieldID != "" {
+		pagerUserID, err := s.slack.GetUserProfileField(slackUserID, fieldID)
+		if err != nil {
+			s.logger.LogDebug("pager user id not found in slack profile", "userID", slackUserID, "err", err.Error())
+		} else {
+			user.ID = pagerUserID
+			source = userSource.SlackProfileField
+		}
+	}
+
+	if user.String() == "" {
+		if emailErr == nil {
+			emailErr = errors.New("no email address")
+		}
+		return user, source, errors.Wrapf(emailErr, "no pager user found for slack user %s", slackUserID)
+	}
+	return user, source, nil
+}
+
+// whoAmI shows the identities resolved for the user of 'whoami'.
+func (s *Stargate) whoAmI(ctx commandContext) {
+	responder := ctx.responder
+	user, source, err := s.pagerUserBySlackUserID(ctx.userID)
+
+	msg := fmt.Sprintf("Slack user: <@%s> (id: %s, name: %s, email: %s)", ctx.userID, ctx.userID, user.Name, orNone(user.Email))
+	if s.pager == nil {
+		responder.Respond(msg + "\nNo pager is configured.")
+		return
+	}
+
+	msg += fmt.Sprintf("\n%s user: %s (resolved by %s)", s.pager.Name(), orNone(user.String()), source)
+	if err != nil {
+		responder.Respond(fmt.Sprintf("%s\nFailed to resolve the %s user: %s", msg, s.pager.Name(), err.Error()))
+		return
+	}
+
+	if userResolver, ok := s.pager.(pager.UserResolver); ok {
+		actingUser, err := userResolver.ResolveUser(user)
+		if err != nil {
+			msg += fmt.Sprintf("\nFailed to resolve the %s user: %s", s.pager.Name(), err.Error())
+		} else {
+			msg += fmt.Sprintf("\nActing as %s user: %s (id: %s, email: %s)", s.pager.Name(), actingUser.Name, actingUser.ID, actingUser.Email)
+			if actingUser.ID != user.ID && actingUser.Email != user.Email {
+				msg += " which is the default user"
+			}
+		}
+	}
+
+	responder.Respond(msg)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
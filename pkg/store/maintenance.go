@@ -0,0 +1,102 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package store
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/silence"
+)
+
+// MaintenanceStore persists the maintenance windows added via the API in a YAML file.
+type MaintenanceStore struct {
+	logger   log.Logger
+	filePath string
+
+	mtx sync.RWMutex
+	// windows maps the name of a window to the window.
+	windows map[string]silence.MaintenanceWindow
+}
+
+// NewMaintenanceStore returns a new MaintenanceStore and loads the windows from the file.
+func NewMaintenanceStore(filePath string, logger log.Logger) (*MaintenanceStore, error) {
+	m := &MaintenanceStore{
+		logger:   log.NewLoggerWith(logger, "component", "MaintenanceStore"),
+		filePath: filePath,
+		windows:  make(map[string]silence.MaintenanceWindow),
+	}
+
+	var windows []silence.MaintenanceWindow
+	exists, err := loadYAMLFile(filePath, "maintenance", &windows)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		m.logger.LogInfo("maintenance file does not exist yet", "file", filePath)
+		return m, nil
+	}
+
+	for _, w := range windows {
+		if err := w.Validate(); err != nil {
+			return nil, err
+		}
+		m.windows[w.Name] = w
+	}
+	m.logger.LogInfo("loaded maintenance windows", "file", filePath, "windows", len(m.windows))
+	return m, nil
+}
+
+// List returns the windows sorted by name.
+func (m *MaintenanceStore) List() []silence.MaintenanceWindow {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	return m.sortedWindows()
+}
+
+// Set adds or replaces the validated window and persists the windows.
+func (m *MaintenanceStore) Set(w silence.MaintenanceWindow) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	previous, existed := m.windows[w.Name]
+	m.windows[w.Name] = w
+	if err := storeYAMLFile(m.filePath, "maintenance", m.sortedWindows()); err != nil {
+		if existed {
+			m.windows[w.Name] = previous
+		} else {
+			delete(m.windows, w.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *MaintenanceStore) sortedWindows() []silence.MaintenanceWindow {
+	windows := make([]silence.MaintenanceWindow, 0, len(m.windows))
+	for _, w := range m.windows {
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Name < windows[j].Name
+	})
+	return windows
+}
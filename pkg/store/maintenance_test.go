@@ -0,0 +1,64 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/sapcc/stargate/pkg/silence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stargate")
+	require.NoError(t, err, "creating a temporary directory must not raise an error")
+	defer os.RemoveAll(dir)
+	filePath := path.Join(dir, "maintenance.yaml")
+
+	maintenance, err := NewMaintenanceStore(filePath, log.NewLogger(false))
+	require.NoError(t, err, "a missing maintenance file must not raise an error")
+	assert.Empty(t, maintenance.List(), "there should be no windows")
+
+	window := silence.MaintenanceWindow{
+		Name:     "weekly-db-maintenance",
+		Matchers: []silence.Matcher{{Name: "service", Value: "database"}},
+		Schedule: "0 22 * * SAT",
+		Duration: "4h",
+	}
+	require.NoError(t, window.Validate(), "the window should be valid")
+	require.NoError(t, maintenance.Set(window), "setting the window must not raise an error")
+
+	maintenance, err = NewMaintenanceStore(filePath, log.NewLogger(false))
+	require.NoError(t, err, "loading the windows must not raise an error")
+	windows := maintenance.List()
+	require.Len(t, windows, 1, "the window should be persisted")
+	assert.Equal(t, "weekly-db-maintenance", windows[0].Name, "the name should be equal")
+
+	from := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	start, _, ok := windows[0].Next(from)
+	require.True(t, ok, "the loaded window should be validated")
+	assert.Equal(t, time.Date(2019, time.March, 2, 22, 0, 0, 0, time.UTC), start, "the schedule should be equal")
+}
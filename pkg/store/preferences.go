@@ -0,0 +1,84 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package store
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/sapcc/stargate/pkg/log"
+)
+
+// UserPreferences of a Slack user.
+type UserPreferences struct {
+	// Regions shown in the App Home. All regions if empty.
+	Regions []string `yaml:"regions,omitempty"`
+}
+
+// PreferenceStore persists the preferences of Slack users in a YAML file.
+type PreferenceStore struct {
+	logger   log.Logger
+	filePath string
+
+	mtx         sync.RWMutex
+	preferences map[string]UserPreferences
+}
+
+// NewPreferenceStore returns a new PreferenceStore and loads existing preferences from the file.
+func NewPreferenceStore(filePath string, logger log.Logger) (*PreferenceStore, error) {
+	p := &PreferenceStore{
+		logger:      log.NewLoggerWith(logger, "component", "PreferenceStore"),
+		filePath:    filePath,
+		preferences: make(map[string]UserPreferences),
+	}
+
+	exists, err := loadYAMLFile(filePath, "preferences", &p.preferences)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		p.logger.LogInfo("preferences file does not exist yet", "file", filePath)
+		return p, nil
+	}
+	p.logger.LogInfo("loaded preferences", "file", filePath, "users", len(p.preferences))
+	return p, nil
+}
+
+// Get returns the preferences of the user.
+func (p *PreferenceStore) Get(userID string) UserPreferences {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.preferences[userID]
+}
+
+// SetRegions sets the regions of the user and persists the preferences.
+func (p *PreferenceStore) SetRegions(userID string, regions []string) error {
+	sorted := make([]string, len(regions))
+	copy(sorted, regions)
+	sort.Strings(sorted)
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	prefs := p.preferences[userID]
+	prefs.Regions = sorted
+	p.preferences[userID] = prefs
+	return storeYAMLFile(p.filePath, "preferences", p.preferences)
+}
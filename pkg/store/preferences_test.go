@@ -0,0 +1,49 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferenceStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stargate")
+	require.NoError(t, err, "creating a temporary directory must not raise an error")
+	defer os.RemoveAll(dir)
+	filePath := path.Join(dir, "preferences.yaml")
+
+	prefs, err := NewPreferenceStore(filePath, log.NewLogger(false))
+	require.NoError(t, err, "a missing preferences file must not raise an error")
+	assert.Empty(t, prefs.Get("U0123ABCD").Regions, "there should be no regions for an unknown user")
+
+	err = prefs.SetRegions("U0123ABCD", []string{"eu-nl-1", "eu-de-1"})
+	require.NoError(t, err, "setting the regions must not raise an error")
+
+	prefs, err = NewPreferenceStore(filePath, log.NewLogger(false))
+	require.NoError(t, err, "loading the preferences must not raise an error")
+	assert.Equal(t, []string{"eu-de-1", "eu-nl-1"}, prefs.Get("U0123ABCD").Regions, "the regions should be persisted")
+}
@@ -0,0 +1,94 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sapcc/stargate/pkg/log"
+)
+
+// ReminderStore persists the silences whose creators were reminded of their expiry in a YAML file.
+type ReminderStore struct {
+	logger   log.Logger
+	filePath string
+
+	mtx sync.RWMutex
+	// reminders maps the ID of a silence to the end of the silence the reminder was sent for.
+	reminders map[string]time.Time
+}
+
+// NewReminderStore returns a new ReminderStore and loads the sent reminders from the file.
+func NewReminderStore(filePath string, logger log.Logger) (*ReminderStore, error) {
+	r := &ReminderStore{
+		logger:    log.NewLoggerWith(logger, "component", "ReminderStore"),
+		filePath:  filePath,
+		reminders: make(map[string]time.Time),
+	}
+
+	exists, err := loadYAMLFile(filePath, "reminders", &r.reminders)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		r.logger.LogInfo("reminders file does not exist yet", "file", filePath)
+		return r, nil
+	}
+	r.logger.LogInfo("loaded reminders", "file", filePath, "silences", len(r.reminders))
+	return r, nil
+}
+
+// IsReminded checks whether the reminder was sent for the silence ending at the given time.
+// Extended silences are reminded again.
+func (r *ReminderStore) IsReminded(silenceID string, endsAt time.Time) bool {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	remindedEndsAt, ok := r.reminders[silenceID]
+	return ok && remindedEndsAt.Equal(endsAt)
+}
+
+// SetReminded records the reminder for the silence ending at the given time and persists the reminders.
+func (r *ReminderStore) SetReminded(silenceID string, endsAt time.Time) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.reminders[silenceID] = endsAt.UTC()
+	return storeYAMLFile(r.filePath, "reminders", r.reminders)
+}
+
+// Prune removes the reminders of silences which are no longer active and persists the reminders if any were removed.
+func (r *ReminderStore) Prune(activeSilenceIDs map[string]bool) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	pruned := false
+	for silenceID := range r.reminders {
+		if !activeSilenceIDs[silenceID] {
+			delete(r.reminders, silenceID)
+			pruned = true
+		}
+	}
+	if !pruned {
+		return nil
+	}
+	return storeYAMLFile(r.filePath, "reminders", r.reminders)
+}
@@ -0,0 +1,57 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReminderStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stargate")
+	require.NoError(t, err, "creating a temporary directory must not raise an error")
+	defer os.RemoveAll(dir)
+	filePath := path.Join(dir, "reminders.yaml")
+
+	endsAt := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	reminders, err := NewReminderStore(filePath, log.NewLogger(false))
+	require.NoError(t, err, "a missing reminders file must not raise an error")
+	assert.False(t, reminders.IsReminded("silence1", endsAt), "there should be no reminder for an unknown silence")
+
+	require.NoError(t, reminders.SetReminded("silence1", endsAt), "setting the reminder must not raise an error")
+	require.NoError(t, reminders.SetReminded("silence2", endsAt), "setting the reminder must not raise an error")
+
+	reminders, err = NewReminderStore(filePath, log.NewLogger(false))
+	require.NoError(t, err, "loading the reminders must not raise an error")
+	assert.True(t, reminders.IsReminded("silence1", endsAt), "the reminder should be persisted")
+	assert.False(t, reminders.IsReminded("silence1", endsAt.Add(time.Hour)), "an extended silence should be reminded again")
+
+	require.NoError(t, reminders.Prune(map[string]bool{"silence1": true}), "pruning the reminders must not raise an error")
+	assert.True(t, reminders.IsReminded("silence1", endsAt), "reminders of active silences should be kept")
+	assert.False(t, reminders.IsReminded("silence2", endsAt), "reminders of expired silences should be removed")
+}
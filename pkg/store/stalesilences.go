@@ -0,0 +1,106 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sapcc/stargate/pkg/log"
+)
+
+// StaleSilenceStore persists when active silences last matched an alert and which stale silences were reported in a YAML file.
+// Silences are thus considered stale across restarts of the stargate.
+type StaleSilenceStore struct {
+	logger   log.Logger
+	filePath string
+
+	mtx      sync.RWMutex
+	silences staleSilences
+}
+
+type staleSilences struct {
+	// TrackedSince is the time silences are tracked since, which is the first start of the stargate.
+	TrackedSince time.Time `yaml:"tracked_since"`
+	// LastMatchedAt maps the ID of an active silence to the last time it matched an alert.
+	LastMatchedAt map[string]time.Time `yaml:"last_matched_at"`
+	// Reported contains the IDs of the stale silences which were reported.
+	Reported map[string]bool `yaml:"reported"`
+}
+
+// NewStaleSilenceStore returns a new StaleSilenceStore and loads the tracked silences from the file.
+func NewStaleSilenceStore(filePath string, logger log.Logger) (*StaleSilenceStore, error) {
+	s := &StaleSilenceStore{
+		logger:   log.NewLoggerWith(logger, "component", "StaleSilenceStore"),
+		filePath: filePath,
+	}
+
+	exists, err := loadYAMLFile(filePath, "stale silences", &s.silences)
+	if err != nil {
+		return nil, err
+	}
+	if s.silences.TrackedSince.IsZero() {
+		s.silences.TrackedSince = time.Now().UTC()
+	}
+	if s.silences.LastMatchedAt == nil {
+		s.silences.LastMatchedAt = make(map[string]time.Time)
+	}
+	if s.silences.Reported == nil {
+		s.silences.Reported = make(map[string]bool)
+	}
+	if !exists {
+		s.logger.LogInfo("stale silences file does not exist yet", "file", filePath)
+		return s, nil
+	}
+	s.logger.LogInfo("loaded stale silences", "file", filePath, "silences", len(s.silences.LastMatchedAt))
+	return s, nil
+}
+
+// TrackedSince returns the time silences are tracked since.
+func (s *StaleSilenceStore) TrackedSince() time.Time {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.silences.TrackedSince
+}
+
+// LastMatchedAt returns the last time the silence matched an alert if it is tracked.
+func (s *StaleSilenceStore) LastMatchedAt(silenceID string) (time.Time, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	matchedAt, ok := s.silences.LastMatchedAt[silenceID]
+	return matchedAt, ok
+}
+
+// IsReported checks whether the stale silence was reported.
+func (s *StaleSilenceStore) IsReported(silenceID string) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.silences.Reported[silenceID]
+}
+
+// Set replaces the tracked silences and persists them.
+func (s *StaleSilenceStore) Set(lastMatchedAt map[string]time.Time, reported map[string]bool) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.silences.LastMatchedAt = lastMatchedAt
+	s.silences.Reported = reported
+	return storeYAMLFile(s.filePath, "stale silences", s.silences)
+}
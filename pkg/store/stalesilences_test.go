@@ -0,0 +1,59 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/sapcc/stargate/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaleSilenceStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stargate")
+	require.NoError(t, err, "creating a temporary directory must not raise an error")
+	defer os.RemoveAll(dir)
+	filePath := path.Join(dir, "stale-silences.yaml")
+
+	matchedAt := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	staleSilences, err := NewStaleSilenceStore(filePath, log.NewLogger(false))
+	require.NoError(t, err, "a missing stale silences file must not raise an error")
+	trackedSince := staleSilences.TrackedSince()
+	_, ok := staleSilences.LastMatchedAt("silence1")
+	assert.False(t, ok, "an unknown silence should not be tracked")
+
+	err = staleSilences.Set(map[string]time.Time{"silence1": matchedAt, "silence2": matchedAt}, map[string]bool{"silence2": true})
+	require.NoError(t, err, "setting the silences must not raise an error")
+
+	staleSilences, err = NewStaleSilenceStore(filePath, log.NewLogger(false))
+	require.NoError(t, err, "loading the stale silences must not raise an error")
+	assert.True(t, trackedSince.Equal(staleSilences.TrackedSince()), "the start of the tracking should be persisted")
+	lastMatchedAt, ok := staleSilences.LastMatchedAt("silence1")
+	assert.True(t, ok, "the silence should be tracked")
+	assert.True(t, matchedAt.Equal(lastMatchedAt), "the last match should be persisted")
+	assert.False(t, staleSilences.IsReported("silence1"), "the silence should not be reported")
+	assert.True(t, staleSilences.IsReported("silence2"), "the report should be persisted")
+}
@@ -133,6 +133,19 @@ func (a *AlertStore) Set(extendedAlert *client.ExtendedAlert) error {
 // AcknowledgeAndSetMultiple acknowledges and adds multiple alerts to the AlertStore.
 // If alert already present in AlertStore, additional acknowledgers will be appended.
 func (a *AlertStore) AcknowledgeAndSetMultiple(extendedAlertList []*client.ExtendedAlert, acknowledgedBy string) error {
+	return a.acknowledgeAndSetMultiple(extendedAlertList, func(al *client.ExtendedAlert) *client.ExtendedAlert {
+		return alert_util.AcknowledgeAlert(al, acknowledgedBy)
+	})
+}
+
+// AcknowledgeAndSetMultipleByUser acknowledges and adds multiple alerts to the AlertStore on behalf of the Slack user.
+func (a *AlertStore) AcknowledgeAndSetMultipleByUser(extendedAlertList []*client.ExtendedAlert, userName, userID string) error {
+	return a.acknowledgeAndSetMultiple(extendedAlertList, func(al *client.ExtendedAlert) *client.ExtendedAlert {
+		return alert_util.AcknowledgeAlertByUser(al, userName, userID)
+	})
+}
+
+func (a *AlertStore) acknowledgeAndSetMultiple(extendedAlertList []*client.ExtendedAlert, acknowledge func(al *client.ExtendedAlert) *client.ExtendedAlert) error {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
@@ -145,11 +158,11 @@ func (a *AlertStore) AcknowledgeAndSetMultiple(extendedAlertList []*client.Exten
 
 		foundAlert, ok := a.s[fp]
 		if ok {
-			a.s[fp] = alert_util.AcknowledgeAlert(foundAlert, acknowledgedBy)
+			a.s[fp] = acknowledge(foundAlert)
 			a.logger.LogDebug("adding alert to store", "fingerprint", fp.String())
 			continue
 		}
-		a.s[fp] = alert_util.AcknowledgeAlert(al, acknowledgedBy)
+		a.s[fp] = acknowledge(al)
 	}
 	return nil
 }
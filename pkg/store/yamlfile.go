@@ -0,0 +1,59 @@
+/*******************************************************************************
+*
+* Copyright 2019 SAP SE
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You should have received a copy of the License along with this
+* program. If not, you may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*
+*******************************************************************************/
+
+package store
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// loadYAMLFile parses the file into v. Returns false if the file does not exist yet.
+func loadYAMLFile(filePath, kind string, v interface{}) (bool, error) {
+	fileBytes, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read %s file: %s", kind, err.Error())
+	}
+
+	if err := yaml.Unmarshal(fileBytes, v); err != nil {
+		return false, fmt.Errorf("parse %s file: %s", kind, err.Error())
+	}
+	return true, nil
+}
+
+// storeYAMLFile writes v to a temporary file, which is then renamed.
+func storeYAMLFile(filePath, kind string, v interface{}) error {
+	fileBytes, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %s", kind, err.Error())
+	}
+
+	tmpFilename := fmt.Sprintf("%s.%x", filePath, uint64(rand.Int63()))
+	if err := ioutil.WriteFile(tmpFilename, fileBytes, 0644); err != nil {
+		return fmt.Errorf("write %s file: %s", kind, err.Error())
+	}
+	return os.Rename(tmpFilename, filePath)
+}
@@ -17,11 +17,20 @@
 *
 *******************************************************************************/
 
-package pagerduty
+package util
 
-import "github.com/prometheus/common/model"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
 
-// Pagerduty ...
-type Pagerduty interface {
-	AcknowledgeIncident(alert *model.Alert, userEmail string) error
+// NewCorrelationID returns a random ID used to correlate a message shown to a user with the log.
+func NewCorrelationID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
 }